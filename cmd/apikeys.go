@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"gopkg.in/yaml.v3"
+)
+
+type APIKeysCmdOptions struct {
+	Name     string
+	Scanners []string
+}
+
+func init() {
+	opts := &APIKeysCmdOptions{}
+	cmd := NewAPIKeysCmd(opts)
+	rootCmd.AddCommand(cmd)
+}
+
+func NewAPIKeysCmd(opts *APIKeysCmdOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apikeys",
+		Short: "Manage API keys of the REST API",
+	}
+
+	generateCmd := &cobra.Command{
+		Use:     "generate",
+		Example: "phoneinfoga apikeys generate --name soar --scanner local --scanner numverify",
+		Short:   "Generate an API key, along with its entry for the configuration or keys file",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			key, err := auth.Generate()
+			if err != nil {
+				exitWithError(err)
+			}
+			printAPIKeyEntry(key, opts)
+		},
+	}
+	generateCmd.Flags().StringVar(&opts.Name, "name", "default", "Name of the API key")
+	generateCmd.Flags().StringArrayVar(&opts.Scanners, "scanner", []string{}, "Scanner the key is allowed to use, accepts the same rules as --only (default all)")
+
+	hashCmd := &cobra.Command{
+		Use:     "hash <key>",
+		Example: "phoneinfoga apikeys hash pik_0123456789abcdef --name soar",
+		Short:   "Print the entry of an existing API key for the configuration or keys file",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			printAPIKeyEntry(args[0], opts)
+		},
+	}
+	hashCmd.Flags().StringVar(&opts.Name, "name", "default", "Name of the API key")
+	hashCmd.Flags().StringArrayVar(&opts.Scanners, "scanner", []string{}, "Scanner the key is allowed to use, accepts the same rules as --only (default all)")
+
+	cmd.AddCommand(generateCmd, hashCmd)
+	return cmd
+}
+
+// printAPIKeyEntry prints the key, which isn't stored anywhere, then its hashed entry
+func printAPIKeyEntry(key string, opts *APIKeysCmdOptions) {
+	k := auth.Key{Name: opts.Name, Hash: auth.Hash(key), Scanners: opts.Scanners}
+	if _, err := auth.NewKeyring(k); err != nil {
+		exitWithError(err)
+	}
+
+	data, err := yaml.Marshal([]auth.Key{k})
+	if err != nil {
+		exitWithError(err)
+	}
+
+	fmt.Printf("API key: %s\n\n", key)
+	fmt.Println("# Add this entry to auth.keys in the configuration file, or to keys in the API keys file:")
+	fmt.Print(string(data))
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+type ConfigCmdOptions struct {
+	EnvFiles []string
+}
+
+func init() {
+	opts := &ConfigCmdOptions{}
+	cmd := NewConfigCmd(opts)
+	rootCmd.AddCommand(cmd)
+}
+
+func NewConfigCmd(opts *ConfigCmdOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+	}
+
+	showCmd := &cobra.Command{
+		Use:     "show",
+		Example: "phoneinfoga config show --config ./phoneinfoga.yaml",
+		Short:   "Print the effective configuration, with secrets masked",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := loadEnv(cmd, opts.EnvFiles); err != nil {
+				exitWithError(err)
+			}
+
+			data, err := cfg.Masked().YAML()
+			if err != nil {
+				exitWithError(err)
+			}
+			if cfg.Path() != "" {
+				fmt.Printf("# Loaded from %s\n", cfg.Path())
+			}
+			fmt.Print(string(data))
+		},
+	}
+	showCmd.Flags().StringSliceVar(&opts.EnvFiles, "env-file", []string{}, "Env files to parse environment variables from (looks for .env by default)")
+
+	cmd.AddCommand(showCmd)
+	return cmd
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/output"
+	"io"
+	"os"
+)
+
+type DiffCmdOptions struct {
+	OutputFormat string
+	OutputFile   string
+}
+
+func init() {
+	// Register command
+	opts := &DiffCmdOptions{}
+	cmd := NewDiffCmd(opts)
+	rootCmd.AddCommand(cmd)
+
+	// Register flags
+	cmd.Flags().StringVar(&opts.OutputFormat, "format", "console", "Output format of the diff (console, json)")
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "", "Output file to write the diff to")
+}
+
+func NewDiffCmd(opts *DiffCmdOptions) *cobra.Command {
+	return &cobra.Command{
+		Use: "diff <from> [to]",
+		Example: `phoneinfoga diff <id> <id>
+phoneinfoga diff old.json new.json
+phoneinfoga diff +33679368229 # two latest scans of this number`,
+		Short: "Compare results of two scans",
+		Long:  "Compare results of two scans, given as scan IDs from history, numbers (their latest scan), or JSON files written by the scan command.",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			from, to, err := diffInputs(args)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			d, err := diff.Compare(from, to)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			if err := writeDiff(opts, d); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+}
+
+func diffInputs(args []string) (diff.Result, diff.Result, error) {
+	if len(args) == 1 {
+		num, err := number.NewNumber(args[0])
+		if err != nil {
+			return diff.Result{}, diff.Result{}, fmt.Errorf("give two scans to compare, or a number: %v", err)
+		}
+		scans, err := browseHistory().List(history.Query{Number: num.E164, Limit: 2})
+		if err != nil {
+			return diff.Result{}, diff.Result{}, err
+		}
+		if len(scans) < 2 {
+			return diff.Result{}, diff.Result{}, fmt.Errorf("at least two scans of number %s are needed", num.E164)
+		}
+		return scanResult(scans[1]), scanResult(scans[0]), nil
+	}
+
+	from, err := diffInput(args[0])
+	if err != nil {
+		return diff.Result{}, diff.Result{}, err
+	}
+	to, err := diffInput(args[1])
+	return from, to, err
+}
+
+// diffInput reads results from a JSON file, or from history if no such file exists
+func diffInput(arg string) (diff.Result, error) {
+	file, err := os.Open(arg)
+	if err == nil {
+		defer file.Close()
+		res, err := diff.ReadResult(file)
+		if err != nil {
+			return res, fmt.Errorf("%s: %v", arg, err)
+		}
+		return res, nil
+	}
+	if !os.IsNotExist(err) {
+		return diff.Result{}, err
+	}
+
+	scan, err := findScan(arg)
+	if err != nil {
+		return diff.Result{}, fmt.Errorf("%s: %v", arg, err)
+	}
+	return scanResult(scan), nil
+}
+
+func scanResult(s *history.Scan) diff.Result {
+	return diff.Result{Number: &s.Number, Results: s.Results, Errors: s.Errors}
+}
+
+func writeDiff(opts *DiffCmdOptions, d *diff.Diff) error {
+	outputKey, err := output.ParseOutputKey(opts.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = color.Output
+	if opts.OutputFile != "" {
+		file, err := os.Create(opts.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if outputKey == output.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+
+	d.WriteConsole(w)
+	return nil
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/lib/config"
+	"github.com/sundowndev/phoneinfoga/v2/lib/doctor"
+	"github.com/sundowndev/phoneinfoga/v2/lib/dorks"
+	"github.com/sundowndev/phoneinfoga/v2/lib/plugins"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+type DoctorCmdOptions struct {
+	EnvFiles    []string
+	PluginPaths []string
+	Timeout     time.Duration
+	Offline     bool
+}
+
+func init() {
+	opts := &DoctorCmdOptions{}
+	cmd := NewDoctorCmd(opts)
+	rootCmd.AddCommand(cmd)
+
+	cmd.Flags().StringSliceVar(&opts.EnvFiles, "env-file", []string{}, "Env files to parse environment variables from (looks for .env by default)")
+	cmd.Flags().StringArrayVar(&opts.PluginPaths, "plugin", []string{}, "Extra scanner plugin to check")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 5*time.Second, "Timeout of requests checking suppliers can be reached")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Do not check suppliers can be reached")
+}
+
+func NewDoctorCmd(opts *DoctorCmdOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "doctor",
+		Example: "phoneinfoga doctor --env-file .env.local",
+		Short:   "Diagnose the configuration, plugins, scanner options and access to suppliers",
+		Args:    cobra.NoArgs,
+		// The configuration is loaded by the command, so errors are reported along with other checks
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Run: func(cmd *cobra.Command, args []string) {
+			report := &doctor.Report{}
+
+			checkConfig(report, cmd, opts)
+			checkPlugins(report, opts)
+			lib := checkScanners(report)
+			if !opts.Offline {
+				checkSuppliers(report, lib, opts.Timeout)
+			}
+
+			report.Write(color.Output)
+			if n := report.Failures(); n > 0 {
+				fmt.Println()
+				exitWithError(fmt.Errorf("%d problem(s) found", n))
+			}
+		},
+	}
+}
+
+func checkConfig(report *doctor.Report, cmd *cobra.Command, opts *DoctorCmdOptions) {
+	report.Section("Configuration")
+
+	if err := loadConfig(); err != nil {
+		report.Fail("config file", err.Error(), "fix the file or the environment variable, or give another file with --config")
+	} else if cfg.Path() != "" {
+		report.OK("config file", cfg.Path())
+	} else {
+		report.OK("config file", fmt.Sprintf("none found in %s, using defaults", strings.Join(config.Locations(), ", ")))
+	}
+
+	envFiles := opts.EnvFiles
+	if !cmd.Flags().Changed("env-file") && len(cfg.EnvFiles) > 0 {
+		envFiles = cfg.EnvFiles
+	}
+	explicit := len(envFiles) > 0
+	if !explicit {
+		envFiles = []string{".env"}
+	}
+	for _, f := range envFiles {
+		name := "env file " + f
+		if _, err := os.Stat(f); os.IsNotExist(err) {
+			if explicit {
+				report.Fail(name, "not found", "create the file, or fix its path in --env-file or env_files of the config file")
+			} else {
+				report.OK(name, "not found, options are read from the environment only")
+			}
+			continue
+		}
+		vars, err := godotenv.Read(f)
+		if err != nil {
+			report.Fail(name, err.Error(), "write one NAME=value variable per line")
+			continue
+		}
+		report.OK(name, fmt.Sprintf("%d variable(s)", len(vars)))
+	}
+
+	if err := loadEnv(cmd, opts.EnvFiles); err != nil {
+		report.Fail("environment", err.Error(), "fix the environment variable, or unset it")
+	}
+
+	if set, err := dorks.Load(cfg.Dorks.Paths...); err != nil {
+		report.Fail("dork files", err.Error(), "fix the file, or its path in dorks.paths of the config file")
+	} else if len(cfg.Dorks.Paths) > 0 {
+		report.OK("dork files", fmt.Sprintf("%d dork(s) loaded", len(set.Dorks(""))))
+	}
+}
+
+func checkPlugins(report *doctor.Report, opts *DoctorCmdOptions) {
+	report.Section("Plugins")
+
+	registry := plugins.NewRegistry(cfg.Plugins.Dir)
+	manifests, err := registry.List()
+	if err != nil {
+		report.Fail("plugins directory "+registry.Dir(), err.Error(), "fix or remove the manifest files of the directory")
+	} else if len(manifests) == 0 {
+		report.OK("plugins directory "+registry.Dir(), "no plugin installed")
+	}
+
+	for _, m := range manifests {
+		fix := fmt.Sprintf("install the plugin again with phoneinfoga plugins install, or remove it with phoneinfoga plugins remove %s", m.Name)
+		if err := m.Load(); err != nil {
+			report.Fail(m.Name, err.Error(), fix)
+			continue
+		}
+		if missing := m.MissingOptions(remote.ScannerOptions{}); len(missing) > 0 {
+			report.Fail(m.Name, "missing required options "+strings.Join(missing, ", "), "define them in a .env file or in the environment, or remove the plugin")
+			continue
+		}
+		report.OK(m.Name, "loaded from "+m.FullPath())
+	}
+
+	for _, p := range append(cfg.Plugins.Paths, opts.PluginPaths...) {
+		if err := remote.OpenPlugin(p); err != nil {
+			report.Fail(p, err.Error(), "fix the path in --plugin or plugins.paths of the config file")
+			continue
+		}
+		report.OK(p, "loaded")
+	}
+}
+
+func checkScanners(report *doctor.Report) *remote.Library {
+	report.Section("Scanners")
+
+	f, err := newFilterEngine(nil, nil, nil)
+	if err != nil {
+		report.Fail("disabled scanners", err.Error(), "fix scanner names in the config file")
+	}
+	lib := remote.NewLibrary(f)
+	remote.InitScanners(lib)
+
+	for _, s := range lib.GetAllScanners() {
+		report.Add(doctor.CheckScanner(s, remote.ScannerOptions{}))
+	}
+	for _, name := range cfg.DisabledScanners() {
+		report.OK(name, "disabled in the config file")
+	}
+	return lib
+}
+
+func checkSuppliers(report *doctor.Report, lib *remote.Library, timeout time.Duration) {
+	report.Section("Suppliers")
+
+	checked := map[string]bool{}
+	for _, s := range lib.GetAllScanners() {
+		es, ok := s.(remote.EndpointsScanner)
+		if !ok {
+			continue
+		}
+		for _, url := range es.Endpoints() {
+			if checked[url] {
+				continue
+			}
+			checked[url] = true
+
+			d, err := doctor.CheckEndpoint(context.Background(), http.DefaultClient, url, timeout)
+			if err != nil {
+				report.Fail(url, err.Error(), fmt.Sprintf("check your network connection and proxy settings (HTTPS_PROXY), or disable the %s scanner", s.Name()))
+				continue
+			}
+			report.OK(url, fmt.Sprintf("reachable in %s (%s)", d.Round(time.Millisecond), s.Name()))
+		}
+	}
+	if len(checked) == 0 {
+		report.OK("suppliers", "no enabled scanner sends requests")
+	}
+}
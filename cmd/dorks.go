@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/lib/dorks"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+type DorksCmdOptions struct {
+	Number     string
+	Categories []string
+	Engines    string
+	Format     string
+	OutputFile string
+	// Options of the list command
+	Category string
+	Engine   string
+}
+
+func init() {
+	opts := &DorksCmdOptions{}
+	cmd := NewDorksCmd(opts)
+	rootCmd.AddCommand(cmd)
+
+	cmd.Flags().StringVarP(&opts.Number, "number", "n", "", "The phone number to generate dorks for (E164 or international format)")
+	cmd.Flags().StringSliceVar(&opts.Categories, "category", []string{}, fmt.Sprintf("Only generate dorks of the given categories (%s, %s)", strings.Join(dorks.Categories(), ", "), remote.GoogleCSE))
+	cmd.Flags().StringVar(&opts.Engines, "engine", "", fmt.Sprintf("Comma separated list of search engines to generate dorks for (default from GOOGLESEARCH_ENGINES, or %q)", remote.DefaultSearchEngines))
+	cmd.Flags().StringVar(&opts.Format, "format", string(dorks.FormatPlain), "Output format (plain, markdown, html)")
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "", "Output file to save dorks to")
+}
+
+func NewDorksCmd(opts *DorksCmdOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dorks",
+		Example: "phoneinfoga dorks -n +4176418xxxx --engine google,bing --format html -o dorks.html",
+		Short:   "Generate dorks of a phone number, or manage dorks generated by the googlesearch scanner",
+		Long:    fmt.Sprintf("Generate search links of the googlesearch and googlecse scanners for a phone number, to open them manually. No request is sent. Built-in dorks can be replaced or completed with YAML or JSON files, given in dorks.paths of the config file or $%s.", dorks.PathsEnv),
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.Number == "" {
+				_ = cmd.Help()
+				return
+			}
+			if err := loadEnv(cmd, nil); err != nil {
+				exitWithError(err)
+			}
+			if err := generateDorks(opts); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Example: "phoneinfoga dorks list --category reputation --engine bing",
+		Short:   "Validate dork files and list dorks by category",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listDorks(opts); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+	listCmd.Flags().StringVar(&opts.Category, "category", "", "Only list dorks of the given category")
+	listCmd.Flags().StringVar(&opts.Engine, "engine", dorks.Google.Name, "Search engine to write dorks for")
+
+	cmd.AddCommand(listCmd)
+	return cmd
+}
+
+func listDorks(opts *DorksCmdOptions) error {
+	engines, err := dorks.ParseEngines(opts.Engine)
+	if err != nil {
+		return err
+	}
+	if len(engines) > 1 {
+		return fmt.Errorf("only one search engine can be given")
+	}
+
+	set, err := dorks.Load(cfg.Dorks.Paths...)
+	if err != nil {
+		return err
+	}
+
+	categories := dorks.Categories()
+	if opts.Category != "" {
+		if !dorks.IsCategory(opts.Category) {
+			return fmt.Errorf("unknown category %q, categories are %s", opts.Category, strings.Join(dorks.Categories(), ", "))
+		}
+		categories = []string{opts.Category}
+	}
+
+	count := 0
+	for _, category := range categories {
+		list := set.Dorks(category)
+		if len(list) == 0 {
+			continue
+		}
+		if count > 0 {
+			fmt.Println()
+		}
+		_, _ = fmt.Fprintln(color.Output, color.WhiteString("%s (%d)", category, len(list)))
+		for _, d := range list {
+			_, _ = fmt.Fprintf(color.Output, "  %s %s\n", d.Template(engines[0]), color.HiBlackString(d.File))
+		}
+		count += len(list)
+	}
+	return nil
+}
+
+func generateDorks(opts *DorksCmdOptions) error {
+	format, err := dorks.ParseFormat(opts.Format)
+	if err != nil {
+		return err
+	}
+	for _, c := range opts.Categories {
+		if !dorks.IsCategory(c) && c != remote.GoogleCSE {
+			return fmt.Errorf("unknown category %q, categories are %s, %s", c, strings.Join(dorks.Categories(), ", "), remote.GoogleCSE)
+		}
+	}
+
+	if !number.IsValid(opts.Number) {
+		return errors.New("given phone number is not valid")
+	}
+	num, err := number.NewNumber(opts.Number)
+	if err != nil {
+		return err
+	}
+
+	if err := loadDorks(); err != nil {
+		return err
+	}
+	scannerOpts := remote.ScannerOptions{}
+	if opts.Engines != "" {
+		scannerOpts["GOOGLESEARCH_ENGINES"] = opts.Engines
+	}
+	res, err := remote.NewGoogleSearchScanner().Run(*num, scannerOpts)
+	if err != nil {
+		return err
+	}
+	r := res.(remote.GoogleSearchResponse)
+
+	all := []dorks.Group{
+		dorkGroup(dorks.CategorySocialMedia, dorks.CategoryTitle(dorks.CategorySocialMedia), r.SocialMedia),
+		dorkGroup(dorks.CategoryDisposableProviders, dorks.CategoryTitle(dorks.CategoryDisposableProviders), r.DisposableProviders),
+		dorkGroup(dorks.CategoryReputation, dorks.CategoryTitle(dorks.CategoryReputation), r.Reputation),
+		dorkGroup(dorks.CategoryIndividuals, dorks.CategoryTitle(dorks.CategoryIndividuals), r.Individuals),
+		dorkGroup(dorks.CategoryGeneral, dorks.CategoryTitle(dorks.CategoryGeneral), r.General),
+		dorkGroup(remote.GoogleCSE, "Google Custom Search", remote.GoogleCSEDorks(*num)),
+	}
+	only := map[string]bool{}
+	for _, c := range opts.Categories {
+		only[c] = true
+	}
+	var groups []dorks.Group
+	for _, g := range all {
+		if len(g.Links) > 0 && (len(only) == 0 || only[g.Category]) {
+			groups = append(groups, g)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if opts.OutputFile != "" {
+		f, err := os.Create(opts.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return dorks.Write(w, format, "Dorks for "+num.E164, groups)
+}
+
+func dorkGroup(category, title string, list []*remote.GoogleSearchDork) dorks.Group {
+	g := dorks.Group{Category: category, Title: title}
+	for _, d := range list {
+		g.Links = append(g.Links, dorks.Link{Engine: d.Engine, Dork: d.Dork, URL: d.URL})
+	}
+	return g
+}
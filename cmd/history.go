@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/output"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type HistoryCmdOptions struct {
+	Number       string
+	Country      string
+	Scanner      string
+	Since        string
+	Until        string
+	Limit        int
+	OutputFormat string
+	OutputFile   string
+}
+
+func init() {
+	opts := &HistoryCmdOptions{}
+	cmd := NewHistoryCmd(opts)
+	rootCmd.AddCommand(cmd)
+}
+
+func NewHistoryCmd(opts *HistoryCmdOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Browse scans saved in history",
+	}
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Example: "phoneinfoga history list --country FR --since 2023-01-01",
+		Short:   "List saved scans",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			scans := listScans(opts)
+			w := tabwriter.NewWriter(color.Output, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tDATE\tNUMBER\tCOUNTRY\tRESULTS\tERRORS")
+			for _, s := range scans {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", s.ID, s.Date.Local().Format(time.RFC3339), s.Number.E164, s.Number.Country, len(s.Results), len(s.Errors))
+			}
+			_ = w.Flush()
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:     "show <id|number>",
+		Example: "phoneinfoga history show +33679368229",
+		Short:   "Display a saved scan, or the latest scan of a number",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			scan, err := findScan(args[0])
+			if err != nil {
+				exitWithError(err)
+			}
+			if err := writeScans(opts, []*history.Scan{scan}); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:     "delete [id...]",
+		Example: "phoneinfoga history delete --until 2022-12-31",
+		Short:   "Delete saved scans, by ID or matching filters",
+		Run: func(cmd *cobra.Command, args []string) {
+			store := browseHistory()
+
+			ids := args
+			if len(ids) == 0 {
+				if opts.Number == "" && opts.Country == "" && opts.Scanner == "" && opts.Since == "" && opts.Until == "" {
+					exitWithError(errors.New("give scan IDs or at least one filter to delete scans"))
+				}
+				for _, s := range listScans(opts) {
+					ids = append(ids, s.ID)
+				}
+			}
+
+			for _, id := range ids {
+				if err := store.Delete(id); err != nil {
+					exitWithError(fmt.Errorf("%s: %v", id, err))
+				}
+			}
+			fmt.Printf("%d scan(s) deleted\n", len(ids))
+		},
+	}
+
+	exportCmd := &cobra.Command{
+		Use:     "export",
+		Example: "phoneinfoga history export --number +33679368229 --format json -o scans.json",
+		Short:   "Export saved scans matching filters",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := writeScans(opts, listScans(opts)); err != nil {
+				exitWithError(err)
+			}
+		},
+	}
+
+	for _, c := range []*cobra.Command{listCmd, deleteCmd, exportCmd} {
+		c.Flags().StringVarP(&opts.Number, "number", "n", "", "Filter scans by phone number")
+		c.Flags().StringVar(&opts.Country, "country", "", "Filter scans by country code (e.g. US, FR)")
+		c.Flags().StringVar(&opts.Scanner, "scanner", "", "Filter scans that ran the given scanner")
+		c.Flags().StringVar(&opts.Since, "since", "", "Filter scans made on or after this date (YYYY-MM-DD or RFC 3339)")
+		c.Flags().StringVar(&opts.Until, "until", "", "Filter scans made on or before this date (YYYY-MM-DD or RFC 3339)")
+	}
+	listCmd.Flags().IntVar(&opts.Limit, "limit", 0, "Maximum number of scans to list")
+	exportCmd.Flags().IntVar(&opts.Limit, "limit", 0, "Maximum number of scans to export")
+	for _, c := range []*cobra.Command{showCmd, exportCmd} {
+		c.Flags().StringVar(&opts.OutputFormat, "format", "console", "Output format of scans (console, json)")
+		c.Flags().StringVarP(&opts.OutputFile, "output", "o", "", "Output file to write scans to")
+	}
+
+	cmd.AddCommand(listCmd, showCmd, deleteCmd, exportCmd)
+	return cmd
+}
+
+func historyQuery(opts *HistoryCmdOptions) (history.Query, error) {
+	q := history.Query{
+		Country: strings.ToUpper(opts.Country),
+		Scanner: opts.Scanner,
+		Limit:   opts.Limit,
+	}
+	if opts.Number != "" {
+		num, err := number.NewNumber(opts.Number)
+		if err != nil {
+			return q, err
+		}
+		q.Number = num.E164
+	}
+	if opts.Since != "" {
+		since, err := history.ParseDate(opts.Since)
+		if err != nil {
+			return q, err
+		}
+		q.Since = since
+	}
+	if opts.Until != "" {
+		until, err := history.ParseDate(opts.Until)
+		if err != nil {
+			return q, err
+		}
+		// Dates without time include the whole day
+		if len(opts.Until) == len("2006-01-02") {
+			until = until.Add(24*time.Hour - time.Nanosecond)
+		}
+		q.Until = until
+	}
+	return q, nil
+}
+
+func listScans(opts *HistoryCmdOptions) []*history.Scan {
+	q, err := historyQuery(opts)
+	if err != nil {
+		exitWithError(err)
+	}
+	scans, err := browseHistory().List(q)
+	if err != nil {
+		exitWithError(err)
+	}
+	return scans
+}
+
+// findScan returns the scan with the given ID, or the latest scan of the given number
+func findScan(idOrNumber string) (*history.Scan, error) {
+	store := browseHistory()
+
+	scan, err := store.Get(idOrNumber)
+	if err == nil || !errors.Is(err, history.ErrNotFound) {
+		return scan, err
+	}
+
+	num, err := number.NewNumber(idOrNumber)
+	if err != nil {
+		return nil, history.ErrNotFound
+	}
+	scans, err := store.List(history.Query{Number: num.E164, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(scans) == 0 {
+		return nil, fmt.Errorf("no scan found for number %s", num.E164)
+	}
+	return scans[0], nil
+}
+
+// exportedScan is a scan written by the JSON output, its
+// results are written like the ones of scan --format json
+type exportedScan struct {
+	ID     string        `json:"id"`
+	Date   time.Time     `json:"date"`
+	Number number.Number `json:"number"`
+	output.JSONResult
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// writeScans renders scans through output writers. JSON output
+// is an array of scans, including their ID, date and metadata.
+func writeScans(opts *HistoryCmdOptions, scans []*history.Scan) error {
+	outputKey, err := output.ParseOutputKey(opts.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = color.Output
+	if opts.OutputFile != "" {
+		file, err := os.Create(opts.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if outputKey == output.JSON {
+		docs := make([]exportedScan, 0, len(scans))
+		for _, s := range scans {
+			docs = append(docs, exportedScan{
+				ID:         s.ID,
+				Date:       s.Date,
+				Number:     s.Number,
+				JSONResult: output.NewJSONResult(s.Results, s.ErrorMap()),
+				Metadata:   s.Metadata,
+			})
+		}
+		return output.WriteJSON(w, docs)
+	}
+
+	for i, s := range scans {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintf(w, color.WhiteString("Scan %s of %s on %s\n\n"), s.ID, s.Number.E164, s.Date.Local().Format(time.RFC1123))
+		if err := output.GetOutput(outputKey, w).Write(s.Results, s.ErrorMap()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// browseHistory returns the history store, even when saving scans is disabled
+func browseHistory() history.Store {
+	return history.NewBoltStore(cfg.History.Path)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/lib/plugins"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"strings"
+)
+
+type PluginsCmdOptions struct {
+	Dir             string
+	Name            string
+	Version         string
+	RequiredOptions []string
+}
+
+func init() {
+	opts := &PluginsCmdOptions{}
+	cmd := NewPluginsCmd(opts)
+	rootCmd.AddCommand(cmd)
+
+	cmd.PersistentFlags().StringVar(&opts.Dir, "dir", "", fmt.Sprintf("Plugins directory (default %q, or $%s)", plugins.DefaultDir(), plugins.DirEnv))
+}
+
+func NewPluginsCmd(opts *PluginsCmdOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "Manage installed scanner plugins",
+		Long:  "Manage scanner plugins installed in the plugins directory. Installed plugins are loaded automatically by scan, serve and scanners commands.",
+	}
+
+	installCmd := &cobra.Command{
+		Use:     "install <path>",
+		Example: "phoneinfoga plugins install ./customscanner.so --version v1.0.0 --require CUSTOM_API_KEY",
+		Short:   "Install a plugin and write its manifest",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			m, err := opts.registry().Install(args[0], plugins.Manifest{
+				Name:            opts.Name,
+				Version:         opts.Version,
+				RequiredOptions: opts.RequiredOptions,
+			})
+			if err != nil {
+				exitWithError(err)
+			}
+			fmt.Printf("Plugin %s installed in %s (%s)\n", m.Name, m.FullPath(), m.Checksum)
+		},
+	}
+	installCmd.Flags().StringVar(&opts.Name, "name", "", "Plugin name (defaults to the file name)")
+	installCmd.Flags().StringVar(&opts.Version, "version", "", "Plugin version")
+	installCmd.Flags().StringArrayVar(&opts.RequiredOptions, "require", []string{}, "Option the plugin requires to run")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List installed plugins",
+			Args:  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				manifests, err := opts.registry().List()
+				if err != nil {
+					exitWithError(err)
+				}
+				for i, m := range manifests {
+					fmt.Printf("%s %s\n", m.Name, m.Version)
+					fmt.Printf("Path: %s\n", m.FullPath())
+					fmt.Printf("Checksum: %s\n", m.Checksum)
+					if len(m.RequiredOptions) > 0 {
+						fmt.Printf("Required options: %s\n", strings.Join(m.RequiredOptions, ", "))
+					}
+					if i < len(manifests)-1 {
+						fmt.Printf("\n")
+					}
+				}
+			},
+		},
+		installCmd,
+		&cobra.Command{
+			Use:   "remove <name>",
+			Short: "Remove an installed plugin",
+			Args:  cobra.ExactArgs(1),
+			Run: func(cmd *cobra.Command, args []string) {
+				if err := opts.registry().Remove(args[0]); err != nil {
+					exitWithError(err)
+				}
+				fmt.Printf("Plugin %s removed\n", args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "verify [names...]",
+			Short: "Verify checksums and required options of installed plugins",
+			Run: func(cmd *cobra.Command, args []string) {
+				if err := verifyPlugins(opts.registry(), args); err != nil {
+					exitWithError(err)
+				}
+			},
+		},
+	)
+
+	return cmd
+}
+
+func verifyPlugins(r *plugins.Registry, names []string) error {
+	var manifests []*plugins.Manifest
+	if len(names) == 0 {
+		all, err := r.List()
+		if err != nil {
+			return err
+		}
+		manifests = all
+	}
+	for _, name := range names {
+		m, err := r.Get(name)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, m)
+	}
+
+	failed := 0
+	for _, m := range manifests {
+		if err := m.Verify(); err != nil {
+			failed++
+			fmt.Fprintf(color.Output, "%s %s\n", color.RedString("✗"), err)
+			continue
+		}
+		if missing := m.MissingOptions(remote.ScannerOptions{}); len(missing) > 0 {
+			fmt.Fprintf(color.Output, "%s %s: missing required options %s\n", color.YellowString("!"), m.Name, strings.Join(missing, ", "))
+			continue
+		}
+		fmt.Fprintf(color.Output, "%s %s\n", color.GreenString("✓"), m.Name)
+	}
+
+	if failed > 0 {
+		return errors.New("some plugins could not be verified")
+	}
+	return nil
+}
+
+// registry returns the registry of the plugins directory given
+// on the command line, defaulting to the configured one
+func (opts *PluginsCmdOptions) registry() *plugins.Registry {
+	if opts.Dir != "" {
+		return plugins.NewRegistry(opts.Dir)
+	}
+	return plugins.NewRegistry(cfg.Plugins.Dir)
+}
@@ -1,28 +1,224 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/fatih/color"
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/lib/config"
+	"github.com/sundowndev/phoneinfoga/v2/lib/dorks"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/fixtures"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/plugins"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tracing"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	configPath string
+	cfg        = &config.Config{}
+)
+
 var rootCmd = &cobra.Command{
 	Use:     "phoneinfoga [COMMANDS] [OPTIONS]",
 	Short:   "Advanced information gathering & OSINT tool for phone numbers",
 	Long:    "PhoneInfoga is one of the most advanced tools to scan phone numbers using only free resources.",
 	Example: "phoneinfoga scan -n <number>",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := loadConfig(); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", fmt.Sprintf("Config file (default %q, or $%s)", config.Locations(), config.FileEnv))
 }
 
 // Execute is a function that executes the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	remote.RemovePluginCopies()
+	if err != nil {
 		exitWithError(err)
 	}
 }
 
 func exitWithError(err error) {
+	remote.RemovePluginCopies()
 	fmt.Fprintf(color.Error, "%s\n", color.RedString(err.Error()))
 	os.Exit(1)
 }
+
+func loadConfig() error {
+	c, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	if err := c.ApplyEnv(); err != nil {
+		return err
+	}
+	cfg = c
+
+	logConfig := logs.Config{
+		Level:         logrus.GetLevel(),
+		Format:        cfg.LogFormat,
+		File:          cfg.LogFile,
+		MaxSize:       cfg.LogMaxSize,
+		MaxBackups:    cfg.LogMaxBackups,
+		MaxAge:        cfg.LogMaxAge,
+		RedactNumbers: cfg.LogRedactNumbers,
+	}
+	if cfg.LogLevel != "" {
+		lvl, err := logrus.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			return err
+		}
+		logConfig.Level = lvl
+	}
+	if err := logs.Setup(logConfig); err != nil {
+		return err
+	}
+	if cfg.Path() != "" {
+		logrus.WithField("path", cfg.Path()).Debug("Config file loaded")
+	}
+	return nil
+}
+
+// loadEnv loads env files, then applies environment variables they define
+// to the configuration. Scanner options from the configuration are exported
+// as environment variables, unless they're already defined.
+func loadEnv(cmd *cobra.Command, envFiles []string) error {
+	if !cmd.Flags().Changed("env-file") && len(cfg.EnvFiles) > 0 {
+		envFiles = cfg.EnvFiles
+	}
+	err := godotenv.Load(envFiles...)
+	if err != nil {
+		logrus.WithField("error", err).Debug("Error loading .env file")
+	}
+
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+
+	wasmOptions := map[string]map[string]string{}
+	for name, s := range cfg.Scanners {
+		if s != nil {
+			wasmOptions[name] = s.Options
+		}
+	}
+	remote.SetWasmOptions(wasmOptions)
+
+	return cfg.ExportScannerOptions()
+}
+
+// setFlagDefaults gives flags that were not set on the command line
+// their value from the configuration. Empty values are ignored.
+func setFlagDefaults(cmd *cobra.Command, values map[string]string) error {
+	for name, v := range values {
+		if v == "" || cmd.Flags().Changed(name) {
+			continue
+		}
+		if err := cmd.Flags().Set(name, v); err != nil {
+			return fmt.Errorf("invalid value for %s in config: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// newFilterEngine builds the scanner filter shared by scan, serve and scanners
+// commands. Rules from flags come last, so they take precedence over the
+// configuration and the profile. Scanners given with --only replace the
+// ones of the profile.
+func newFilterEngine(profile *config.Profile, disabled, only []string) (*filter.Engine, error) {
+	f := filter.NewEngine()
+	if profile != nil {
+		profile.WithScanners(only).AddRules(f)
+	}
+	f.AddRule(cfg.DisabledScanners()...)
+	f.AddRule(disabled...)
+	f.AddAllowRule(only...)
+	return f, f.Validate()
+}
+
+// historyStore returns the scan history store, or nil when history is disabled
+func historyStore(disabled bool) history.Store {
+	if disabled || cfg.History.Disabled {
+		return nil
+	}
+	return history.NewBoltStore(cfg.History.Path)
+}
+
+// transports are layers of the chain supplier requests go through,
+// commands set them then build the chain once with remote.SetTransport
+var transports remote.TransportChain
+
+func installFixtures(mode, dir string) error {
+	m, err := fixtures.ParseMode(mode)
+	if err != nil {
+		return err
+	}
+	layer, err := fixtures.Layer(m, dir)
+	if err != nil {
+		return err
+	}
+	transports.Fixtures = layer
+	return nil
+}
+
+// setupTracing exports traces of scans and supplier requests when an
+// exporter is given. The returned function flushes spans left.
+func setupTracing(exporter, file string) (func(), error) {
+	if exporter == "" {
+		return func() {}, nil
+	}
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{Exporter: exporter, File: file})
+	if err != nil {
+		return nil, err
+	}
+	transports.Tracing = tracing.Transport
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logrus.WithField("error", err).Warn("Unable to export traces")
+		}
+	}, nil
+}
+
+// loadPlugins opens plugins installed in the plugins directory,
+// then the ones given in the configuration and on the command line.
+// Plugins failing to load are logged and returned, other plugins
+// are still loaded.
+func loadPlugins(paths []string) []error {
+	errs := plugins.NewRegistry(cfg.Plugins.Dir).Load()
+	for _, p := range append(cfg.Plugins.Paths, paths...) {
+		if err := remote.OpenPlugin(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, err := range errs {
+		logrus.WithField("error", err).Error("Unable to load plugin")
+	}
+	return errs
+}
+
+// loadDorks loads dork files given in the configuration,
+// they're generated by the googlesearch scanner
+func loadDorks() error {
+	set, err := dorks.Load(cfg.Dorks.Paths...)
+	if err != nil {
+		return err
+	}
+	remote.GoogleSearchDorks = set
+	return nil
+}
@@ -1,23 +1,38 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/fatih/color"
-	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/build"
+	"github.com/sundowndev/phoneinfoga/v2/lib/config"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/output"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tracing"
+	"os"
 )
 
 type ScanCmdOptions struct {
 	Number           string
 	DisabledScanners []string
+	OnlyScanners     []string
 	PluginPaths      []string
 	EnvFiles         []string
+	FixturesMode     string
+	FixturesDir      string
+	OutputFormat     string
+	OutputFile       string
+	Profile          string
+	NoHistory        bool
+	MetricsFile      string
+	Tracing          string
+	TracingFile      string
 }
 
 func init() {
@@ -28,11 +43,20 @@ func init() {
 
 	// Register flags
 	cmd.PersistentFlags().StringVarP(&opts.Number, "number", "n", "", "The phone number to scan (E164 or international format)")
-	cmd.PersistentFlags().StringArrayVarP(&opts.DisabledScanners, "disable", "D", []string{}, "Scanner to skip for this scan")
+	cmd.PersistentFlags().StringArrayVarP(&opts.DisabledScanners, "disable", "D", []string{}, "Scanner to skip for this scan (name, glob such as \"google*\", or \"tag:<tag>\", prefix with ! to negate)")
+	cmd.PersistentFlags().StringArrayVar(&opts.OnlyScanners, "only", []string{}, "Scanner to run exclusively for this scan, accepts the same rules as --disable")
 	cmd.PersistentFlags().StringArrayVar(&opts.PluginPaths, "plugin", []string{}, "Extra scanner plugin to use for the scan")
 	cmd.PersistentFlags().StringSliceVar(&opts.EnvFiles, "env-file", []string{}, "Env files to parse environment variables from (looks for .env by default)")
+	cmd.PersistentFlags().StringVar(&opts.FixturesMode, "fixtures-mode", "", "Record or replay HTTP responses of suppliers (record, replay)")
+	cmd.PersistentFlags().StringVar(&opts.FixturesDir, "fixtures-dir", "", "Directory to store HTTP fixtures in (default \"fixtures\")")
+	cmd.PersistentFlags().StringVar(&opts.OutputFormat, "format", "console", "Output format of scan results (console, json)")
+	cmd.PersistentFlags().StringVarP(&opts.OutputFile, "output", "o", "", "Output file to save scan results to")
+	cmd.PersistentFlags().StringVar(&opts.Profile, "profile", "", "Scan profile to use (quick, full, passive, or a profile from the config file)")
+	cmd.PersistentFlags().BoolVar(&opts.NoHistory, "no-history", false, "Do not save the scan in history")
+	cmd.PersistentFlags().StringVar(&opts.Tracing, "tracing", "", "Export OpenTelemetry traces of the scan (otlp, stdout, file)")
+	cmd.PersistentFlags().StringVar(&opts.TracingFile, "tracing-file", "", "File to append traces to with the file exporter")
+	cmd.PersistentFlags().StringVar(&opts.MetricsFile, "metrics-file", "", "File to write Prometheus metrics of the scan to, e.g. for the node exporter textfile collector")
 	// scanCmd.PersistentFlags().StringVarP(&input, "input", "i", "", "Text file containing a list of phone numbers to scan (one per line)")
-	// scanCmd.PersistentFlags().StringVarP(&output, "output", "o", "", "Output to save scan results")
 }
 
 func NewScanCmd(opts *ScanCmdOptions) *cobra.Command {
@@ -41,50 +65,141 @@ func NewScanCmd(opts *ScanCmdOptions) *cobra.Command {
 		Short: "Scan a phone number",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			err := godotenv.Load(opts.EnvFiles...)
+			if err := loadEnv(cmd, opts.EnvFiles); err != nil {
+				exitWithError(err)
+			}
+
+			profile := &config.Profile{}
+			if opts.Profile != "" {
+				p, err := cfg.Profile(opts.Profile)
+				if err != nil {
+					exitWithError(err)
+				}
+				profile = p
+			}
+
+			format := cfg.Output.Format
+			if profile.Output != "" {
+				format = profile.Output
+			}
+
+			err := setFlagDefaults(cmd, map[string]string{
+				"fixtures-mode": cfg.Fixtures.Mode,
+				"fixtures-dir":  cfg.Fixtures.Dir,
+				"format":        format,
+				"output":        cfg.Output.File,
+				"tracing":       cfg.Tracing.Exporter,
+				"tracing-file":  cfg.Tracing.File,
+			})
 			if err != nil {
-				logrus.WithField("error", err).Debug("Error loading .env file")
+				exitWithError(err)
+			}
+
+			if err := installFixtures(opts.FixturesMode, opts.FixturesDir); err != nil {
+				exitWithError(err)
 			}
 
-			runScan(opts)
+			if err := runScan(opts, profile); err != nil {
+				exitWithError(err)
+			}
 		},
 	}
 }
 
-func runScan(opts *ScanCmdOptions) {
-	fmt.Fprintf(color.Output, color.WhiteString("Running scan for phone number %s...\n\n"), opts.Number)
+// runScan scans the number and writes results. Errors are returned rather
+// than exiting, so traces and metrics are flushed whatever the outcome.
+func runScan(opts *ScanCmdOptions, profile *config.Profile) error {
+	outputKey, err := output.ParseOutputKey(opts.OutputFormat)
+	if err != nil {
+		return err
+	}
+
+	if outputKey == output.Console {
+		fmt.Fprintf(color.Output, color.WhiteString("Running scan for phone number %s...\n\n"), opts.Number)
+	}
 
 	if valid := number.IsValid(opts.Number); !valid {
 		logrus.WithFields(map[string]interface{}{
 			"input": opts.Number,
 			"valid": valid,
 		}).Debug("Input phone number is invalid")
-		exitWithError(errors.New("given phone number is not valid"))
+		return errors.New("given phone number is not valid")
 	}
 
 	num, err := number.NewNumber(opts.Number)
 	if err != nil {
-		exitWithError(err)
+		return err
 	}
 
-	for _, p := range opts.PluginPaths {
-		err := remote.OpenPlugin(p)
-		if err != nil {
-			exitWithError(err)
-		}
+	loadPlugins(opts.PluginPaths)
+
+	if err := loadDorks(); err != nil {
+		return err
 	}
 
-	f := filter.NewEngine()
-	f.AddRule(opts.DisabledScanners...)
+	f, err := newFilterEngine(profile, opts.DisabledScanners, opts.OnlyScanners)
+	if err != nil {
+		return err
+	}
 
 	remoteLibrary := remote.NewLibrary(f)
 	remote.InitScanners(remoteLibrary)
 
-	// Scanner options are currently not used in CLI
-	result, errs := remoteLibrary.Scan(num, remote.ScannerOptions{})
-
-	err = output.GetOutput(output.Console, color.Output).Write(result, errs)
+	shutdownTracing, err := setupTracing(opts.Tracing, opts.TracingFile)
 	if err != nil {
-		exitWithError(err)
+		return err
+	}
+	defer shutdownTracing()
+
+	if opts.MetricsFile != "" {
+		m := metrics.New()
+		remoteLibrary.Observe(m)
+		transports.Metrics = m.Transport
+		defer writeMetrics(m, opts.MetricsFile)
+	}
+	remote.SetTransport(transports)
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "scan")
+	defer span.End()
+
+	if profile.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, profile.Timeout)
+		defer cancel()
+	}
+
+	result, errs := remoteLibrary.ScanContext(ctx, num, profile.ScannerOptions(remote.ScannerOptions{}))
+
+	if store := historyStore(opts.NoHistory); store != nil {
+		metadata := map[string]string{
+			history.MetadataSource:  "cli",
+			history.MetadataVersion: build.String(),
+		}
+		if opts.Profile != "" {
+			metadata[history.MetadataProfile] = opts.Profile
+		}
+		if err := store.Save(history.NewScan(num, result, errs, metadata)); err != nil {
+			logrus.WithField("error", err).Warn("Unable to save scan in history")
+		}
+	}
+
+	w := color.Output
+	if opts.OutputFile != "" {
+		file, err := os.Create(opts.OutputFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return output.GetOutput(outputKey, w).Write(result, errs)
+}
+
+// writeMetrics writes metrics to the given file, failures
+// are only logged as they don't affect scan results
+func writeMetrics(m *metrics.Metrics, path string) {
+	if err := m.WriteFile(path); err != nil {
+		logrus.WithField("error", err).Warn("Unable to write metrics file")
 	}
 }
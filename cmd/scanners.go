@@ -3,12 +3,14 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
-	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"strings"
 )
 
 type ScannersCmdOptions struct {
-	Plugin []string
+	Plugin           []string
+	DisabledScanners []string
+	OnlyScanners     []string
 }
 
 func init() {
@@ -16,7 +18,9 @@ func init() {
 	scannersCmd := NewScannersCmd(opts)
 
 	fl := scannersCmd.Flags()
-	fl.StringSliceVar(&opts.Plugin, "plugin", []string{}, "Output file")
+	fl.StringSliceVar(&opts.Plugin, "plugin", []string{}, "Extra scanner plugin to use")
+	fl.StringArrayVarP(&opts.DisabledScanners, "disable", "D", []string{}, "Scanner to hide (name, glob such as \"google*\", or \"tag:<tag>\", prefix with ! to negate)")
+	fl.StringArrayVar(&opts.OnlyScanners, "only", []string{}, "Scanner to list exclusively, accepts the same rules as --disable")
 
 	rootCmd.AddCommand(scannersCmd)
 }
@@ -24,21 +28,24 @@ func init() {
 func NewScannersCmd(opts *ScannersCmdOptions) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "scanners",
-		Example: "phoneinfoga scanners",
+		Example: "phoneinfoga scanners --only tag:network",
 		Short:   "Display list of loaded scanners",
 		Run: func(cmd *cobra.Command, args []string) {
-			for _, p := range opts.Plugin {
-				err := remote.OpenPlugin(p)
-				if err != nil {
-					exitWithError(err)
-				}
+			loadPlugins(opts.Plugin)
+
+			f, err := newFilterEngine(nil, opts.DisabledScanners, opts.OnlyScanners)
+			if err != nil {
+				exitWithError(err)
 			}
 
-			remoteLibrary := remote.NewLibrary(filter.NewEngine())
+			remoteLibrary := remote.NewLibrary(f)
 			remote.InitScanners(remoteLibrary)
 
 			for i, s := range remoteLibrary.GetAllScanners() {
 				fmt.Printf("%s\n%s\n", s.Name(), s.Description())
+				if tags := remote.ScannerTags(s); len(tags) > 0 {
+					fmt.Printf("Tags: %s\n", strings.Join(tags, ", "))
+				}
 				if i < len(remoteLibrary.GetAllScanners()) {
 					fmt.Printf("\n")
 				}
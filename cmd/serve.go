@@ -1,27 +1,49 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/sundowndev/phoneinfoga/v2/build"
-	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
+	"github.com/sundowndev/phoneinfoga/v2/lib/netguard"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tlsconfig"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
 	"github.com/sundowndev/phoneinfoga/v2/web"
 	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
-	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 )
 
 type ServeCmdOptions struct {
 	HttpPort         int
 	DisableClient    bool
 	DisabledScanners []string
+	OnlyScanners     []string
 	PluginPaths      []string
 	EnvFiles         []string
+	FixturesMode     string
+	FixturesDir      string
+	NoHistory        bool
+	APIKeysFile      string
+	Metrics          bool
+	Tracing          string
+	TracingFile      string
+	ReadTimeout      time.Duration
+	WriteTimeout     time.Duration
+	IdleTimeout      time.Duration
+	ShutdownTimeout  time.Duration
+	TLSCert          string
+	TLSKey           string
+	TLSClientCA      string
 }
 
 func init() {
@@ -33,9 +55,24 @@ func init() {
 	// Register flags
 	cmd.PersistentFlags().IntVarP(&opts.HttpPort, "port", "p", 5000, "HTTP port")
 	cmd.PersistentFlags().BoolVar(&opts.DisableClient, "no-client", false, "Disable web client (REST API only)")
-	cmd.PersistentFlags().StringArrayVarP(&opts.DisabledScanners, "disable", "D", []string{}, "Scanner to skip for the scans")
+	cmd.PersistentFlags().StringArrayVarP(&opts.DisabledScanners, "disable", "D", []string{}, "Scanner to skip for the scans (name, glob such as \"google*\", or \"tag:<tag>\", prefix with ! to negate)")
+	cmd.PersistentFlags().StringArrayVar(&opts.OnlyScanners, "only", []string{}, "Scanner to run exclusively for the scans, accepts the same rules as --disable")
 	cmd.PersistentFlags().StringArrayVar(&opts.PluginPaths, "plugin", []string{}, "Extra scanner plugin to use for the scans")
+	cmd.PersistentFlags().BoolVar(&opts.NoHistory, "no-history", false, "Do not save scans in history")
+	cmd.PersistentFlags().StringVar(&opts.APIKeysFile, "api-keys-file", "", "YAML file of API keys required to use the REST API")
+	cmd.PersistentFlags().BoolVar(&opts.Metrics, "metrics", false, "Expose Prometheus metrics on /metrics")
+	cmd.PersistentFlags().StringVar(&opts.Tracing, "tracing", "", "Export OpenTelemetry traces of requests and scans (otlp, stdout, file)")
+	cmd.PersistentFlags().StringVar(&opts.TracingFile, "tracing-file", "", "File to append traces to with the file exporter")
+	cmd.PersistentFlags().DurationVar(&opts.ReadTimeout, "read-timeout", 30*time.Second, "Maximum duration for reading a request (0 for no timeout)")
+	cmd.PersistentFlags().DurationVar(&opts.WriteTimeout, "write-timeout", 5*time.Minute, "Maximum duration for writing a response, including the scan (0 for no timeout)")
+	cmd.PersistentFlags().DurationVar(&opts.IdleTimeout, "idle-timeout", 2*time.Minute, "Maximum time to wait for the next request on keep-alive connections (0 for no timeout)")
+	cmd.PersistentFlags().DurationVar(&opts.ShutdownTimeout, "shutdown-timeout", 25*time.Second, "Time to wait for running requests and scans when stopping before cancelling them")
+	cmd.PersistentFlags().StringVar(&opts.TLSCert, "tls-cert", "", "Certificate file to serve HTTPS with, reloaded when it changes")
+	cmd.PersistentFlags().StringVar(&opts.TLSKey, "tls-key", "", "Private key file of the TLS certificate")
+	cmd.PersistentFlags().StringVar(&opts.TLSClientCA, "tls-client-ca", "", "CA certificates file to require and verify TLS client certificates with")
 	cmd.PersistentFlags().StringSliceVar(&opts.EnvFiles, "env-file", []string{}, "Env files to parse environment variables from (looks for .env by default)")
+	cmd.PersistentFlags().StringVar(&opts.FixturesMode, "fixtures-mode", "", "Record or replay HTTP responses of suppliers (record, replay)")
+	cmd.PersistentFlags().StringVar(&opts.FixturesDir, "fixtures-dir", "", "Directory to store HTTP fixtures in (default \"fixtures\")")
 }
 
 func NewServeCmd(opts *ServeCmdOptions) *cobra.Command {
@@ -43,38 +80,176 @@ func NewServeCmd(opts *ServeCmdOptions) *cobra.Command {
 		Use:   "serve",
 		Short: "Serve web client",
 		PreRun: func(cmd *cobra.Command, args []string) {
-			err := godotenv.Load(opts.EnvFiles...)
+			if err := loadEnv(cmd, opts.EnvFiles); err != nil {
+				exitWithError(err)
+			}
+
+			err := setFlagDefaults(cmd, map[string]string{
+				"port":             portString(cfg.Server.Port),
+				"no-client":        strconv.FormatBool(cfg.Server.NoClient),
+				"fixtures-mode":    cfg.Fixtures.Mode,
+				"fixtures-dir":     cfg.Fixtures.Dir,
+				"api-keys-file":    cfg.Auth.KeysFile,
+				"metrics":          strconv.FormatBool(cfg.Server.Metrics),
+				"tracing":          cfg.Tracing.Exporter,
+				"tracing-file":     cfg.Tracing.File,
+				"read-timeout":     durationString(cfg.Server.ReadTimeout),
+				"write-timeout":    durationString(cfg.Server.WriteTimeout),
+				"idle-timeout":     durationString(cfg.Server.IdleTimeout),
+				"shutdown-timeout": durationString(cfg.Server.ShutdownTimeout),
+				"tls-cert":         cfg.Server.TLSCert,
+				"tls-key":          cfg.Server.TLSKey,
+				"tls-client-ca":    cfg.Server.TLSClientCA,
+			})
 			if err != nil {
-				logrus.WithField("error", err).Debug("Error loading .env file")
+				exitWithError(err)
+			}
+
+			if err := installFixtures(opts.FixturesMode, opts.FixturesDir); err != nil {
+				exitWithError(err)
 			}
 
-			for _, p := range opts.PluginPaths {
-				err := remote.OpenPlugin(p)
-				if err != nil {
-					exitWithError(err)
-				}
+			pluginErrs := loadPlugins(opts.PluginPaths)
+
+			if err := loadDorks(); err != nil {
+				exitWithError(err)
 			}
 
 			// Initialize remote library
-			f := filter.NewEngine()
-			f.AddRule(opts.DisabledScanners...)
+			f, err := newFilterEngine(nil, opts.DisabledScanners, opts.OnlyScanners)
+			if err != nil {
+				exitWithError(err)
+			}
 			handlers.Init(f)
+			handlers.PluginErrors = pluginErrs
+			handlers.Config = cfg
+			handlers.History = historyStore(opts.NoHistory)
+			guard, err := netguard.New(cfg.Server.WebhookAllowedNetworks...)
+			if err != nil {
+				exitWithError(err)
+			}
+			if cfg.Server.WebhookSecret == "" {
+				logrus.Infof("Callback URLs are disabled, set %s to enable them", webhook.SecretEnv)
+			}
+			handlers.Webhooks = webhook.NewDispatcher(webhook.Options{Secret: cfg.Server.WebhookSecret, Guard: guard})
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			if build.IsRelease() && os.Getenv("GIN_MODE") == "" {
-				gin.SetMode(gin.ReleaseMode)
+			if err := runServe(opts); err != nil {
+				exitWithError(err)
 			}
+		},
+	}
+}
 
-			srv, err := web.NewServer(opts.DisableClient)
-			if err != nil {
-				log.Fatal(err)
-			}
+// runServe serves the REST API and the web client until a signal is
+// received. Errors are returned rather than exiting, so traces are
+// flushed whatever the outcome.
+func runServe(opts *ServeCmdOptions) error {
+	if build.IsRelease() && os.Getenv("GIN_MODE") == "" {
+		gin.SetMode(gin.ReleaseMode)
+	}
 
-			addr := fmt.Sprintf(":%d", opts.HttpPort)
-			fmt.Printf("Listening on %s\n", addr)
-			if err := srv.ListenAndServe(addr); err != nil && err != http.ErrServerClosed {
-				log.Fatalf("listen: %s\n", err)
-			}
-		},
+	shutdownTracing, err := setupTracing(opts.Tracing, opts.TracingFile)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	cfg.Auth.KeysFile = opts.APIKeysFile
+	keyring, err := cfg.Keyring()
+	if err != nil {
+		return err
+	}
+	if keyring.Len() > 0 {
+		logrus.WithField("keys", keyring.Len()).Info("API key authentication enabled")
+	}
+
+	limiter, err := ratelimit.NewLimiter(cfg.Server.RateLimits...)
+	if err != nil {
+		return err
+	}
+
+	serverOpts := []web.Option{
+		web.WithKeyring(keyring),
+		web.WithRateLimiter(limiter),
+		web.WithTrustedProxies(cfg.Server.TrustedProxies),
+		web.WithTimeouts(web.Timeouts{Read: opts.ReadTimeout, Write: opts.WriteTimeout, Idle: opts.IdleTimeout}),
+	}
+	if opts.TLSCert != "" || opts.TLSKey != "" || opts.TLSClientCA != "" {
+		certs, err := tlsconfig.NewReloader(tlsconfig.Options{
+			CertFile:     opts.TLSCert,
+			KeyFile:      opts.TLSKey,
+			ClientCAFile: opts.TLSClientCA,
+		})
+		if err != nil {
+			return err
+		}
+		serverOpts = append(serverOpts, web.WithTLS(certs.Config()))
+	}
+	if opts.Metrics {
+		m := metrics.New()
+		handlers.RemoteLibrary.Observe(m)
+		transports.Metrics = m.Transport
+		m.RegisterJobQueue(handlers.Webhooks.Pending)
+		serverOpts = append(serverOpts, web.WithMetrics(m))
+	}
+	remote.SetTransport(transports)
+
+	srv, err := web.NewServer(opts.DisableClient, serverOpts...)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	addr := fmt.Sprintf(":%d", opts.HttpPort)
+	fmt.Printf("Listening on %s\n", addr)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe(addr)
+	}()
+
+	select {
+	case err := <-errc:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("listen: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		stop()
+	}
+
+	logrus.WithField("timeout", opts.ShutdownTimeout).Info("Shutting down, waiting for running requests and scans")
+	shutdown(srv, opts.ShutdownTimeout)
+	return nil
+}
+
+// shutdown stops the server, then waits for scans running in the background
+// and their webhook deliveries. Whatever is still running after the
+// timeout is cancelled.
+func shutdown(srv *web.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logrus.WithField("error", err).Warn("Running requests were cancelled")
+	}
+	if err := handlers.Webhooks.Shutdown(ctx); err != nil {
+		logrus.WithField("error", err).Warn("Background scans and webhook deliveries were cancelled")
+	}
+}
+
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func portString(port int) string {
+	if port == 0 {
+		return ""
 	}
+	return strconv.Itoa(port)
 }
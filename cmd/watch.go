@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/sundowndev/phoneinfoga/v2/build"
+	"github.com/sundowndev/phoneinfoga/v2/lib/config"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/output"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tracing"
+	"github.com/sundowndev/phoneinfoga/v2/lib/watch"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type WatchCmdOptions struct {
+	Input            string
+	Interval         time.Duration
+	StatePath        string
+	Once             bool
+	DisabledScanners []string
+	OnlyScanners     []string
+	PluginPaths      []string
+	EnvFiles         []string
+	OutputFormat     string
+	OutputFile       string
+	Webhooks         []string
+	Profile          string
+	NoHistory        bool
+	MetricsFile      string
+	Tracing          string
+	TracingFile      string
+}
+
+func init() {
+	// Register command
+	opts := &WatchCmdOptions{}
+	cmd := NewWatchCmd(opts)
+	rootCmd.AddCommand(cmd)
+
+	// Register flags
+	cmd.Flags().StringVarP(&opts.Input, "input", "i", "", "Text file containing phone numbers to watch (one per line)")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 24*time.Hour, "Time between two scans of a number")
+	cmd.Flags().StringVar(&opts.StatePath, "state", "", "File to persist latest scans in (default \"~/.config/phoneinfoga/watch.json\")")
+	cmd.Flags().BoolVar(&opts.Once, "once", false, "Scan numbers that are due once, then exit")
+	cmd.Flags().StringArrayVarP(&opts.DisabledScanners, "disable", "D", []string{}, "Scanner to skip for the scans (name, glob such as \"google*\", or \"tag:<tag>\", prefix with ! to negate)")
+	cmd.Flags().StringArrayVar(&opts.OnlyScanners, "only", []string{}, "Scanner to run exclusively for the scans, accepts the same rules as --disable")
+	cmd.Flags().StringArrayVar(&opts.PluginPaths, "plugin", []string{}, "Extra scanner plugin to use for the scans")
+	cmd.Flags().StringSliceVar(&opts.EnvFiles, "env-file", []string{}, "Env files to parse environment variables from (looks for .env by default)")
+	cmd.Flags().StringVar(&opts.OutputFormat, "format", "console", "Output format of changes (console, json)")
+	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "", "Output file to append changes to")
+	cmd.Flags().StringArrayVar(&opts.Webhooks, "webhook", []string{}, "URL to post changes to as JSON")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "Scan profile to use (quick, full, passive, or a profile from the config file)")
+	cmd.Flags().BoolVar(&opts.NoHistory, "no-history", false, "Do not save scans in history")
+	cmd.Flags().StringVar(&opts.Tracing, "tracing", "", "Export OpenTelemetry traces of the scans (otlp, stdout, file)")
+	cmd.Flags().StringVar(&opts.TracingFile, "tracing-file", "", "File to append traces to with the file exporter")
+	cmd.Flags().StringVar(&opts.MetricsFile, "metrics-file", "", "File to write Prometheus metrics to after each scan, e.g. for the node exporter textfile collector")
+	_ = cmd.MarkFlagRequired("input")
+}
+
+func NewWatchCmd(opts *WatchCmdOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:     "watch",
+		Example: "phoneinfoga watch --input numbers.txt --interval 24h --webhook https://example.com/hook",
+		Short:   "Scan phone numbers periodically and report changes",
+		Long:    "Scan phone numbers periodically and report changes. The first scan of a number is used as a baseline, following scans are compared to the previous one and only changes are reported.",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := loadEnv(cmd, opts.EnvFiles); err != nil {
+				exitWithError(err)
+			}
+			if opts.Interval <= 0 {
+				exitWithError(errors.New("interval must be positive"))
+			}
+
+			err := setFlagDefaults(cmd, map[string]string{
+				"tracing":      cfg.Tracing.Exporter,
+				"tracing-file": cfg.Tracing.File,
+			})
+			if err != nil {
+				exitWithError(err)
+			}
+
+			profile := &config.Profile{}
+			if opts.Profile != "" {
+				p, err := cfg.Profile(opts.Profile)
+				if err != nil {
+					exitWithError(err)
+				}
+				profile = p
+			}
+
+			numbers := readWatchList(opts.Input)
+
+			state, err := watch.LoadState(opts.StatePath)
+			if err != nil {
+				exitWithError(err)
+			}
+
+			if err := runWatch(opts, profile, numbers, state); err != nil && !errors.Is(err, context.Canceled) {
+				exitWithError(err)
+			}
+		},
+	}
+}
+
+// runWatch scans numbers until a signal is received, or once with --once.
+// Errors are returned rather than exiting, so traces are flushed and the
+// output file is closed whatever the outcome.
+func runWatch(opts *WatchCmdOptions, profile *config.Profile, numbers []*number.Number, state *watch.State) error {
+	notifiers, closeOutput := watchNotifiers(opts)
+	defer closeOutput()
+
+	shutdownTracing, err := setupTracing(opts.Tracing, opts.TracingFile)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	scan, err := watchScanFunc(opts, profile)
+	if err != nil {
+		return err
+	}
+	w := watch.NewWatcher(numbers, opts.Interval, scan, state, notifiers...)
+	w.SetTimeout(profile.Timeout)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if opts.Once {
+		_, err = w.RunOnce(ctx)
+		return err
+	}
+	logrus.WithField("numbers", len(numbers)).WithField("interval", opts.Interval).Info("Watching phone numbers")
+	return w.Run(ctx)
+}
+
+func readWatchList(path string) []*number.Number {
+	file, err := os.Open(path)
+	if err != nil {
+		exitWithError(err)
+	}
+	defer file.Close()
+
+	numbers, err := watch.ReadNumbers(file)
+	if err != nil {
+		exitWithError(err)
+	}
+	if len(numbers) == 0 {
+		exitWithError(errors.New("no valid phone number to watch"))
+	}
+	return numbers
+}
+
+// watchNotifiers returns notifiers for the output and webhooks, changes are
+// printed to stdout unless they're sent to a file or webhooks.
+func watchNotifiers(opts *WatchCmdOptions) ([]watch.Notifier, func()) {
+	outputKey, err := output.ParseOutputKey(opts.OutputFormat)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if len(opts.Webhooks) > 0 && cfg.Server.WebhookSecret == "" {
+		exitWithError(webhook.ErrNoSecret)
+	}
+
+	var notifiers []watch.Notifier
+	for _, url := range opts.Webhooks {
+		if err := webhook.ValidateURL(url); err != nil {
+			exitWithError(err)
+		}
+		notifiers = append(notifiers, watch.NewWebhookNotifier(url, cfg.Server.WebhookSecret))
+	}
+
+	closeOutput := func() {}
+	var w io.Writer
+	switch {
+	case opts.OutputFile != "":
+		file, err := os.OpenFile(opts.OutputFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			exitWithError(err)
+		}
+		closeOutput = func() { _ = file.Close() }
+		w = file
+	case len(notifiers) == 0:
+		w = color.Output
+	}
+	if w != nil {
+		notifiers = append(notifiers, watch.NewWriterNotifier(w, outputKey == output.JSON))
+	}
+
+	return notifiers, closeOutput
+}
+
+func watchScanFunc(opts *WatchCmdOptions, profile *config.Profile) (watch.ScanFunc, error) {
+	loadPlugins(opts.PluginPaths)
+
+	if err := loadDorks(); err != nil {
+		return nil, err
+	}
+
+	f, err := newFilterEngine(profile, opts.DisabledScanners, opts.OnlyScanners)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteLibrary := remote.NewLibrary(f)
+	remote.InitScanners(remoteLibrary)
+	store := historyStore(opts.NoHistory)
+
+	var m *metrics.Metrics
+	if opts.MetricsFile != "" {
+		m = metrics.New()
+		remoteLibrary.Observe(m)
+		transports.Metrics = m.Transport
+	}
+	remote.SetTransport(transports)
+
+	return func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error) {
+		ctx, span := tracing.Tracer().Start(ctx, "scan")
+		defer span.End()
+
+		results, errs := remoteLibrary.ScanContext(ctx, n, profile.ScannerOptions(remote.ScannerOptions{}))
+
+		if store != nil {
+			metadata := map[string]string{
+				history.MetadataSource:  "watch",
+				history.MetadataVersion: build.String(),
+			}
+			if opts.Profile != "" {
+				metadata[history.MetadataProfile] = opts.Profile
+			}
+			if err := store.Save(history.NewScan(n, results, errs, metadata)); err != nil {
+				logrus.WithField("error", err).Warn("Unable to save scan in history")
+			}
+		}
+		if m != nil {
+			writeMetrics(m, opts.MetricsFile)
+		}
+
+		return results, errs
+	}, nil
+}
@@ -0,0 +1,49 @@
+#!/usr/bin/env python3
+"""Example exec plugin for PhoneInfoga.
+
+A request is read as JSON on stdin, and a single JSON
+response must be written on stdout. Logs go to stderr.
+"""
+import json
+import sys
+
+
+def describe(_):
+    return {
+        "name": "customscanner",
+        "description": "This is a dummy scanner written in Python",
+        "options": [
+            {"name": "CUSTOM_API_KEY", "description": "API key of the custom service", "required": False, "secret": True},
+        ],
+        "tags": ["network"],
+    }
+
+
+def dry_run(req):
+    # Return an error to skip the scanner for the given number
+    if req["number"]["country_code"] == 0:
+        return {"error": "country code is not supported"}
+    return {}
+
+
+def run(req):
+    print("scanning %s" % req["number"]["e164"], file=sys.stderr)
+    return {
+        "result": {
+            "valid": req["number"]["valid"],
+            "info": "This number is known for scams!",
+        }
+    }
+
+
+def main():
+    req = json.load(sys.stdin)
+    methods = {"describe": describe, "dry_run": dry_run, "run": run}
+    if req["method"] not in methods:
+        print("unknown method %s" % req["method"], file=sys.stderr)
+        sys.exit(1)
+    json.dump(methods[req["method"]](req), sys.stdout)
+
+
+if __name__ == "__main__":
+    main()
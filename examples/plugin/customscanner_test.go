@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote/remotetest"
 	"testing"
 )
 
@@ -52,3 +53,7 @@ func TestCustomScanner(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomScanner_Conformance(t *testing.T) {
+	remotetest.TestScanner(t, &customScanner{}, remotetest.Config{})
+}
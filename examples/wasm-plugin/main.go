@@ -0,0 +1,110 @@
+//go:build wasip1
+
+// This is an example WASM scanner plugin. It reads a request on
+// standard input and writes a response on standard output, using the
+// same protocol as exec plugins. See README.md to build it.
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"unsafe"
+)
+
+//go:wasmimport phoneinfoga log
+func hostLog(level uint32, ptr unsafe.Pointer, size uint32)
+
+//go:wasmimport phoneinfoga option
+func hostOption(ptr unsafe.Pointer, size uint32) uint32
+
+//go:wasmimport phoneinfoga http_fetch
+func hostFetch(ptr unsafe.Pointer, size uint32) uint32
+
+//go:wasmimport phoneinfoga read_result
+func hostReadResult(ptr unsafe.Pointer)
+
+type request struct {
+	Method string `json:"method"`
+	Number struct {
+		E164  string `json:"e164"`
+		Valid bool   `json:"valid"`
+	} `json:"number"`
+}
+
+type fetchResponse struct {
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+	Error  string `json:"error"`
+}
+
+func log(msg string) {
+	b := []byte(msg)
+	if len(b) == 0 {
+		return
+	}
+	hostLog(0, unsafe.Pointer(&b[0]), uint32(len(b)))
+}
+
+func readResult(size uint32) []byte {
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	hostReadResult(unsafe.Pointer(&buf[0]))
+	return buf
+}
+
+func option(key string) string {
+	b := []byte(key)
+	return string(readResult(hostOption(unsafe.Pointer(&b[0]), uint32(len(b)))))
+}
+
+func fetch(url string) fetchResponse {
+	b, _ := json.Marshal(map[string]string{"url": url})
+	var res fetchResponse
+	_ = json.Unmarshal(readResult(hostFetch(unsafe.Pointer(&b[0]), uint32(len(b)))), &res)
+	return res
+}
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(1)
+	}
+
+	var res interface{}
+	switch req.Method {
+	case "describe":
+		res = map[string]interface{}{
+			"name":        "wasmscanner",
+			"description": "This is a dummy WASM scanner",
+			"options": []map[string]interface{}{
+				{"name": "WASM_SCANNER_URL", "description": "URL to look the number up", "required": true},
+			},
+			"tags": []string{"network"},
+		}
+	case "dry_run":
+		if option("WASM_SCANNER_URL") == "" {
+			res = map[string]string{"error": "URL is not defined"}
+		} else {
+			res = map[string]string{}
+		}
+	case "run":
+		log("looking up " + req.Number.E164)
+		r := fetch(option("WASM_SCANNER_URL") + "?number=" + url.QueryEscape(req.Number.E164))
+		if r.Error != "" {
+			res = map[string]string{"error": r.Error}
+			break
+		}
+		res = map[string]interface{}{
+			"result": map[string]interface{}{
+				"valid":  req.Number.Valid,
+				"status": r.Status,
+				"info":   r.Body,
+			},
+		}
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(res)
+}
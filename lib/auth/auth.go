@@ -0,0 +1,189 @@
+// Package auth authenticates REST API clients with API keys. Keys
+// are stored as sha256 hashes, each of them can be disabled or
+// restricted to some scanners. A key can also be bound to the identity
+// of TLS client certificates, clients then don't send the key itself.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Header is the request header holding the API key,
+	// keys are also accepted as bearer tokens.
+	Header = "X-API-Key"
+
+	hashPrefix = "sha256:"
+	keyPrefix  = "pik_"
+)
+
+var (
+	ErrMissingKey  = errors.New("missing API key")
+	ErrInvalidKey  = errors.New("invalid API key")
+	ErrDisabledKey = errors.New("API key is disabled")
+	ErrUnknownCert = errors.New("client certificate is not bound to an API key")
+)
+
+// Key is an API key, as written in the configuration or keys file
+type Key struct {
+	Name     string   `yaml:"name" json:"name"`
+	Hash     string   `yaml:"hash,omitempty" json:"-"`
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Scanners []string `yaml:"scanners,omitempty" json:"scanners,omitempty"`
+	// ClientCert is the identity of client certificates authenticated
+	// as this key, such as their common name. The hash is then optional.
+	ClientCert string `yaml:"client_cert,omitempty" json:"client_cert,omitempty"`
+}
+
+// Filter returns the scanners the key is allowed to use, all of them
+// when the allow-list is empty. Rules are the ones of --only flags.
+func (k *Key) Filter() filter.Filter {
+	f := filter.NewEngine()
+	f.AddAllowRule(k.Scanners...)
+	return f
+}
+
+// Restricted reports whether the key has an allow-list of scanners
+func (k *Key) Restricted() bool {
+	return len(k.Scanners) > 0
+}
+
+// AllowsScanner reports whether the key is allowed to use the given scanner
+func (k *Key) AllowsScanner(name string, tags ...string) bool {
+	return !k.Filter().Match(name, tags...)
+}
+
+// Hash returns the hash of a key, as stored in the configuration
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// Generate returns a new random API key
+func Generate() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+type keysFile struct {
+	Keys []Key `yaml:"keys"`
+}
+
+// LoadKeysFile reads keys from a YAML file with a top-level "keys" list
+func LoadKeysFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read API keys file: %w", err)
+	}
+	var f keysFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("API keys file %s is not valid: %v", path, err)
+	}
+	return f.Keys, nil
+}
+
+// Keyring holds API keys allowed to use the REST API
+type Keyring struct {
+	keys []Key
+}
+
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	names := map[string]bool{}
+	for _, k := range keys {
+		if k.Name == "" {
+			return nil, errors.New("API keys must have a name")
+		}
+		if names[k.Name] {
+			return nil, fmt.Errorf("API key %s is defined twice", k.Name)
+		}
+		names[k.Name] = true
+		certOnly := k.Hash == "" && k.ClientCert != ""
+		if !certOnly && (!strings.HasPrefix(k.Hash, hashPrefix) || len(k.Hash) != len(hashPrefix)+sha256.Size*2) {
+			return nil, fmt.Errorf("API key %s has no valid sha256 hash", k.Name)
+		}
+		for _, rule := range k.Scanners {
+			if err := filter.ValidateRule(rule); err != nil {
+				return nil, fmt.Errorf("API key %s: %v", k.Name, err)
+			}
+		}
+	}
+	return &Keyring{keys: keys}, nil
+}
+
+// Len returns the number of keys, authentication is disabled without keys
+func (r *Keyring) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.keys)
+}
+
+// Authenticate returns the key matching the given raw key
+func (r *Keyring) Authenticate(raw string) (*Key, error) {
+	if raw == "" {
+		return nil, ErrMissingKey
+	}
+	hash := []byte(Hash(raw))
+	for i := range r.keys {
+		k := r.keys[i]
+		if subtle.ConstantTimeCompare(hash, []byte(k.Hash)) != 1 {
+			continue
+		}
+		if k.Disabled {
+			return nil, ErrDisabledKey
+		}
+		return &k, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// AuthenticateCert returns the key bound to the given client certificate identity
+func (r *Keyring) AuthenticateCert(identity string) (*Key, error) {
+	for i := range r.keys {
+		k := r.keys[i]
+		if identity == "" || k.ClientCert != identity {
+			continue
+		}
+		if k.Disabled {
+			return nil, ErrDisabledKey
+		}
+		return &k, nil
+	}
+	return nil, ErrUnknownCert
+}
+
+type contextKey struct{}
+
+// NewContext returns a context holding the authenticated key
+func NewContext(ctx context.Context, k *Key) context.Context {
+	return context.WithValue(ctx, contextKey{}, k)
+}
+
+// FromContext returns the authenticated key, nil when authentication is disabled
+func FromContext(ctx context.Context) *Key {
+	k, _ := ctx.Value(contextKey{}).(*Key)
+	return k
+}
+
+// ScannerFilter returns the scanners allowed in the given context, all
+// of them when authentication is disabled
+func ScannerFilter(ctx context.Context) filter.Filter {
+	if k := FromContext(ctx); k != nil {
+		return k.Filter()
+	}
+	return filter.NewEngine()
+}
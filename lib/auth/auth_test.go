@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyring(t *testing.T) {
+	key, err := Generate()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "pik_"))
+
+	keyring, err := NewKeyring(
+		Key{Name: "soar", Hash: Hash(key), Scanners: []string{"local", "tag:offline"}},
+		Key{Name: "old", Hash: Hash("old-key"), Disabled: true},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keyring.Len())
+
+	testcases := []struct {
+		name     string
+		key      string
+		expected string
+		wantErr  error
+	}{
+		{name: "test valid key", key: key, expected: "soar"},
+		{name: "test missing key", key: "", wantErr: ErrMissingKey},
+		{name: "test unknown key", key: "unknown", wantErr: ErrInvalidKey},
+		{name: "test disabled key", key: "old-key", wantErr: ErrDisabledKey},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keyring.Authenticate(tt.key)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.expected, got.Name)
+			}
+		})
+	}
+
+	k, _ := keyring.Authenticate(key)
+	assert.True(t, k.AllowsScanner("local"))
+	assert.True(t, k.AllowsScanner("googlesearch", "offline"))
+	assert.False(t, k.AllowsScanner("numverify", "network", "paid"))
+}
+
+func TestKeyring_AuthenticateCert(t *testing.T) {
+	keyring, err := NewKeyring(
+		Key{Name: "soar", ClientCert: "soar.internal"},
+		Key{Name: "both", Hash: Hash("key"), ClientCert: "siem.internal"},
+		Key{Name: "old", ClientCert: "old.internal", Disabled: true},
+	)
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		identity string
+		expected string
+		wantErr  error
+	}{
+		{name: "test certificate only key", identity: "soar.internal", expected: "soar"},
+		{name: "test key with a hash", identity: "siem.internal", expected: "both"},
+		{name: "test unknown certificate", identity: "unknown.internal", wantErr: ErrUnknownCert},
+		{name: "test anonymous certificate", identity: "", wantErr: ErrUnknownCert},
+		{name: "test disabled key", identity: "old.internal", wantErr: ErrDisabledKey},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keyring.AuthenticateCert(tt.identity)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.expected, got.Name)
+			}
+		})
+	}
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		keys    []Key
+		wantErr string
+	}{
+		{
+			name:    "test missing name",
+			keys:    []Key{{Hash: Hash("key")}},
+			wantErr: "API keys must have a name",
+		},
+		{
+			name:    "test duplicated name",
+			keys:    []Key{{Name: "a", Hash: Hash("key")}, {Name: "a", Hash: Hash("other")}},
+			wantErr: "API key a is defined twice",
+		},
+		{
+			name:    "test plain key",
+			keys:    []Key{{Name: "a", Hash: "key"}},
+			wantErr: "API key a has no valid sha256 hash",
+		},
+		{
+			name:    "test invalid scanner rule",
+			keys:    []Key{{Name: "a", Hash: Hash("key"), Scanners: []string{"["}}},
+			wantErr: `API key a: invalid filter rule "[": syntax error in pattern`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys...)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadKeysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("keys:\n  - name: soar\n    hash: "+Hash("key")+"\n    scanners: [local]\n"), 0600))
+
+	keys, err := LoadKeysFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Key{{Name: "soar", Hash: Hash("key"), Scanners: []string{"local"}}}, keys)
+
+	_, err = LoadKeysFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+	assert.False(t, ScannerFilter(ctx).Match("numverify"))
+
+	k := &Key{Name: "soar", Scanners: []string{"local"}}
+	assert.True(t, k.Restricted())
+	assert.False(t, (&Key{Name: "admin"}).Restricted())
+	ctx = NewContext(ctx, k)
+	assert.Equal(t, k, FromContext(ctx))
+	assert.True(t, ScannerFilter(ctx).Match("numverify"))
+	assert.False(t, ScannerFilter(ctx).Match("local"))
+}
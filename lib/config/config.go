@@ -0,0 +1,394 @@
+// Package config loads PhoneInfoga settings from a YAML configuration file.
+// Settings from the file have the lowest precedence: they're overridden
+// by environment variables, themselves overridden by command line flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/dorks"
+	"github.com/sundowndev/phoneinfoga/v2/lib/fixtures"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/netguard"
+	"github.com/sundowndev/phoneinfoga/v2/lib/plugins"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileEnv is the environment variable giving the configuration file path
+	FileEnv = "PHONEINFOGA_CONFIG"
+	// FileName is the name of configuration files looked up in standard locations
+	FileName = "phoneinfoga.yaml"
+
+	maskedValue = "********"
+)
+
+type Config struct {
+	LogLevel         string                    `yaml:"log_level,omitempty"`
+	LogFormat        string                    `yaml:"log_format,omitempty"`
+	LogFile          string                    `yaml:"log_file,omitempty"`
+	LogMaxSize       int                       `yaml:"log_max_size,omitempty"`
+	LogMaxBackups    int                       `yaml:"log_max_backups,omitempty"`
+	LogMaxAge        int                       `yaml:"log_max_age,omitempty"`
+	LogRedactNumbers bool                      `yaml:"log_redact_numbers,omitempty"`
+	EnvFiles         []string                  `yaml:"env_files,omitempty"`
+	Fixtures         FixturesConfig            `yaml:"fixtures,omitempty"`
+	Scanners         map[string]*ScannerConfig `yaml:"scanners,omitempty"`
+	Output           OutputConfig              `yaml:"output,omitempty"`
+	Server           ServerConfig              `yaml:"server,omitempty"`
+	Plugins          PluginsConfig             `yaml:"plugins,omitempty"`
+	Dorks            DorksConfig               `yaml:"dorks,omitempty"`
+	Profiles         map[string]*Profile       `yaml:"profiles,omitempty"`
+	History          HistoryConfig             `yaml:"history,omitempty"`
+	Auth             AuthConfig                `yaml:"auth,omitempty"`
+	Tracing          TracingConfig             `yaml:"tracing,omitempty"`
+
+	path string
+}
+
+type FixturesConfig struct {
+	Mode string `yaml:"mode,omitempty"`
+	Dir  string `yaml:"dir,omitempty"`
+}
+
+// ScannerConfig holds settings of a single scanner. Options are
+// named like the environment variables the scanner reads.
+type ScannerConfig struct {
+	Enabled *bool             `yaml:"enabled,omitempty"`
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+type OutputConfig struct {
+	Format string `yaml:"format,omitempty"`
+	File   string `yaml:"file,omitempty"`
+}
+
+type ServerConfig struct {
+	Port     int  `yaml:"port,omitempty"`
+	NoClient bool `yaml:"no_client,omitempty"`
+	// WebhookSecret signs payloads posted to callback URLs,
+	// callback URLs are refused when it's not set
+	WebhookSecret string `yaml:"webhook_secret,omitempty"`
+	// WebhookAllowedNetworks are loopback, private or link-local
+	// networks callback URLs may point to, none by default
+	WebhookAllowedNetworks []string `yaml:"webhook_allowed_networks,omitempty"`
+	// RateLimits limit requests of each client to API routes
+	RateLimits []ratelimit.Rule `yaml:"rate_limits,omitempty"`
+	// TrustedProxies are networks of proxies allowed to forward client
+	// IPs in the X-Forwarded-For header, none by default
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
+	// Metrics exposes Prometheus metrics on /metrics
+	Metrics bool `yaml:"metrics,omitempty"`
+	// Timeouts of the HTTP server, ShutdownTimeout is how long running
+	// requests and scans are waited for when the server stops
+	ReadTimeout     time.Duration `yaml:"read_timeout,omitempty"`
+	WriteTimeout    time.Duration `yaml:"write_timeout,omitempty"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout,omitempty"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout,omitempty"`
+	// TLSCert and TLSKey are files the server is served over HTTPS with,
+	// client certificates are required when TLSClientCA is set
+	TLSCert     string `yaml:"tls_cert,omitempty"`
+	TLSKey      string `yaml:"tls_key,omitempty"`
+	TLSClientCA string `yaml:"tls_client_ca,omitempty"`
+}
+
+type HistoryConfig struct {
+	Disabled bool   `yaml:"disabled,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+}
+
+// AuthConfig holds API keys of the REST API, it's
+// open to anyone when no key is defined
+type AuthConfig struct {
+	Keys     []auth.Key `yaml:"keys,omitempty"`
+	KeysFile string     `yaml:"keys_file,omitempty"`
+}
+
+// TracingConfig selects where OpenTelemetry traces are exported,
+// tracing is disabled when no exporter is given
+type TracingConfig struct {
+	Exporter string `yaml:"exporter,omitempty"`
+	File     string `yaml:"file,omitempty"`
+}
+
+type PluginsConfig struct {
+	Dir   string   `yaml:"dir,omitempty"`
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// DorksConfig gives files and directories of dorks generated by the
+// googlesearch scanner, in addition to the built-in ones
+type DorksConfig struct {
+	Paths []string `yaml:"paths,omitempty"`
+}
+
+// Environment variables overriding settings from the configuration file
+const (
+	LogLevelEnv         = logs.LevelEnv
+	LogFormatEnv        = logs.FormatEnv
+	LogFileEnv          = logs.FileEnv
+	LogRedactNumbersEnv = logs.RedactNumbersEnv
+	FixturesModeEnv     = fixtures.ModeEnv
+	FixturesDirEnv      = fixtures.DirEnv
+	OutputFormatEnv     = "PHONEINFOGA_OUTPUT_FORMAT"
+	OutputFileEnv       = "PHONEINFOGA_OUTPUT_FILE"
+	PortEnv             = "PHONEINFOGA_PORT"
+	NoClientEnv         = "PHONEINFOGA_NO_CLIENT"
+	PluginsDirEnv       = plugins.DirEnv
+	HistoryFileEnv      = history.FileEnv
+	WebhookSecretEnv    = webhook.SecretEnv
+	WebhookNetworksEnv  = "PHONEINFOGA_WEBHOOK_ALLOWED_NETWORKS"
+	TrustedProxiesEnv   = "PHONEINFOGA_TRUSTED_PROXIES"
+	APIKeysFileEnv      = "PHONEINFOGA_API_KEYS_FILE"
+	MetricsEnv          = "PHONEINFOGA_METRICS"
+	TracingEnv          = "PHONEINFOGA_TRACING"
+	TracingFileEnv      = "PHONEINFOGA_TRACING_FILE"
+	TLSCertEnv          = "PHONEINFOGA_TLS_CERT"
+	TLSKeyEnv           = "PHONEINFOGA_TLS_KEY"
+	TLSClientCAEnv      = "PHONEINFOGA_TLS_CLIENT_CA"
+	DorksEnv            = dorks.PathsEnv
+)
+
+// Locations returns paths where the configuration file is looked up when not
+// given explicitly: the working directory, then the user configuration directory.
+func Locations() []string {
+	locations := []string{FileName}
+	if dir, err := os.UserConfigDir(); err == nil {
+		locations = append(locations, filepath.Join(dir, "phoneinfoga", FileName))
+	}
+	return locations
+}
+
+// Load reads the configuration from the given path. When path is empty,
+// PHONEINFOGA_CONFIG and standard locations are used, an empty
+// configuration is returned if no file is found.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(FileEnv)
+	}
+	if path != "" {
+		return loadFile(path)
+	}
+
+	for _, p := range Locations() {
+		c, err := loadFile(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		return c, err
+	}
+
+	return &Config{}, nil
+}
+
+func loadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %w", err)
+	}
+
+	c := &Config{path: path}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("config file %s is not valid: %v", path, err)
+	}
+	return c, nil
+}
+
+// Path returns the path of the loaded configuration file, if any
+func (c *Config) Path() string {
+	return c.path
+}
+
+// ApplyEnv overrides settings with the ones defined in environment variables
+func (c *Config) ApplyEnv() error {
+	for env, field := range map[string]*string{
+		LogLevelEnv:      &c.LogLevel,
+		LogFormatEnv:     &c.LogFormat,
+		LogFileEnv:       &c.LogFile,
+		FixturesModeEnv:  &c.Fixtures.Mode,
+		FixturesDirEnv:   &c.Fixtures.Dir,
+		OutputFormatEnv:  &c.Output.Format,
+		OutputFileEnv:    &c.Output.File,
+		PluginsDirEnv:    &c.Plugins.Dir,
+		HistoryFileEnv:   &c.History.Path,
+		WebhookSecretEnv: &c.Server.WebhookSecret,
+		APIKeysFileEnv:   &c.Auth.KeysFile,
+		TracingEnv:       &c.Tracing.Exporter,
+		TracingFileEnv:   &c.Tracing.File,
+		TLSCertEnv:       &c.Server.TLSCert,
+		TLSKeyEnv:        &c.Server.TLSKey,
+		TLSClientCAEnv:   &c.Server.TLSClientCA,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*field = v
+		}
+	}
+
+	if v := os.Getenv(PortEnv); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a valid port: %v", PortEnv, err)
+		}
+		c.Server.Port = port
+	}
+	if v := os.Getenv(NoClientEnv); v != "" {
+		noClient, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a boolean: %v", NoClientEnv, err)
+		}
+		c.Server.NoClient = noClient
+	}
+	if v := os.Getenv(LogRedactNumbersEnv); v != "" {
+		redact, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a boolean: %v", LogRedactNumbersEnv, err)
+		}
+		c.LogRedactNumbers = redact
+	}
+	if v := os.Getenv(DorksEnv); v != "" {
+		c.Dorks.Paths = dorks.SplitPaths(v)
+	}
+	if v := os.Getenv(WebhookNetworksEnv); v != "" {
+		c.Server.WebhookAllowedNetworks = netguard.SplitNetworks(v)
+	}
+	if v := os.Getenv(TrustedProxiesEnv); v != "" {
+		c.Server.TrustedProxies = netguard.SplitNetworks(v)
+	}
+	if v := os.Getenv(MetricsEnv); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a boolean: %v", MetricsEnv, err)
+		}
+		c.Server.Metrics = enabled
+	}
+
+	for _, s := range c.Scanners {
+		for k := range s.Options {
+			if v := os.Getenv(k); v != "" {
+				s.Options[k] = v
+			}
+		}
+	}
+
+	return nil
+}
+
+// Keyring returns API keys defined in the configuration and the keys file
+func (c *Config) Keyring() (*auth.Keyring, error) {
+	keys := append([]auth.Key{}, c.Auth.Keys...)
+	if c.Auth.KeysFile != "" {
+		fileKeys, err := auth.LoadKeysFile(c.Auth.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+	return auth.NewKeyring(keys...)
+}
+
+// DisabledScanners returns names of scanners disabled in the configuration, sorted
+func (c *Config) DisabledScanners() []string {
+	var names []string
+	for name, s := range c.Scanners {
+		if s != nil && s.Enabled != nil && !*s.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExportScannerOptions sets scanner options from the configuration as
+// environment variables, so scanners read them like any other option.
+// Variables already defined in the environment are left untouched.
+func (c *Config) ExportScannerOptions() error {
+	for _, s := range c.Scanners {
+		if s == nil {
+			continue
+		}
+		for k, v := range s.Options {
+			if _, ok := os.LookupEnv(k); ok {
+				continue
+			}
+			if err := os.Setenv(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Masked returns a copy of the configuration with secret values masked
+func (c *Config) Masked() *Config {
+	masked := *c
+	if c.Server.WebhookSecret != "" {
+		masked.Server.WebhookSecret = maskedValue
+	}
+	masked.Scanners = map[string]*ScannerConfig{}
+	for name, s := range c.Scanners {
+		if s == nil {
+			continue
+		}
+		masked.Scanners[name] = &ScannerConfig{Enabled: s.Enabled, Options: maskOptions(s.Options)}
+	}
+	if c.Profiles != nil {
+		masked.Profiles = map[string]*Profile{}
+		for name, p := range c.Profiles {
+			if p == nil {
+				continue
+			}
+			mp := *p
+			mp.Options = maskOptions(p.Options)
+			masked.Profiles[name] = &mp
+		}
+	}
+	return &masked
+}
+
+func maskOptions(opts map[string]string) map[string]string {
+	if opts == nil {
+		return nil
+	}
+	masked := map[string]string{}
+	for k, v := range opts {
+		if IsSecret(k) && v != "" {
+			v = maskedValue
+		}
+		masked[k] = v
+	}
+	return masked
+}
+
+// IsSecret reports whether an option holds a secret, based on its name
+func IsSecret(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// YAML returns the configuration encoded as YAML
+func (c *Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
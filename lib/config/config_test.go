@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestLoad(t *testing.T) {
+	c, err := Load("testdata/phoneinfoga.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "testdata/phoneinfoga.yaml", c.Path())
+	assert.Equal(t, &Config{
+		LogLevel: "info",
+		EnvFiles: []string{".env.local"},
+		Scanners: map[string]*ScannerConfig{
+			"googlecse": {Enabled: boolPtr(false)},
+			"numverify": {
+				Enabled: boolPtr(true),
+				Options: map[string]string{"NUMVERIFY_API_KEY": "5ad5554ac240e4d3d31107941b35a5eb"},
+			},
+		},
+		Output:  OutputConfig{Format: "json"},
+		Server:  ServerConfig{Port: 8080, NoClient: true, ShutdownTimeout: 25 * time.Second},
+		Plugins: PluginsConfig{Dir: "/opt/phoneinfoga/plugins", Paths: []string{"./customscanner.so"}},
+		path:    "testdata/phoneinfoga.yaml",
+	}, c)
+	assert.Equal(t, []string{"googlecse"}, c.DisabledScanners())
+}
+
+func TestLoad_Errors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "test invalid yaml",
+			content: "server: [",
+			wantErr: "config file %s is not valid: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:    "test invalid type",
+			content: "server:\n  port: abc",
+			wantErr: "config file %s is not valid: yaml: unmarshal errors:\n  line 2: cannot unmarshal !!str `abc` into int",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(path)
+			assert.EqualError(t, err, fmt.Sprintf(tt.wantErr, path))
+		})
+	}
+
+	_, err := Load("testdata/doesnotexist.yaml")
+	assert.EqualError(t, err, "unable to read config file: open testdata/doesnotexist.yaml: no such file or directory")
+}
+
+func TestLoad_Locations(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	// No config file found
+	c, err := Load("")
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{}, c)
+
+	_ = os.Setenv(FileEnv, "testdata/phoneinfoga.yaml")
+	c, err = Load("")
+	_ = os.Unsetenv(FileEnv)
+	assert.NoError(t, err)
+	assert.Equal(t, "testdata/phoneinfoga.yaml", c.Path())
+}
+
+func TestConfig_ApplyEnv(t *testing.T) {
+	c, err := Load("testdata/phoneinfoga.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{
+		PortEnv:             "9000",
+		OutputFormatEnv:     "console",
+		MetricsEnv:          "true",
+		TracingEnv:          "otlp",
+		LogFormatEnv:        "json",
+		LogRedactNumbersEnv: "true",
+		DorksEnv:            "./dorks, /etc/phoneinfoga/dorks.yaml",
+		WebhookNetworksEnv:  "10.0.0.0/8,192.168.1.10",
+		TrustedProxiesEnv:   "10.0.0.1",
+		"NUMVERIFY_API_KEY": "fromenv",
+	} {
+		_ = os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	assert.NoError(t, c.ApplyEnv())
+	assert.Equal(t, 9000, c.Server.Port)
+	assert.True(t, c.Server.NoClient)
+	assert.True(t, c.Server.Metrics)
+	assert.Equal(t, "otlp", c.Tracing.Exporter)
+	assert.Equal(t, "json", c.LogFormat)
+	assert.True(t, c.LogRedactNumbers)
+	assert.Equal(t, "console", c.Output.Format)
+	assert.Equal(t, []string{"./dorks", "/etc/phoneinfoga/dorks.yaml"}, c.Dorks.Paths)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, c.Server.WebhookAllowedNetworks)
+	assert.Equal(t, []string{"10.0.0.1"}, c.Server.TrustedProxies)
+	assert.Equal(t, "fromenv", c.Scanners["numverify"].Options["NUMVERIFY_API_KEY"])
+
+	_ = os.Setenv(PortEnv, "abc")
+	assert.EqualError(t, c.ApplyEnv(), "PHONEINFOGA_PORT must be a valid port: strconv.Atoi: parsing \"abc\": invalid syntax")
+}
+
+func TestConfig_ExportScannerOptions(t *testing.T) {
+	c := &Config{Scanners: map[string]*ScannerConfig{
+		"numverify": {Options: map[string]string{"NUMVERIFY_API_KEY": "fromfile"}},
+		"googlecse": {Options: map[string]string{"GOOGLECSE_CX": "fromfile"}},
+	}}
+
+	_ = os.Setenv("GOOGLECSE_CX", "fromenv")
+	defer os.Unsetenv("GOOGLECSE_CX")
+	defer os.Unsetenv("NUMVERIFY_API_KEY")
+
+	assert.NoError(t, c.ExportScannerOptions())
+	assert.Equal(t, "fromfile", os.Getenv("NUMVERIFY_API_KEY"))
+	assert.Equal(t, "fromenv", os.Getenv("GOOGLECSE_CX"))
+}
+
+func TestConfig_Masked(t *testing.T) {
+	c, err := Load("testdata/phoneinfoga.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Scanners["googlecse"].Options = map[string]string{"GOOGLECSE_CX": "cx", "GOOGLE_API_KEY": "key"}
+	c.Server.WebhookSecret = "secret"
+
+	data, err := c.Masked().YAML()
+	assert.NoError(t, err)
+	assert.Equal(t, `log_level: info
+env_files:
+  - .env.local
+scanners:
+  googlecse:
+    enabled: false
+    options:
+      GOOGLE_API_KEY: '********'
+      GOOGLECSE_CX: cx
+  numverify:
+    enabled: true
+    options:
+      NUMVERIFY_API_KEY: '********'
+output:
+  format: json
+server:
+  port: 8080
+  no_client: true
+  webhook_secret: '********'
+  shutdown_timeout: 25s
+plugins:
+  dir: /opt/phoneinfoga/plugins
+  paths:
+    - ./customscanner.so
+`, string(data))
+
+	// Original configuration is left untouched
+	assert.Equal(t, "key", c.Scanners["googlecse"].Options["GOOGLE_API_KEY"])
+	assert.Equal(t, "secret", c.Server.WebhookSecret)
+}
+
+func TestConfig_Keyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	err := os.WriteFile(path, []byte("keys:\n  - name: file\n    hash: "+auth.Hash("file-key")+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Config{Auth: AuthConfig{
+		Keys:     []auth.Key{{Name: "config", Hash: auth.Hash("config-key"), Scanners: []string{"local"}}},
+		KeysFile: path,
+	}}
+	keyring, err := c.Keyring()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, keyring.Len())
+
+	k, err := keyring.Authenticate("file-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "file", k.Name)
+
+	c.Auth.Keys = append(c.Auth.Keys, auth.Key{Name: "file", Hash: auth.Hash("other")})
+	_, err = c.Keyring()
+	assert.EqualError(t, err, "API key file is defined twice")
+
+	keyring, err = (&Config{}).Keyring()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, keyring.Len())
+}
+
+func TestLoad_RateLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "phoneinfoga.yaml")
+	err := os.WriteFile(path, []byte(`server:
+  rate_limits:
+    - route: /api/v2/scanners/:scanner/run
+      requests: 10
+      per: 1m
+      daily_quota: 500
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []ratelimit.Rule{
+		{Route: "/api/v2/scanners/:scanner/run", Requests: 10, Per: time.Minute, DailyQuota: 500},
+	}, c.Server.RateLimits)
+}
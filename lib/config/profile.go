@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+// Profile bundles scan settings under a name. Scanners lists the only
+// scanners to run, all of them run when it's empty. Options are
+// scanner options, named like the environment variables scanners read.
+type Profile struct {
+	Scanners []string          `yaml:"scanners,omitempty" json:"scanners,omitempty"`
+	Disable  []string          `yaml:"disable,omitempty" json:"disable,omitempty"`
+	Options  map[string]string `yaml:"options,omitempty" json:"options,omitempty"`
+	Timeout  time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Output   string            `yaml:"output,omitempty" json:"output,omitempty"`
+}
+
+// DefaultProfiles returns built-in profiles, they can be overridden in the configuration file
+func DefaultProfiles() map[string]*Profile {
+	return map[string]*Profile{
+		"quick": {
+			Scanners: []string{"local"},
+		},
+		"full": {},
+		"passive": {
+			Disable: []string{"numverify", "googlecse"},
+		},
+	}
+}
+
+// Profile returns the profile with the given name, from
+// the configuration file or from built-in profiles
+func (c *Config) Profile(name string) (*Profile, error) {
+	if p, ok := c.Profiles[name]; ok && p != nil {
+		return p, nil
+	}
+	if p, ok := DefaultProfiles()[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("profile %q not found", name)
+}
+
+// ProfileNames returns names of all available profiles, sorted
+func (c *Config) ProfileNames() []string {
+	var names []string
+	for name := range DefaultProfiles() {
+		names = append(names, name)
+	}
+	for name := range c.Profiles {
+		if _, ok := DefaultProfiles()[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddRules adds the profile scanner selection to the given filter engine
+func (p *Profile) AddRules(e *filter.Engine) {
+	e.AddAllowRule(p.Scanners...)
+	e.AddRule(p.Disable...)
+}
+
+// WithScanners returns a copy of the profile running the given scanners
+// instead of its own ones, the profile is returned as is when none is given
+func (p *Profile) WithScanners(scanners []string) *Profile {
+	if len(scanners) == 0 {
+		return p
+	}
+	c := *p
+	c.Scanners = scanners
+	return &c
+}
+
+// Filter returns a filter engine with the profile scanner selection
+func (p *Profile) Filter() *filter.Engine {
+	e := filter.NewEngine()
+	p.AddRules(e)
+	return e
+}
+
+// ScannerOptions returns the profile options, overridden by the given ones
+func (p *Profile) ScannerOptions(opts remote.ScannerOptions) remote.ScannerOptions {
+	merged := remote.ScannerOptions{}
+	for k, v := range p.Options {
+		merged[k] = v
+	}
+	for k, v := range opts {
+		merged[k] = v
+	}
+	return merged
+}
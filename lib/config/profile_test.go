@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+func TestConfig_Profile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	err := os.WriteFile(path, []byte(`
+profiles:
+  quick:
+    scanners: [local, numverify]
+  custom:
+    disable: [googlesearch]
+    options:
+      NUMVERIFY_API_KEY: secret
+    timeout: 30s
+    output: json
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		profile  string
+		expected *Profile
+		wantErr  string
+	}{
+		{
+			name:    "test configured profile",
+			profile: "custom",
+			expected: &Profile{
+				Disable: []string{"googlesearch"},
+				Options: map[string]string{"NUMVERIFY_API_KEY": "secret"},
+				Timeout: 30 * time.Second,
+				Output:  "json",
+			},
+		},
+		{
+			name:     "test overridden built-in profile",
+			profile:  "quick",
+			expected: &Profile{Scanners: []string{"local", "numverify"}},
+		},
+		{
+			name:     "test built-in profile",
+			profile:  "passive",
+			expected: &Profile{Disable: []string{"numverify", "googlecse"}},
+		},
+		{
+			name:    "test unknown profile",
+			profile: "unknown",
+			wantErr: "profile \"unknown\" not found",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := c.Profile(tt.profile)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+
+	assert.Equal(t, []string{"custom", "full", "passive", "quick"}, c.ProfileNames())
+}
+
+func TestProfile_Filter(t *testing.T) {
+	testcases := []struct {
+		name     string
+		profile  *Profile
+		expected map[string]bool
+	}{
+		{
+			name:    "test quick profile",
+			profile: DefaultProfiles()["quick"],
+			expected: map[string]bool{
+				"local":     false,
+				"numverify": true,
+				"ovh":       true,
+			},
+		},
+		{
+			name:    "test full profile",
+			profile: DefaultProfiles()["full"],
+			expected: map[string]bool{
+				"local":     false,
+				"numverify": false,
+				"googlecse": false,
+			},
+		},
+		{
+			name:    "test passive profile",
+			profile: DefaultProfiles()["passive"],
+			expected: map[string]bool{
+				"local":     false,
+				"numverify": true,
+				"googlecse": true,
+			},
+		},
+		{
+			name:    "test scanners given replace the profile ones",
+			profile: DefaultProfiles()["quick"].WithScanners([]string{"numverify"}),
+			expected: map[string]bool{
+				"local":     true,
+				"numverify": false,
+				"ovh":       true,
+			},
+		},
+		{
+			name:    "test scanners given keep disabled ones",
+			profile: DefaultProfiles()["passive"].WithScanners([]string{"local", "numverify"}),
+			expected: map[string]bool{
+				"local":     false,
+				"numverify": true,
+				"ovh":       true,
+			},
+		},
+		{
+			name:    "test no scanner given",
+			profile: DefaultProfiles()["quick"].WithScanners(nil),
+			expected: map[string]bool{
+				"local":     false,
+				"numverify": true,
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.profile.Filter()
+			for name, ignored := range tt.expected {
+				assert.Equal(t, ignored, f.Match(name), name)
+			}
+		})
+	}
+}
+
+func TestProfile_ScannerOptions(t *testing.T) {
+	p := &Profile{Options: map[string]string{"A": "profile", "B": "profile"}}
+
+	assert.Equal(t, remote.ScannerOptions{"A": "profile", "B": "request"}, p.ScannerOptions(remote.ScannerOptions{"B": "request"}))
+}
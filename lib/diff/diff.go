@@ -0,0 +1,267 @@
+// Package diff compares results of two scans of the same number.
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+)
+
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// Change is a single difference in a scanner result. Path is
+// made of field names separated by dots, "[]" denotes items of
+// a list, they're compared regardless of their position.
+type Change struct {
+	Path string      `json:"path"`
+	Type ChangeType  `json:"type"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// ScannerDiff holds changes of a single scanner. Type is Added or Removed
+// when the scanner only returned a result or an error in one of the scans.
+type ScannerDiff struct {
+	Scanner string     `json:"scanner"`
+	Type    ChangeType `json:"type"`
+	Changes []Change   `json:"changes"`
+}
+
+type Diff struct {
+	Scanners []ScannerDiff `json:"scanners"`
+}
+
+// Result is one side of a diff. Errors are compared
+// as if scanners returned an object with an error field.
+type Result struct {
+	// Number is the scanned number, when it's known
+	Number  *number.Number         `json:"number,omitempty"`
+	Results map[string]interface{} `json:"results"`
+	Errors  map[string]string      `json:"errors"`
+}
+
+// Compare returns differences between two scan results, per scanner.
+// Scans of different numbers can't be compared.
+func Compare(from, to Result) (*Diff, error) {
+	if from.Number != nil && to.Number != nil && from.Number.E164 != to.Number.E164 {
+		return nil, fmt.Errorf("scans of different numbers can't be compared: %s and %s", from.Number.E164, to.Number.E164)
+	}
+
+	a, err := normalize(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := normalize(to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Diff{Scanners: []ScannerDiff{}}
+	for _, name := range sortedKeys(a, b) {
+		va, inA := a[name]
+		vb, inB := b[name]
+		switch {
+		case !inA:
+			d.Scanners = append(d.Scanners, ScannerDiff{Scanner: name, Type: Added, Changes: []Change{{Type: Added, To: vb}}})
+		case !inB:
+			d.Scanners = append(d.Scanners, ScannerDiff{Scanner: name, Type: Removed, Changes: []Change{{Type: Removed, From: va}}})
+		default:
+			if changes := compareValues("", va, vb); len(changes) > 0 {
+				d.Scanners = append(d.Scanners, ScannerDiff{Scanner: name, Type: Changed, Changes: changes})
+			}
+		}
+	}
+	return d, nil
+}
+
+// Empty reports whether both scans are identical
+func (d *Diff) Empty() bool {
+	return len(d.Scanners) == 0
+}
+
+// normalize converts results to generic JSON values, so typed
+// results and results decoded from JSON compare equally
+func normalize(r Result) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for name, msg := range r.Errors {
+		values[name] = map[string]interface{}{"error": msg}
+	}
+	for name, res := range r.Results {
+		if res != nil {
+			values[name] = res
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	normalized := map[string]interface{}{}
+	return normalized, json.Unmarshal(data, &normalized)
+}
+
+func compareValues(path string, a, b interface{}) []Change {
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		var changes []Change
+		for _, k := range sortedKeys(va, vb) {
+			p := joinPath(path, k)
+			ia, inA := va[k]
+			ib, inB := vb[k]
+			switch {
+			case !inA:
+				changes = append(changes, Change{Path: p, Type: Added, To: ib})
+			case !inB:
+				changes = append(changes, Change{Path: p, Type: Removed, From: ia})
+			default:
+				changes = append(changes, compareValues(p, ia, ib)...)
+			}
+		}
+		return changes
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		var changes []Change
+		for _, item := range va {
+			if !containsValue(vb, item) {
+				changes = append(changes, Change{Path: path + "[]", Type: Removed, From: item})
+			}
+		}
+		for _, item := range vb {
+			if !containsValue(va, item) {
+				changes = append(changes, Change{Path: path + "[]", Type: Added, To: item})
+			}
+		}
+		return changes
+	}
+
+	return []Change{{Path: path, Type: Changed, From: a, To: b}}
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, item := range values {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReadResult reads scan results written as JSON by the scan command,
+// or a scan exported from history.
+func ReadResult(r io.Reader) (Result, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return Result{}, fmt.Errorf("invalid JSON results: %v", err)
+	}
+
+	// History exports are lists of scans
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		var scans []json.RawMessage
+		if err := json.Unmarshal(raw, &scans); err != nil {
+			return Result{}, fmt.Errorf("invalid JSON results: %v", err)
+		}
+		if len(scans) != 1 {
+			return Result{}, errors.New("JSON results must contain a single scan")
+		}
+		raw = scans[0]
+	}
+
+	var res Result
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return Result{}, fmt.Errorf("invalid JSON results: %v", err)
+	}
+	if res.Results == nil && res.Errors == nil {
+		return Result{}, errors.New("JSON results must have results or errors fields")
+	}
+	return res, nil
+}
+
+// WriteConsole renders the diff for humans
+func (d *Diff) WriteConsole(w io.Writer) {
+	if d.Empty() {
+		_, _ = fmt.Fprintln(w, "No changes")
+		return
+	}
+
+	for _, s := range d.Scanners {
+		switch s.Type {
+		case Added:
+			_, _ = fmt.Fprintf(w, color.WhiteString("%s (new scanner)\n"), s.Scanner)
+		case Removed:
+			_, _ = fmt.Fprintf(w, color.WhiteString("%s (missing scanner)\n"), s.Scanner)
+		default:
+			_, _ = fmt.Fprintf(w, color.WhiteString("%s\n"), s.Scanner)
+		}
+
+		for _, c := range s.Changes {
+			path := c.Path
+			if path == "" {
+				path = "result"
+			}
+			switch c.Type {
+			case Added:
+				_, _ = fmt.Fprintf(w, color.GreenString("  + %s: %s\n"), path, formatValue(c.To))
+			case Removed:
+				_, _ = fmt.Fprintf(w, color.RedString("  - %s: %s\n"), path, formatValue(c.From))
+			case Changed:
+				_, _ = fmt.Fprintf(w, color.YellowString("  ~ %s: %s -> %s\n"), path, formatValue(c.From), formatValue(c.To))
+			}
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	_, _ = fmt.Fprintf(w, "%d scanner(s) changed\n", len(d.Scanners))
+}
+
+func formatValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,195 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+func TestCompare(t *testing.T) {
+	testcases := []struct {
+		name     string
+		from     Result
+		to       Result
+		expected *Diff
+	}{
+		{
+			name: "test identical scans",
+			from: Result{Results: map[string]interface{}{
+				"ovh": remote.OVHScannerResponse{Found: true, NumberRange: "0336517xxxx"},
+			}},
+			to: Result{Results: map[string]interface{}{
+				"ovh": map[string]interface{}{"found": true, "number_range": "0336517xxxx"},
+			}},
+			expected: &Diff{Scanners: []ScannerDiff{}},
+		},
+		{
+			name: "test changed fields",
+			from: Result{Results: map[string]interface{}{
+				"ovh": remote.OVHScannerResponse{Found: true, NumberRange: "0336517xxxx", City: "Abbeville"},
+			}},
+			to: Result{Results: map[string]interface{}{
+				"ovh": remote.OVHScannerResponse{Found: true, NumberRange: "0336518xxxx", ZipCode: "80100"},
+			}},
+			expected: &Diff{Scanners: []ScannerDiff{
+				{Scanner: "ovh", Type: Changed, Changes: []Change{
+					{Path: "city", Type: Removed, From: "Abbeville"},
+					{Path: "number_range", Type: Changed, From: "0336517xxxx", To: "0336518xxxx"},
+					{Path: "zip_code", Type: Added, To: "80100"},
+				}},
+			}},
+		},
+		{
+			name: "test list items regardless of their position",
+			from: Result{Results: map[string]interface{}{
+				"googlecse": remote.GoogleCSEScannerResponse{ResultCount: 2, Items: []remote.ResultItem{
+					{Title: "a", URL: "https://a.com"},
+					{Title: "b", URL: "https://b.com"},
+				}},
+			}},
+			to: Result{Results: map[string]interface{}{
+				"googlecse": remote.GoogleCSEScannerResponse{ResultCount: 2, Items: []remote.ResultItem{
+					{Title: "c", URL: "https://c.com"},
+					{Title: "a", URL: "https://a.com"},
+				}},
+			}},
+			expected: &Diff{Scanners: []ScannerDiff{
+				{Scanner: "googlecse", Type: Changed, Changes: []Change{
+					{Path: "items[]", Type: Removed, From: map[string]interface{}{"title": "b", "url": "https://b.com"}},
+					{Path: "items[]", Type: Added, To: map[string]interface{}{"title": "c", "url": "https://c.com"}},
+				}},
+			}},
+		},
+		{
+			name: "test added and removed scanners",
+			from: Result{
+				Results: map[string]interface{}{"local": map[string]interface{}{"country": "US"}},
+				Errors:  map[string]string{"numverify": "dummy error"},
+			},
+			to: Result{
+				Results: map[string]interface{}{"numverify": map[string]interface{}{"valid": true}},
+			},
+			expected: &Diff{Scanners: []ScannerDiff{
+				{Scanner: "local", Type: Removed, Changes: []Change{
+					{Type: Removed, From: map[string]interface{}{"country": "US"}},
+				}},
+				{Scanner: "numverify", Type: Changed, Changes: []Change{
+					{Path: "error", Type: Removed, From: "dummy error"},
+					{Path: "valid", Type: Added, To: true},
+				}},
+			}},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Compare(tt.from, tt.to)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, len(tt.expected.Scanners) == 0, got.Empty())
+		})
+	}
+}
+
+func TestCompare_DifferentNumbers(t *testing.T) {
+	us, _ := number.NewNumber("+14152229670")
+	fr, _ := number.NewNumber("+33679368229")
+
+	_, err := Compare(Result{Number: us}, Result{Number: fr})
+	assert.EqualError(t, err, "scans of different numbers can't be compared: +14152229670 and +33679368229")
+
+	// Results of unknown numbers, e.g. scan outputs, can still be compared
+	_, err = Compare(Result{Number: us}, Result{})
+	assert.NoError(t, err)
+	_, err = Compare(Result{Number: us}, Result{Number: us})
+	assert.NoError(t, err)
+}
+
+func TestReadResult(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		expected Result
+		wantErr  string
+	}{
+		{
+			name:  "test scan output",
+			input: `{"results": {"local": {"country": "US"}}, "errors": {"numverify": "dummy error"}}`,
+			expected: Result{
+				Results: map[string]interface{}{"local": map[string]interface{}{"country": "US"}},
+				Errors:  map[string]string{"numverify": "dummy error"},
+			},
+		},
+		{
+			name:  "test history export",
+			input: `[{"id": "1", "number": {"E164": "+14152229670"}, "results": {"local": {"country": "US"}}, "errors": {}}]`,
+			expected: Result{
+				Number:  &number.Number{E164: "+14152229670"},
+				Results: map[string]interface{}{"local": map[string]interface{}{"country": "US"}},
+				Errors:  map[string]string{},
+			},
+		},
+		{
+			name:    "test history export with several scans",
+			input:   `[{"results": {}}, {"results": {}}]`,
+			wantErr: "JSON results must contain a single scan",
+		},
+		{
+			name:    "test unknown object",
+			input:   `{"foo": "bar"}`,
+			wantErr: "JSON results must have results or errors fields",
+		},
+		{
+			name:    "test invalid JSON",
+			input:   `{`,
+			wantErr: "invalid JSON results: unexpected EOF",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadResult(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestDiff_WriteConsole(t *testing.T) {
+	color.NoColor = true
+
+	d := &Diff{Scanners: []ScannerDiff{
+		{Scanner: "googlecse", Type: Changed, Changes: []Change{
+			{Path: "items[]", Type: Added, To: map[string]interface{}{"title": "c"}},
+			{Path: "result_count", Type: Changed, From: 1, To: 2},
+		}},
+		{Scanner: "local", Type: Removed, Changes: []Change{
+			{Type: Removed, From: map[string]interface{}{"country": "US"}},
+		}},
+	}}
+
+	buf := &bytes.Buffer{}
+	d.WriteConsole(buf)
+	assert.Equal(t, `googlecse
+  + items[]: {"title":"c"}
+  ~ result_count: 1 -> 2
+
+local (missing scanner)
+  - result: {"country":"US"}
+
+2 scanner(s) changed
+`, buf.String())
+
+	buf.Reset()
+	(&Diff{}).WriteConsole(buf)
+	assert.Equal(t, "No changes\n", buf.String())
+}
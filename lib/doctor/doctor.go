@@ -0,0 +1,186 @@
+// Package doctor diagnoses the setup of PhoneInfoga: configuration,
+// plugins, scanner options and reachability of suppliers. Checks
+// come with a fix, and never print values of options.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+type Status int
+
+const (
+	StatusOK Status = iota
+	// StatusWarning is for things that may be intended, such as an
+	// optional scanner option left to its default, they don't make
+	// the report fail
+	StatusWarning
+	StatusFailure
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	// Fix tells how to solve the issue, when there's one
+	Fix string
+}
+
+type section struct {
+	title   string
+	results []Result
+}
+
+// Report collects results of checks, grouped by section
+type Report struct {
+	sections []*section
+}
+
+// Section starts a new section, following results are added to it
+func (r *Report) Section(title string) {
+	r.sections = append(r.sections, &section{title: title})
+}
+
+func (r *Report) Add(res Result) {
+	if len(r.sections) == 0 {
+		r.Section("")
+	}
+	s := r.sections[len(r.sections)-1]
+	s.results = append(s.results, res)
+}
+
+// OK adds a successful check
+func (r *Report) OK(name, detail string) {
+	r.Add(Result{Name: name, Status: StatusOK, Detail: detail})
+}
+
+// Warn adds a check with a non-blocking issue
+func (r *Report) Warn(name, detail, fix string) {
+	r.Add(Result{Name: name, Status: StatusWarning, Detail: detail, Fix: fix})
+}
+
+// Fail adds a failed check
+func (r *Report) Fail(name, detail, fix string) {
+	r.Add(Result{Name: name, Status: StatusFailure, Detail: detail, Fix: fix})
+}
+
+// Failures returns the number of failed checks
+func (r *Report) Failures() int {
+	n := 0
+	for _, s := range r.sections {
+		for _, res := range s.results {
+			if res.Status == StatusFailure {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Write prints the report, fixes are printed below their check
+func (r *Report) Write(w io.Writer) {
+	for i, s := range r.sections {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		if s.title != "" {
+			_, _ = fmt.Fprintln(w, color.WhiteString(s.title))
+		}
+		for _, res := range s.results {
+			line := res.Name
+			if res.Detail != "" {
+				line += ": " + res.Detail
+			}
+			_, _ = fmt.Fprintf(w, "%s %s\n", statusSymbol(res.Status), line)
+			if res.Fix != "" {
+				_, _ = fmt.Fprintf(w, "  %s %s\n", color.CyanString("→"), res.Fix)
+			}
+		}
+	}
+}
+
+func statusSymbol(s Status) string {
+	switch s {
+	case StatusWarning:
+		return color.YellowString("!")
+	case StatusFailure:
+		return color.RedString("✗")
+	}
+	return color.GreenString("✓")
+}
+
+// CheckScanner checks options of the scanner are defined, in the given
+// options or the environment. Enabled scanners missing required options
+// fail, unset optional options are warnings. Only names of options are
+// reported.
+func CheckScanner(s remote.Scanner, opts remote.ScannerOptions) Result {
+	res := Result{Name: s.Name(), Status: StatusOK}
+
+	described, ok := s.(remote.OptionsScanner)
+	if !ok {
+		res.Detail = "no option required"
+		return res
+	}
+
+	var set, missing, unset []string
+	for _, o := range described.Options() {
+		switch {
+		case opts.GetStringEnv(o.Name) != "":
+			set = append(set, o.Name)
+		case o.Required:
+			missing = append(missing, o.Name)
+		default:
+			unset = append(unset, o.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		res.Status = StatusFailure
+		res.Detail = "missing " + strings.Join(missing, ", ")
+		res.Fix = fmt.Sprintf(
+			"define %s in a .env file or in scanners.%s.options of the config file, or disable the scanner with scanners.%s.enabled: false",
+			strings.Join(missing, " and "), s.Name(), s.Name(),
+		)
+		return res
+	}
+	if len(unset) > 0 {
+		res.Status = StatusWarning
+		res.Detail = "optional " + strings.Join(unset, ", ") + " not set, defaults are used"
+		res.Fix = fmt.Sprintf("define %s in a .env file or in scanners.%s.options of the config file to change defaults", strings.Join(unset, " and "), s.Name())
+		return res
+	}
+	if len(set) > 0 {
+		res.Detail = strings.Join(set, ", ") + " set"
+	} else {
+		res.Detail = "no option required"
+	}
+	return res
+}
+
+// CheckEndpoint checks the given URL can be reached within the timeout.
+// Any HTTP response counts, as base URLs of APIs often respond with an error.
+func CheckEndpoint(ctx context.Context, client *http.Client, url string, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = res.Body.Close()
+	return time.Since(start), nil
+}
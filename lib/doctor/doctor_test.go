@@ -0,0 +1,154 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+)
+
+type optionsScanner struct {
+	*mocks.Scanner
+	options []remote.ScannerOption
+}
+
+func (s *optionsScanner) Options() []remote.ScannerOption {
+	return s.options
+}
+
+func TestCheckScanner(t *testing.T) {
+	testcases := []struct {
+		name     string
+		options  []remote.ScannerOption
+		values   remote.ScannerOptions
+		env      map[string]string
+		expected Result
+	}{
+		{
+			name:     "test scanner without options",
+			expected: Result{Name: "fake", Status: StatusOK, Detail: "no option required"},
+		},
+		{
+			name: "test required options set",
+			options: []remote.ScannerOption{
+				{Name: "FAKE_API_KEY", Required: true, Secret: true},
+				{Name: "FAKE_ACCOUNT", Required: true},
+			},
+			values:   remote.ScannerOptions{"FAKE_ACCOUNT": "account"},
+			env:      map[string]string{"FAKE_API_KEY": "secret-value"},
+			expected: Result{Name: "fake", Status: StatusOK, Detail: "FAKE_API_KEY, FAKE_ACCOUNT set"},
+		},
+		{
+			name: "test missing required options",
+			options: []remote.ScannerOption{
+				{Name: "FAKE_API_KEY", Required: true, Secret: true},
+				{Name: "FAKE_ACCOUNT", Required: true},
+				{Name: "FAKE_MAX_RESULTS"},
+			},
+			values: remote.ScannerOptions{"FAKE_ACCOUNT": "account"},
+			expected: Result{
+				Name:   "fake",
+				Status: StatusFailure,
+				Detail: "missing FAKE_API_KEY",
+				Fix:    "define FAKE_API_KEY in a .env file or in scanners.fake.options of the config file, or disable the scanner with scanners.fake.enabled: false",
+			},
+		},
+		{
+			name:    "test optional options unset",
+			options: []remote.ScannerOption{{Name: "FAKE_MAX_RESULTS"}},
+			expected: Result{
+				Name:   "fake",
+				Status: StatusWarning,
+				Detail: "optional FAKE_MAX_RESULTS not set, defaults are used",
+				Fix:    "define FAKE_MAX_RESULTS in a .env file or in scanners.fake.options of the config file to change defaults",
+			},
+		},
+		{
+			name:     "test optional options set",
+			options:  []remote.ScannerOption{{Name: "FAKE_MAX_RESULTS"}},
+			values:   remote.ScannerOptions{"FAKE_MAX_RESULTS": "20"},
+			expected: Result{Name: "fake", Status: StatusOK, Detail: "FAKE_MAX_RESULTS set"},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fake := &mocks.Scanner{}
+			fake.On("Name").Return("fake")
+
+			var s remote.Scanner = fake
+			if tt.options != nil {
+				s = &optionsScanner{Scanner: fake, options: tt.options}
+			}
+			assert.Equal(t, tt.expected, CheckScanner(s, tt.values))
+		})
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	testcases := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "test API responding with an error", url: srv.URL},
+		{name: "test timeout", url: slow.URL, wantErr: true},
+		{name: "test unreachable server", url: closed.URL, wantErr: true},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CheckEndpoint(context.Background(), http.DefaultClient, tt.url, 100*time.Millisecond)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	report := &Report{}
+	report.Section("Configuration")
+	report.OK("config file", "phoneinfoga.yaml")
+	report.Section("Scanners")
+	report.Warn("googlecse", "optional GOOGLECSE_MAX_RESULTS not set", "define GOOGLECSE_MAX_RESULTS")
+	report.Fail("plugin", "checksum mismatch", "install it again")
+	assert.Equal(t, 1, report.Failures())
+
+	var buf bytes.Buffer
+	report.Write(&buf)
+	assert.Equal(t, `Configuration
+✓ config file: phoneinfoga.yaml
+
+Scanners
+! googlecse: optional GOOGLECSE_MAX_RESULTS not set
+  → define GOOGLECSE_MAX_RESULTS
+✗ plugin: checksum mismatch
+  → install it again
+`, buf.String())
+}
@@ -0,0 +1,232 @@
+// Package dorks builds search engine requests ("dorks") once, and renders
+// them with the operator syntax of each supported search engine.
+package dorks
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type operator int
+
+const (
+	opSite operator = iota
+	opInText
+	opInTitle
+	opInURL
+	opExt
+	opOr
+	opGroup
+)
+
+type term struct {
+	op    operator
+	value string
+	group *Query
+}
+
+// Query is a search request independent of the search engine
+type Query struct {
+	terms []term
+}
+
+// New creates an empty query
+func New() *Query {
+	return &Query{}
+}
+
+func (q *Query) add(op operator, value string) *Query {
+	q.terms = append(q.terms, term{op: op, value: value})
+	return q
+}
+
+// Site restricts results to the given domain
+func (q *Query) Site(site string) *Query {
+	return q.add(opSite, site)
+}
+
+// InText searches the given text in the page content
+func (q *Query) InText(text string) *Query {
+	return q.add(opInText, text)
+}
+
+// InTitle searches the given text in the page title
+func (q *Query) InTitle(text string) *Query {
+	return q.add(opInTitle, text)
+}
+
+// InURL searches the given text in the page URL
+func (q *Query) InURL(text string) *Query {
+	return q.add(opInURL, text)
+}
+
+// Ext restricts results to documents with the given file extension
+func (q *Query) Ext(ext string) *Query {
+	return q.add(opExt, ext)
+}
+
+// Or puts an OR operator between the previous and the next term
+func (q *Query) Or() *Query {
+	return q.add(opOr, "")
+}
+
+// Group isolates terms of the given query between parentheses
+func (q *Query) Group(g *Query) *Query {
+	q.terms = append(q.terms, term{op: opGroup, group: g})
+	return q
+}
+
+// syntax is how an engine writes an operator. An empty
+// prefix means the engine doesn't support the operator,
+// the value is then searched as plain text.
+type syntax struct {
+	prefix string
+	quote  bool
+}
+
+// Engine is a search engine, with its operator syntax
+type Engine struct {
+	Name      string
+	searchURL string
+	param     string
+	or        string
+	operators map[operator]syntax
+}
+
+// String writes the query with the operators of the engine
+func (e *Engine) String(q *Query) string {
+	tags := make([]string, 0, len(q.terms))
+	for _, t := range q.terms {
+		switch t.op {
+		case opOr:
+			tags = append(tags, e.or)
+		case opGroup:
+			tags = append(tags, "("+e.String(t.group)+")")
+		default:
+			s := e.operators[t.op]
+			if s.quote || s.prefix == "" {
+				tags = append(tags, s.prefix+"\""+t.value+"\"")
+			} else {
+				tags = append(tags, s.prefix+t.value)
+			}
+		}
+	}
+	return strings.Join(tags, " ")
+}
+
+// URL returns the search URL of the query on the engine
+func (e *Engine) URL(q *Query) string {
+	u, _ := url.Parse(e.searchURL)
+	params := url.Values{}
+	params.Add(e.param, e.String(q))
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+var (
+	Google = &Engine{
+		Name:      "google",
+		searchURL: "https://www.google.com/search",
+		param:     "q",
+		or:        "|",
+		operators: map[operator]syntax{
+			opSite:    {prefix: "site:"},
+			opInText:  {prefix: "intext:", quote: true},
+			opInTitle: {prefix: "intitle:", quote: true},
+			opInURL:   {prefix: "inurl:", quote: true},
+			opExt:     {prefix: "ext:"},
+		},
+	}
+	Bing = &Engine{
+		Name:      "bing",
+		searchURL: "https://www.bing.com/search",
+		param:     "q",
+		or:        "OR",
+		operators: map[operator]syntax{
+			opSite:    {prefix: "site:"},
+			opInText:  {prefix: "inbody:", quote: true},
+			opInTitle: {prefix: "intitle:", quote: true},
+			opInURL:   {prefix: "instreamset:url:", quote: true},
+			opExt:     {prefix: "ext:"},
+		},
+	}
+	DuckDuckGo = &Engine{
+		Name:      "duckduckgo",
+		searchURL: "https://duckduckgo.com/",
+		param:     "q",
+		or:        "OR",
+		operators: map[operator]syntax{
+			opSite:    {prefix: "site:"},
+			opInTitle: {prefix: "intitle:", quote: true},
+			opInURL:   {prefix: "inurl:", quote: true},
+			opExt:     {prefix: "filetype:"},
+		},
+	}
+	Yandex = &Engine{
+		Name:      "yandex",
+		searchURL: "https://yandex.com/search/",
+		param:     "text",
+		or:        "|",
+		operators: map[operator]syntax{
+			opSite:    {prefix: "site:"},
+			opInTitle: {prefix: "title:", quote: true},
+			opInURL:   {prefix: "inurl:", quote: true},
+			opExt:     {prefix: "mime:"},
+		},
+	}
+	Baidu = &Engine{
+		Name:      "baidu",
+		searchURL: "https://www.baidu.com/s",
+		param:     "wd",
+		or:        "|",
+		operators: map[operator]syntax{
+			opSite:    {prefix: "site:"},
+			opInTitle: {prefix: "intitle:", quote: true},
+			opInURL:   {prefix: "inurl:", quote: true},
+			opExt:     {prefix: "filetype:"},
+		},
+	}
+)
+
+var engines = map[string]*Engine{
+	Google.Name:     Google,
+	Bing.Name:       Bing,
+	DuckDuckGo.Name: DuckDuckGo,
+	Yandex.Name:     Yandex,
+	Baidu.Name:      Baidu,
+}
+
+// EngineNames returns names of supported engines, sorted
+func EngineNames() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseEngines parses a comma separated list of engine names,
+// such as "google,bing". Engines are returned in the given order.
+func ParseEngines(s string) ([]*Engine, error) {
+	var res []*Engine
+	seen := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		e, ok := engines[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown search engine %q, supported engines are %s", name, strings.Join(EngineNames(), ", "))
+		}
+		seen[name] = true
+		res = append(res, e)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no search engine given, supported engines are %s", strings.Join(EngineNames(), ", "))
+	}
+	return res, nil
+}
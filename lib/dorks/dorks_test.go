@@ -0,0 +1,119 @@
+package dorks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_String(t *testing.T) {
+	query := New().
+		Site("example.com").
+		InText("+15556661212").
+		Or().
+		InTitle("who called").
+		Or().
+		InURL("5556661212")
+	documents := New().
+		Group(New().Ext("pdf").Or().Ext("txt")).
+		InText("+15556661212")
+
+	testcases := []struct {
+		engine    *Engine
+		expected  string
+		documents string
+	}{
+		{
+			engine:    Google,
+			expected:  `site:example.com intext:"+15556661212" | intitle:"who called" | inurl:"5556661212"`,
+			documents: `(ext:pdf | ext:txt) intext:"+15556661212"`,
+		},
+		{
+			engine:    Bing,
+			expected:  `site:example.com inbody:"+15556661212" OR intitle:"who called" OR instreamset:url:"5556661212"`,
+			documents: `(ext:pdf OR ext:txt) inbody:"+15556661212"`,
+		},
+		{
+			engine:    DuckDuckGo,
+			expected:  `site:example.com "+15556661212" OR intitle:"who called" OR inurl:"5556661212"`,
+			documents: `(filetype:pdf OR filetype:txt) "+15556661212"`,
+		},
+		{
+			engine:    Yandex,
+			expected:  `site:example.com "+15556661212" | title:"who called" | inurl:"5556661212"`,
+			documents: `(mime:pdf | mime:txt) "+15556661212"`,
+		},
+		{
+			engine:    Baidu,
+			expected:  `site:example.com "+15556661212" | intitle:"who called" | inurl:"5556661212"`,
+			documents: `(filetype:pdf | filetype:txt) "+15556661212"`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.engine.Name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.engine.String(query))
+			assert.Equal(t, tt.documents, tt.engine.String(documents))
+		})
+	}
+}
+
+func TestEngine_URL(t *testing.T) {
+	query := New().Site("example.com").InText("+15556661212")
+
+	testcases := []struct {
+		engine   *Engine
+		expected string
+	}{
+		{engine: Google, expected: "https://www.google.com/search?q=site%3Aexample.com+intext%3A%22%2B15556661212%22"},
+		{engine: Bing, expected: "https://www.bing.com/search?q=site%3Aexample.com+inbody%3A%22%2B15556661212%22"},
+		{engine: DuckDuckGo, expected: "https://duckduckgo.com/?q=site%3Aexample.com+%22%2B15556661212%22"},
+		{engine: Yandex, expected: "https://yandex.com/search/?text=site%3Aexample.com+%22%2B15556661212%22"},
+		{engine: Baidu, expected: "https://www.baidu.com/s?wd=site%3Aexample.com+%22%2B15556661212%22"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.engine.Name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.engine.URL(query))
+		})
+	}
+}
+
+func TestParseEngines(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		expected []*Engine
+		wantErr  error
+	}{
+		{
+			name:     "test single engine",
+			input:    "google",
+			expected: []*Engine{Google},
+		},
+		{
+			name:     "test several engines keep their order",
+			input:    " Yandex, google,bing,google, ",
+			expected: []*Engine{Yandex, Google, Bing},
+		},
+		{
+			name:    "test unknown engine",
+			input:   "google,altavista",
+			wantErr: errors.New(`unknown search engine "altavista", supported engines are baidu, bing, duckduckgo, google, yandex`),
+		},
+		{
+			name:    "test no engine",
+			input:   " , ",
+			wantErr: errors.New("no search engine given, supported engines are baidu, bing, duckduckgo, google, yandex"),
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEngines(tt.input)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
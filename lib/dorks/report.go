@@ -0,0 +1,121 @@
+package dorks
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Link is a dork to open in a browser
+type Link struct {
+	Engine string
+	Dork   string
+	URL    string
+}
+
+// Group holds links of a category
+type Group struct {
+	Category string
+	Title    string
+	Links    []Link
+}
+
+var categoryTitles = map[string]string{
+	CategorySocialMedia:         "Social media",
+	CategoryDisposableProviders: "Disposable providers",
+	CategoryReputation:          "Reputation",
+	CategoryIndividuals:         "Individuals",
+	CategoryGeneral:             "General",
+}
+
+// CategoryTitle returns the human readable name of a category
+func CategoryTitle(category string) string {
+	if t, ok := categoryTitles[category]; ok {
+		return t
+	}
+	return category
+}
+
+// Format of dork reports
+type Format string
+
+const (
+	// FormatPlain writes URLs only, one per line
+	FormatPlain    Format = "plain"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat returns the format of the given name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatPlain, FormatMarkdown, FormatHTML:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q, formats are %s, %s and %s", s, FormatPlain, FormatMarkdown, FormatHTML)
+}
+
+// Write writes links of groups in the given format, title
+// is used as heading of Markdown and HTML reports
+func Write(w io.Writer, f Format, title string, groups []Group) error {
+	switch f {
+	case FormatMarkdown:
+		return writeMarkdown(w, title, groups)
+	case FormatHTML:
+		return htmlReport.Execute(w, struct {
+			Title  string
+			Groups []Group
+		}{title, groups})
+	}
+	for _, g := range groups {
+		for _, l := range g.Links {
+			if _, err := fmt.Fprintln(w, l.URL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes a checklist, to tick links once they're opened.
+// Dorks are written as code, so their quotes and pipes aren't interpreted.
+func writeMarkdown(w io.Writer, title string, groups []Group) error {
+	var b strings.Builder
+	b.WriteString("# " + title + "\n")
+	for _, g := range groups {
+		b.WriteString("\n## " + g.Title + "\n\n")
+		for _, l := range g.Links {
+			fmt.Fprintf(&b, "- [ ] %s: [`%s`](%s)\n", l.Engine, strings.ReplaceAll(l.Dork, "`", "'"), l.URL)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlReport = template.Must(template.New("dorks").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; }
+li { margin: .3em 0; }
+a { word-break: break-all; }
+a:visited { color: #777; }
+.engine { display: inline-block; min-width: 6em; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{- range .Groups }}
+<h2>{{ .Title }}</h2>
+<ul>
+{{- range .Links }}
+<li><span class="engine">{{ .Engine }}</span> <a href="{{ .URL }}" target="_blank" rel="noopener noreferrer">{{ .Dork }}</a></li>
+{{- end }}
+</ul>
+{{- end }}
+</body>
+</html>
+`))
@@ -0,0 +1,78 @@
+package dorks
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	groups := []Group{
+		{
+			Category: CategorySocialMedia,
+			Title:    CategoryTitle(CategorySocialMedia),
+			Links: []Link{
+				{Engine: "google", Dork: `site:facebook.com intext:"+15556661212"`, URL: "https://www.google.com/search?q=site%3Afacebook.com+intext%3A%22%2B15556661212%22"},
+				{Engine: "bing", Dork: `site:facebook.com inbody:"+15556661212"`, URL: "https://www.bing.com/search?q=site%3Afacebook.com+inbody%3A%22%2B15556661212%22"},
+			},
+		},
+		{
+			Category: CategoryGeneral,
+			Title:    CategoryTitle(CategoryGeneral),
+			Links: []Link{
+				{Engine: "google", Dork: `intext:"<b>" | intext:"+15556661212"`, URL: "https://www.google.com/search?q=intext%3A%22%3Cb%3E%22"},
+			},
+		},
+	}
+
+	testcases := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: FormatPlain,
+			expected: `https://www.google.com/search?q=site%3Afacebook.com+intext%3A%22%2B15556661212%22
+https://www.bing.com/search?q=site%3Afacebook.com+inbody%3A%22%2B15556661212%22
+https://www.google.com/search?q=intext%3A%22%3Cb%3E%22
+`,
+		},
+		{
+			format: FormatMarkdown,
+			expected: "# Dorks for +15556661212\n" +
+				"\n## Social media\n\n" +
+				"- [ ] google: [`site:facebook.com intext:\"+15556661212\"`](https://www.google.com/search?q=site%3Afacebook.com+intext%3A%22%2B15556661212%22)\n" +
+				"- [ ] bing: [`site:facebook.com inbody:\"+15556661212\"`](https://www.bing.com/search?q=site%3Afacebook.com+inbody%3A%22%2B15556661212%22)\n" +
+				"\n## General\n\n" +
+				"- [ ] google: [`intext:\"<b>\" | intext:\"+15556661212\"`](https://www.google.com/search?q=intext%3A%22%3Cb%3E%22)\n",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, Write(&buf, tt.format, "Dorks for +15556661212", groups))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("html", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, Write(&buf, FormatHTML, "Dorks for +15556661212", groups))
+		assert.Contains(t, buf.String(), "<title>Dorks for &#43;15556661212</title>")
+		assert.Contains(t, buf.String(), "<h2>Social media</h2>")
+		assert.Contains(t, buf.String(), `<li><span class="engine">bing</span> <a href="https://www.bing.com/search?q=site%3Afacebook.com&#43;inbody%3A%22%2B15556661212%22" target="_blank" rel="noopener noreferrer">site:facebook.com inbody:&#34;&#43;15556661212&#34;</a></li>`)
+		// Dorks from custom files are escaped
+		assert.Contains(t, buf.String(), "intext:&#34;&lt;b&gt;&#34;")
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("Markdown")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatMarkdown, f)
+
+	_, err = ParseFormat("pdf")
+	assert.Equal(t, errors.New(`unknown format "pdf", formats are plain, markdown and html`), err)
+}
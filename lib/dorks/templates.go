@@ -0,0 +1,337 @@
+package dorks
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"gopkg.in/yaml.v3"
+)
+
+// PathsEnv is the environment variable giving dork files
+// and directories to load, separated by commas
+const PathsEnv = "PHONEINFOGA_DORKS"
+
+// Categories dorks are grouped by
+const (
+	CategorySocialMedia         = "social_media"
+	CategoryDisposableProviders = "disposable_providers"
+	CategoryReputation          = "reputation"
+	CategoryIndividuals         = "individuals"
+	CategoryGeneral             = "general"
+)
+
+// Categories returns dork categories, in the order results are shown
+func Categories() []string {
+	return []string{
+		CategorySocialMedia,
+		CategoryDisposableProviders,
+		CategoryReputation,
+		CategoryIndividuals,
+		CategoryGeneral,
+	}
+}
+
+// Operator types of dork definitions
+const (
+	OperatorInText  = "intext"
+	OperatorInTitle = "intitle"
+	OperatorInURL   = "inurl"
+	OperatorExt     = "ext"
+)
+
+// variants are formats of the number operators can search for
+var variants = map[string]func(n number.Number) string{
+	"international": func(n number.Number) string { return n.International },
+	"e164":          func(n number.Number) string { return n.E164 },
+	"raw_local":     func(n number.Number) string { return n.RawLocal },
+	"local":         func(n number.Number) string { return n.Local },
+}
+
+//go:embed defaults/*.yaml
+var defaultFiles embed.FS
+
+// Operator searches the number variants and values it's given,
+// they're joined with an OR operator
+type Operator struct {
+	Type string `yaml:"type" json:"type"`
+	// Numbers are the number variants to search: international,
+	// e164, raw_local or local
+	Numbers []string `yaml:"numbers,omitempty" json:"numbers,omitempty"`
+	Values  []string `yaml:"values,omitempty" json:"values,omitempty"`
+}
+
+// Definition describes a single dork
+type Definition struct {
+	Category  string     `yaml:"category,omitempty" json:"category,omitempty"`
+	Site      string     `yaml:"site,omitempty" json:"site,omitempty"`
+	Operators []Operator `yaml:"operators" json:"operators"`
+	// File is the name of the file the dork was loaded from
+	File string `yaml:"-" json:"-"`
+}
+
+// File is the format of dork files. Dorks without
+// a category belong to the category of the file.
+type File struct {
+	Category string       `yaml:"category,omitempty" json:"category,omitempty"`
+	Dorks    []Definition `yaml:"dorks" json:"dorks"`
+}
+
+// Query builds the dork for the given number. Values of an operator
+// are grouped between parentheses when other operators follow.
+func (d Definition) Query(n number.Number) *Query {
+	q := New()
+	if d.Site != "" {
+		q.Site(d.Site)
+	}
+	for i, op := range d.Operators {
+		terms := New()
+		for _, v := range op.values(n) {
+			if len(terms.terms) > 0 {
+				terms.Or()
+			}
+			terms.add(operatorTypes[op.Type], v)
+		}
+		if len(op.values(n)) > 1 && i < len(d.Operators)-1 {
+			q.Group(terms)
+			continue
+		}
+		q.terms = append(q.terms, terms.terms...)
+	}
+	return q
+}
+
+// Template writes the dork for the engine, with names of
+// number variants between braces instead of the number
+func (d Definition) Template(e *Engine) string {
+	placeholders := number.Number{
+		International: "{international}",
+		E164:          "{e164}",
+		RawLocal:      "{raw_local}",
+		Local:         "{local}",
+	}
+	return e.String(d.Query(placeholders))
+}
+
+var operatorTypes = map[string]operator{
+	OperatorInText:  opInText,
+	OperatorInTitle: opInTitle,
+	OperatorInURL:   opInURL,
+	OperatorExt:     opExt,
+}
+
+func (o Operator) values(n number.Number) []string {
+	var res []string
+	for _, v := range o.Numbers {
+		res = append(res, variants[v](n))
+	}
+	return append(res, o.Values...)
+}
+
+func (d Definition) validate() error {
+	if !IsCategory(d.Category) {
+		if d.Category == "" {
+			return errors.New("category is missing")
+		}
+		return fmt.Errorf("unknown category %q, categories are %s", d.Category, strings.Join(Categories(), ", "))
+	}
+	if len(d.Operators) == 0 {
+		return errors.New("at least one operator is required")
+	}
+	for _, op := range d.Operators {
+		if _, ok := operatorTypes[op.Type]; !ok {
+			return fmt.Errorf("unknown operator %q, operators are %s, %s, %s and %s", op.Type, OperatorInText, OperatorInTitle, OperatorInURL, OperatorExt)
+		}
+		if len(op.Numbers) == 0 && len(op.Values) == 0 {
+			return fmt.Errorf("operator %s has no number variant nor value", op.Type)
+		}
+		for _, v := range op.Numbers {
+			if _, ok := variants[v]; !ok {
+				return fmt.Errorf("unknown number variant %q, variants are international, e164, raw_local and local", v)
+			}
+		}
+	}
+	return nil
+}
+
+// IsCategory returns whether dorks can be grouped by the given category
+func IsCategory(c string) bool {
+	for _, category := range Categories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Set is a list of dorks loaded from files
+type Set struct {
+	files map[string][]Definition
+	// order of files, a file replacing another one takes its place
+	order []string
+	// loaded are paths of files given by users, by key
+	loaded map[string]string
+}
+
+func (s *Set) add(name string, defs []Definition) {
+	key := fileKey(name)
+	if _, ok := s.files[key]; !ok {
+		s.order = append(s.order, key)
+	}
+	s.files[key] = defs
+}
+
+// addFile adds dorks of a file given by the user. It may replace a default
+// file of the same name, but not another file given by the user, whether
+// it's in another directory or has another extension.
+func (s *Set) addFile(name string, defs []Definition) error {
+	key := fileKey(name)
+	if other, ok := s.loaded[key]; ok && other != filepath.Clean(name) {
+		return fmt.Errorf("dork files %s and %s have the same name, rename one of them", other, name)
+	}
+	s.loaded[key] = filepath.Clean(name)
+	s.add(name, defs)
+	return nil
+}
+
+// Dorks returns dorks of the given category, or all dorks if category
+// is empty. Dorks of a file follow each other, in the order files are loaded.
+func (s *Set) Dorks(category string) []Definition {
+	var res []Definition
+	for _, name := range s.order {
+		for _, d := range s.files[name] {
+			if category == "" || d.Category == category {
+				res = append(res, d)
+			}
+		}
+	}
+	return res
+}
+
+// Queries builds dorks of the given category for the number
+func (s *Set) Queries(category string, n number.Number) []*Query {
+	var res []*Query
+	for _, d := range s.Dorks(category) {
+		res = append(res, d.Query(n))
+	}
+	return res
+}
+
+// Default returns dorks shipped with PhoneInfoga
+func Default() *Set {
+	s := &Set{files: map[string][]Definition{}, loaded: map[string]string{}}
+	entries, _ := defaultFiles.ReadDir("defaults")
+	for _, e := range entries {
+		data, _ := defaultFiles.ReadFile(path.Join("defaults", e.Name()))
+		// Embedded files are checked by tests
+		defs, err := parse(e.Name(), data)
+		if err != nil {
+			panic(err)
+		}
+		s.add(e.Name(), defs)
+	}
+	return s
+}
+
+// Load returns default dorks, along with the ones of given files and
+// directories. A file named like a default one, e.g. social_media.yaml,
+// replaces it. Files can be written in YAML or JSON, two of them can't
+// have the same name without their extension.
+func Load(paths ...string) (*Set, error) {
+	s := Default()
+	for _, p := range paths {
+		files, err := dorkFiles(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read dork file: %w", err)
+			}
+			defs, err := parse(f, data)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.addFile(f, defs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// SplitPaths splits a comma separated list of paths, as given in PathsEnv
+func SplitPaths(s string) []string {
+	var res []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func dorkFiles(p string) ([]string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read dork files: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read dork files: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && isDorkFile(e) {
+			files = append(files, filepath.Join(p, e.Name()))
+		}
+	}
+	return files, nil
+}
+
+func isDorkFile(e fs.DirEntry) bool {
+	switch filepath.Ext(e.Name()) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// fileKey identifies files, so a file replaces the default one
+// of the same name whatever its extension and directory
+func fileKey(name string) string {
+	base := filepath.Base(name)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// parse reads a dork file, JSON files are valid YAML
+func parse(name string, data []byte) ([]Definition, error) {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("dork file %s is not valid: %v", name, err)
+	}
+
+	defs := make([]Definition, 0, len(f.Dorks))
+	for i, d := range f.Dorks {
+		if d.Category == "" {
+			d.Category = f.Category
+		}
+		if err := d.validate(); err != nil {
+			return nil, fmt.Errorf("dork file %s: dork %d: %v", name, i+1, err)
+		}
+		d.File = filepath.Base(name)
+		defs = append(defs, d)
+	}
+	return defs, nil
+}
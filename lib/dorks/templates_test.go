@@ -0,0 +1,213 @@
+package dorks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+)
+
+func TestDefault(t *testing.T) {
+	s := Default()
+
+	expected := map[string]int{
+		CategorySocialMedia:         5,
+		CategoryDisposableProviders: 21,
+		CategoryReputation:          10,
+		CategoryIndividuals:         7,
+		CategoryGeneral:             2,
+	}
+	for category, count := range expected {
+		assert.Len(t, s.Dorks(category), count, category)
+	}
+	assert.Len(t, s.Dorks(""), 45)
+}
+
+func TestDefinition_Query(t *testing.T) {
+	n, err := number.NewNumber("15556661212")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		dork     Definition
+		expected string
+	}{
+		{
+			name: "test site with number variants",
+			dork: Definition{
+				Site:      "facebook.com",
+				Operators: []Operator{{Type: OperatorInText, Numbers: []string{"international", "e164"}}},
+			},
+			expected: `site:facebook.com intext:"15556661212" | intext:"+15556661212"`,
+		},
+		{
+			name: "test number variants and values",
+			dork: Definition{
+				Operators: []Operator{
+					{Type: OperatorInTitle, Values: []string{"who called"}},
+					{Type: OperatorInURL, Numbers: []string{"raw_local"}, Values: []string{"spam"}},
+				},
+			},
+			expected: `intitle:"who called" inurl:"5556661212" | inurl:"spam"`,
+		},
+		{
+			name: "test values grouped when operators follow",
+			dork: Definition{
+				Operators: []Operator{
+					{Type: OperatorExt, Values: []string{"pdf", "txt"}},
+					{Type: OperatorInText, Numbers: []string{"local"}},
+				},
+			},
+			expected: `(ext:pdf | ext:txt) intext:"(555) 666-1212"`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Google.String(tt.dork.Query(*n)))
+		})
+	}
+}
+
+func TestDefinition_Template(t *testing.T) {
+	d := Definition{
+		Site:      "whosenumber.info",
+		Operators: []Operator{{Type: OperatorInText, Numbers: []string{"e164", "local"}}, {Type: OperatorInTitle, Values: []string{"who called"}}},
+	}
+	assert.Equal(t, `site:whosenumber.info (intext:"{e164}" | intext:"{local}") intitle:"who called"`, d.Template(Google))
+	assert.Equal(t, `site:whosenumber.info ("{e164}" OR "{local}") intitle:"who called"`, d.Template(DuckDuckGo))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "social_media.json"), `{
+  "category": "social_media",
+  "dorks": [{"site": "mastodon.social", "operators": [{"type": "intext", "numbers": ["e164"]}]}]
+}`)
+	writeFile(t, filepath.Join(dir, "more.yml"), `
+dorks:
+  - category: disposable_providers
+    site: new-sms-provider.com
+    operators:
+      - type: intext
+        numbers: [international]
+`)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a dork file")
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	social := s.Dorks(CategorySocialMedia)
+	assert.Len(t, social, 1)
+	assert.Equal(t, "mastodon.social", social[0].Site)
+	assert.Equal(t, "social_media.json", social[0].File)
+
+	// Added dorks follow the default ones
+	disposable := s.Dorks(CategoryDisposableProviders)
+	assert.Len(t, disposable, 22)
+	assert.Equal(t, "new-sms-provider.com", disposable[21].Site)
+
+	assert.Len(t, s.Dorks(CategoryGeneral), 2)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	testcases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "test invalid syntax",
+			content: "dorks: [",
+			wantErr: "dork file %s is not valid: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:    "test missing category",
+			content: "dorks: [{site: example.com, operators: [{type: intext, numbers: [e164]}]}]",
+			wantErr: "dork file %s: dork 1: category is missing",
+		},
+		{
+			name:    "test unknown category",
+			content: "category: friends\ndorks: [{site: example.com, operators: [{type: intext, numbers: [e164]}]}]",
+			wantErr: `dork file %s: dork 1: unknown category "friends", categories are social_media, disposable_providers, reputation, individuals, general`,
+		},
+		{
+			name:    "test no operator",
+			content: "category: general\ndorks: [{site: example.com}]",
+			wantErr: "dork file %s: dork 1: at least one operator is required",
+		},
+		{
+			name:    "test unknown operator",
+			content: "category: general\ndorks: [{operators: [{type: allintext, numbers: [e164]}]}]",
+			wantErr: `dork file %s: dork 1: unknown operator "allintext", operators are intext, intitle, inurl and ext`,
+		},
+		{
+			name:    "test operator without value",
+			content: "category: general\ndorks: [{operators: [{type: intext}]}]",
+			wantErr: "dork file %s: dork 1: operator intext has no number variant nor value",
+		},
+		{
+			name:    "test unknown number variant",
+			content: "category: general\ndorks: [{operators: [{type: intext, numbers: [national]}]}]",
+			wantErr: `dork file %s: dork 1: unknown number variant "national", variants are international, e164, raw_local and local`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "dorks.yaml")
+			writeFile(t, file, tt.content)
+
+			s, err := Load(file)
+			assert.Nil(t, s)
+			assert.EqualError(t, err, fmt.Sprintf(tt.wantErr, file))
+		})
+	}
+
+	t.Run("test files with the same name", func(t *testing.T) {
+		content := "category: general\ndorks: [{operators: [{type: intext, numbers: [e164]}]}]"
+		dir, other := t.TempDir(), t.TempDir()
+		writeFile(t, filepath.Join(dir, "reputation.json"), `{"category": "reputation", "dorks": [{"operators": [{"type": "intext", "numbers": ["e164"]}]}]}`)
+		writeFile(t, filepath.Join(dir, "reputation.yaml"), content)
+		writeFile(t, filepath.Join(other, "custom.yaml"), content)
+		writeFile(t, filepath.Join(other, "reputation.yaml"), content)
+
+		_, err := Load(dir)
+		assert.EqualError(t, err, fmt.Sprintf("dork files %s and %s have the same name, rename one of them", filepath.Join(dir, "reputation.json"), filepath.Join(dir, "reputation.yaml")))
+
+		_, err = Load(filepath.Join(other, "custom.yaml"), filepath.Join(t.TempDir(), "custom.yaml"))
+		assert.Error(t, err)
+
+		_, err = Load(filepath.Join(dir, "reputation.json"), other)
+		assert.EqualError(t, err, fmt.Sprintf("dork files %s and %s have the same name, rename one of them", filepath.Join(dir, "reputation.json"), filepath.Join(other, "reputation.yaml")))
+
+		// A file given twice is loaded once, reputation.yaml replaces the default file
+		s, err := Load(filepath.Join(other, "custom.yaml"), other)
+		assert.NoError(t, err)
+		assert.Len(t, s.Dorks(CategoryGeneral), 4)
+		assert.Empty(t, s.Dorks(CategoryReputation))
+	})
+
+	t.Run("test missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestSplitPaths(t *testing.T) {
+	assert.Equal(t, []string{"dorks", "/etc/phoneinfoga/dorks.yaml"}, SplitPaths(" dorks,, /etc/phoneinfoga/dorks.yaml "))
+	assert.Nil(t, SplitPaths(""))
+}
+
+func writeFile(t *testing.T, name, content string) {
+	if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,26 +1,115 @@
 package filter
 
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// TagPrefix marks rules matching scanner tags instead of scanner names
+const TagPrefix = "tag:"
+
 type Filter interface {
-	Match(string) bool
+	Match(name string, tags ...string) bool
 }
 
+// Engine matches names that should be ignored.
+//
+// Rules are scanner names or glob patterns such as "google*", or tags
+// prefixed with "tag:" such as "tag:paid". A rule starting with "!" is
+// negated. When several rules match, the last one wins.
+//
+// A name is matched when it's denied by a rule, or when allow rules are
+// defined and none of them allows it.
 type Engine struct {
 	rules []string
+	allow []string
 }
 
 func NewEngine() *Engine {
 	return &Engine{}
 }
 
+// AddRule adds deny rules
 func (e *Engine) AddRule(r ...string) {
 	e.rules = append(e.rules, r...)
 }
 
-func (e *Engine) Match(r string) bool {
-	for _, rule := range e.rules {
-		if rule == r {
+// AddAllowRule adds allow rules, restricting the engine to the names they match
+func (e *Engine) AddAllowRule(r ...string) {
+	e.allow = append(e.allow, r...)
+}
+
+func (e *Engine) Match(name string, tags ...string) bool {
+	allowed, matched := evaluate(e.allow, name, tags)
+	if !matched {
+		// Names are allowed by default, unless the allow list selects some
+		allowed = !hasPositiveRule(e.allow)
+	}
+	if !allowed {
+		return true
+	}
+
+	denied, _ := evaluate(e.rules, name, tags)
+	return denied
+}
+
+// Validate returns an error if any of the rules is not a valid pattern
+func (e *Engine) Validate() error {
+	for _, rule := range append(e.allow, e.rules...) {
+		if err := ValidateRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluate returns the outcome of the last rule matching the given name,
+// and false when no rule matches
+func evaluate(rules []string, name string, tags []string) (result bool, matched bool) {
+	for _, rule := range rules {
+		negated := strings.HasPrefix(rule, "!")
+		if matchRule(strings.TrimPrefix(rule, "!"), name, tags) {
+			result, matched = !negated, true
+		}
+	}
+	return result, matched
+}
+
+func hasPositiveRule(rules []string) bool {
+	for _, rule := range rules {
+		if !strings.HasPrefix(rule, "!") {
 			return true
 		}
 	}
 	return false
 }
+
+func matchRule(rule, name string, tags []string) bool {
+	if strings.HasPrefix(rule, TagPrefix) {
+		for _, tag := range tags {
+			if matchPattern(strings.TrimPrefix(rule, TagPrefix), tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return matchPattern(rule, name)
+}
+
+func matchPattern(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	return err == nil && ok
+}
+
+// ValidateRule returns an error if the given rule is not a valid pattern
+func ValidateRule(rule string) error {
+	pattern := strings.TrimPrefix(strings.TrimPrefix(rule, "!"), TagPrefix)
+	if pattern == "" {
+		return fmt.Errorf("invalid filter rule %q: empty pattern", rule)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid filter rule %q: %v", rule, err)
+	}
+	return nil
+}
@@ -6,9 +6,19 @@ import (
 )
 
 func TestFilterEngine(t *testing.T) {
+	tags := map[string][]string{
+		"local":        {"offline"},
+		"numverify":    {"network", "paid"},
+		"googlesearch": {"offline"},
+		"googlecse":    {"network", "paid"},
+		"ovh":          {"network"},
+		"custom":       {"network", "plugin"},
+	}
+
 	testcases := []struct {
 		name     string
 		rules    []string
+		allow    []string
 		expected map[string]bool
 	}{
 		{
@@ -27,15 +37,114 @@ func TestFilterEngine(t *testing.T) {
 				"numverify":    false,
 			},
 		},
+		{
+			name:  "test only allowed scanners are kept",
+			allow: []string{"local", "numverify"},
+			rules: []string{"numverify"},
+			expected: map[string]bool{
+				"local":        false,
+				"numverify":    true,
+				"googlesearch": true,
+			},
+		},
+		{
+			name:  "test glob rules",
+			rules: []string{"google*"},
+			expected: map[string]bool{
+				"googlesearch": true,
+				"googlecse":    true,
+				"local":        false,
+			},
+		},
+		{
+			name:  "test negated rules",
+			rules: []string{"google*", "!googlesearch"},
+			expected: map[string]bool{
+				"googlesearch": false,
+				"googlecse":    true,
+				"local":        false,
+			},
+		},
+		{
+			name:  "test last matching rule wins",
+			rules: []string{"!googlesearch", "google*"},
+			expected: map[string]bool{
+				"googlesearch": true,
+				"googlecse":    true,
+			},
+		},
+		{
+			name:  "test tag rules",
+			rules: []string{"tag:paid"},
+			expected: map[string]bool{
+				"numverify": true,
+				"googlecse": true,
+				"ovh":       false,
+				"local":     false,
+			},
+		},
+		{
+			name:  "test allow-list with tags",
+			allow: []string{"tag:network", "!tag:plugin"},
+			expected: map[string]bool{
+				"numverify":    false,
+				"ovh":          false,
+				"custom":       true,
+				"local":        true,
+				"googlesearch": true,
+			},
+		},
+		{
+			name:  "test allow-list with only negated rules",
+			allow: []string{"!tag:offline"},
+			expected: map[string]bool{
+				"local":     true,
+				"numverify": false,
+				"custom":    false,
+			},
+		},
+		{
+			name:  "test allow-list with glob and deny rules",
+			allow: []string{"google*", "local"},
+			rules: []string{"tag:paid"},
+			expected: map[string]bool{
+				"googlesearch": false,
+				"googlecse":    true,
+				"local":        false,
+				"ovh":          true,
+			},
+		},
+		{
+			name:  "test invalid pattern never matches",
+			rules: []string{"[google"},
+			expected: map[string]bool{
+				"googlesearch": false,
+			},
+		},
 	}
 
 	for _, tt := range testcases {
 		t.Run(tt.name, func(t *testing.T) {
 			e := NewEngine()
 			e.AddRule(tt.rules...)
+			e.AddAllowRule(tt.allow...)
 			for r, isIgnored := range tt.expected {
-				assert.Equal(t, isIgnored, e.Match(r))
+				assert.Equal(t, isIgnored, e.Match(r, tags[r]...), r)
 			}
 		})
 	}
 }
+
+func TestValidateRule(t *testing.T) {
+	for _, rule := range []string{"local", "google*", "!googlesearch", "tag:paid", "!tag:net*"} {
+		assert.NoError(t, ValidateRule(rule), rule)
+	}
+
+	assert.EqualError(t, ValidateRule("[google"), "invalid filter rule \"[google\": syntax error in pattern")
+	assert.EqualError(t, ValidateRule("!tag:"), "invalid filter rule \"!tag:\": empty pattern")
+
+	e := NewEngine()
+	e.AddRule("google*")
+	e.AddAllowRule("tag:net[")
+	assert.EqualError(t, e.Validate(), "invalid filter rule \"tag:net[\": syntax error in pattern")
+}
@@ -0,0 +1,303 @@
+// Package fixtures provides an HTTP transport able to record supplier
+// responses to disk and replay them later without network access.
+package fixtures
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Mode string
+
+const (
+	// Off disables the transport, requests are sent as usual
+	Off Mode = ""
+	// Record sends requests and saves responses to the fixtures directory
+	Record Mode = "record"
+	// Replay serves responses from the fixtures directory without network access
+	Replay Mode = "replay"
+)
+
+const (
+	ModeEnv = "PHONEINFOGA_FIXTURES_MODE"
+	DirEnv  = "PHONEINFOGA_FIXTURES_DIR"
+
+	DefaultDir = "fixtures"
+
+	redacted = "REDACTED"
+)
+
+// sensitiveHeaders are never written to fixture files
+var sensitiveHeaders = []string{
+	"Apikey",
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Goog-Api-Key",
+}
+
+// sensitiveParams are redacted from request URLs
+var sensitiveParams = []string{
+	"access_key",
+	"api_key",
+	"apikey",
+	"key",
+	"token",
+}
+
+// Fixture is the on-disk format of a recorded HTTP exchange
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+type FixtureRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type FixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Transport is an http.RoundTripper recording or replaying HTTP exchanges.
+// When Base is nil, http.DefaultTransport at the time of installation is used.
+type Transport struct {
+	Mode Mode
+	Dir  string
+	Base http.RoundTripper
+
+	m sync.Mutex
+}
+
+func NewTransport(mode Mode, dir string, base http.RoundTripper) *Transport {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Transport{
+		Mode: mode,
+		Dir:  dir,
+		Base: base,
+	}
+}
+
+// ParseMode validates the given mode name
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case Off, Record, Replay:
+		return m, nil
+	}
+	return Off, fmt.Errorf("unknown fixtures mode %q, must be one of: record, replay", s)
+}
+
+// Layer returns a function wrapping a transport with the record/replay
+// transport, to add it to the chain supplier requests go through. Mode and
+// directory default to PHONEINFOGA_FIXTURES_MODE and PHONEINFOGA_FIXTURES_DIR
+// env vars. It returns nil when fixtures are off.
+func Layer(mode Mode, dir string) (func(base http.RoundTripper) http.RoundTripper, error) {
+	if mode == Off {
+		m, err := ParseMode(os.Getenv(ModeEnv))
+		if err != nil {
+			return nil, err
+		}
+		mode = m
+	}
+	if dir == "" {
+		dir = os.Getenv(DirEnv)
+	}
+	if mode == Off {
+		return nil, nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"mode": mode,
+		"dir":  dir,
+	}).Debug("HTTP fixtures transport enabled")
+
+	return func(base http.RoundTripper) http.RoundTripper {
+		return NewTransport(mode, dir, base)
+	}, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := Key(req.Method, req.URL, body)
+
+	switch t.Mode {
+	case Replay:
+		return t.replay(req, key)
+	case Record:
+		return t.record(req, key, body)
+	}
+	return t.base().RoundTrip(req)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) replay(req *http.Request, key string) (*http.Response, error) {
+	data, err := os.ReadFile(t.path(key))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no fixture recorded for %s %s", req.Method, RedactURL(req.URL))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("fixture %s is not valid: %v", t.path(key), err)
+	}
+
+	logrus.WithField("url", f.Request.URL).Debug("Replaying HTTP fixture")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Response.Header,
+		Body:          io.NopCloser(strings.NewReader(f.Response.Body)),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *Transport) record(req *http.Request, key string, reqBody []byte) (*http.Response, error) {
+	res, err := t.base().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	f := Fixture{
+		Request: FixtureRequest{
+			Method: req.Method,
+			URL:    RedactURL(req.URL),
+			Header: RedactHeader(req.Header),
+			Body:   string(reqBody),
+		},
+		Response: FixtureResponse{
+			StatusCode: res.StatusCode,
+			Header:     RedactHeader(res.Header),
+			Body:       string(resBody),
+		},
+	}
+
+	if err := t.write(key, f); err != nil {
+		logrus.WithField("error", err).Warn("Failed to write HTTP fixture")
+	}
+
+	return res, nil
+}
+
+func (t *Transport) write(key string, f Fixture) error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if err := os.MkdirAll(t.Dir, 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path(key), data, 0600)
+}
+
+func (t *Transport) path(key string) string {
+	return filepath.Join(t.Dir, key+".json")
+}
+
+// Key returns the fixture name of a request. Sensitive query
+// parameters are ignored so fixtures can be replayed with any credentials.
+func Key(method string, u *url.URL, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, method)
+	_, _ = io.WriteString(h, RedactURL(u))
+	_, _ = h.Write(body)
+	return fmt.Sprintf("%s_%s", strings.ReplaceAll(u.Hostname(), ".", "_"), hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// RedactURL returns the URL with sensitive query parameters
+// replaced, and query parameters sorted.
+func RedactURL(u *url.URL) string {
+	c := *u
+	c.User = nil
+	q := c.Query()
+	for k := range q {
+		if isSensitive(k, sensitiveParams) {
+			q.Set(k, redacted)
+		}
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+// RedactHeader returns a copy of the headers with sensitive values replaced
+func RedactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	c := h.Clone()
+	for k := range c {
+		if isSensitive(k, sensitiveHeaders) {
+			c.Set(k, redacted)
+		}
+	}
+	return c
+}
+
+func isSensitive(k string, list []string) bool {
+	for _, s := range list {
+		if strings.EqualFold(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,127 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMode(t *testing.T) {
+	testcases := []struct {
+		name    string
+		input   string
+		want    Mode
+		wantErr string
+	}{
+		{name: "test empty mode", input: "", want: Off},
+		{name: "test record mode", input: "record", want: Record},
+		{name: "test replay mode", input: "REPLAY", want: Replay},
+		{name: "test invalid mode", input: "foo", want: Off, wantErr: "unknown fixtures mode \"foo\", must be one of: record, replay"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMode(tt.input)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTransport_RecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"valid":true}`))
+	}))
+
+	client := &http.Client{Transport: NewTransport(Record, dir, http.DefaultTransport)}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/validate?number=14152229670&key=secret", nil)
+	req.Header.Set("Apikey", "secret")
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, `{"valid":true}`, string(body))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, string(data), "secret")
+
+	var f Fixture
+	assert.NoError(t, json.Unmarshal(data, &f))
+	assert.Equal(t, srv.URL+"/validate?key=REDACTED&number=14152229670", f.Request.URL)
+	assert.Equal(t, "REDACTED", f.Request.Header.Get("Apikey"))
+	assert.Equal(t, "REDACTED", f.Response.Header.Get("Set-Cookie"))
+	assert.Equal(t, 200, f.Response.StatusCode)
+
+	// Replay must not reach the network, even with other credentials
+	srv.Close()
+
+	client = &http.Client{Transport: NewTransport(Replay, dir, http.DefaultTransport)}
+	req, _ = http.NewRequest(http.MethodGet, srv.URL+"/validate?number=14152229670&key=other", nil)
+
+	res, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.Equal(t, `{"valid":true}`, string(body))
+}
+
+func TestTransport_ReplayMissingFixture(t *testing.T) {
+	client := &http.Client{Transport: NewTransport(Replay, t.TempDir(), nil)}
+
+	_, err := client.Get("https://api.ovh.com/1.0/telephony/number/detailedZones?country=fr")
+	assert.EqualError(t, err, "Get \"https://api.ovh.com/1.0/telephony/number/detailedZones?country=fr\": no fixture recorded for GET https://api.ovh.com/1.0/telephony/number/detailedZones?country=fr")
+}
+
+func TestLayer(t *testing.T) {
+	previous := http.DefaultTransport
+
+	layer, err := Layer(Off, "")
+	assert.NoError(t, err)
+	assert.Nil(t, layer)
+
+	_ = os.Setenv(ModeEnv, "replay")
+	defer os.Unsetenv(ModeEnv)
+
+	layer, err = Layer(Off, "testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, &Transport{Mode: Replay, Dir: "testdata", Base: previous}, layer(previous))
+	// The global transport is left untouched
+	assert.Equal(t, previous, http.DefaultTransport)
+}
+
+func TestKey(t *testing.T) {
+	u1, _ := url.Parse("https://api.apilayer.com/number_verification/validate?number=14152229670&key=a")
+	u2, _ := url.Parse("https://api.apilayer.com/number_verification/validate?key=b&number=14152229670")
+	u3, _ := url.Parse("https://api.apilayer.com/number_verification/validate?number=33679368229")
+
+	assert.Equal(t, Key(http.MethodGet, u1, nil), Key(http.MethodGet, u2, nil))
+	assert.NotEqual(t, Key(http.MethodGet, u1, nil), Key(http.MethodGet, u3, nil))
+	assert.NotEqual(t, Key(http.MethodGet, u1, nil), Key(http.MethodPost, u1, nil))
+	assert.Regexp(t, "^api_apilayer_com_[a-f0-9]{16}$", Key(http.MethodGet, u1, nil))
+}
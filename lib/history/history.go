@@ -0,0 +1,375 @@
+// Package history persists scans in an embedded bbolt database, so they
+// can be listed, compared or exported later without scanning again.
+package history
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// FileEnv is the environment variable giving the history database path
+	FileEnv = "PHONEINFOGA_HISTORY_FILE"
+
+	// Metadata keys set by PhoneInfoga
+	MetadataSource  = "source"
+	MetadataProfile = "profile"
+	MetadataVersion = "version"
+)
+
+var (
+	scansBucket   = []byte("scans")
+	numbersBucket = []byte("numbers")
+
+	// ErrNotFound is returned when a scan doesn't exist
+	ErrNotFound = errors.New("scan not found")
+)
+
+// Scan is a scan stored in history
+type Scan struct {
+	ID       string                 `json:"id"`
+	Date     time.Time              `json:"date"`
+	Number   number.Number          `json:"number"`
+	Results  map[string]interface{} `json:"results"`
+	Errors   map[string]string      `json:"errors"`
+	Metadata map[string]string      `json:"metadata,omitempty"`
+}
+
+// NewScan returns a scan with the given results, dated from now
+func NewScan(n *number.Number, results map[string]interface{}, errs map[string]error, metadata map[string]string) *Scan {
+	s := &Scan{
+		Date:     time.Now().UTC(),
+		Number:   *n,
+		Results:  map[string]interface{}{},
+		Errors:   map[string]string{},
+		Metadata: metadata,
+	}
+	for name, r := range results {
+		s.Results[name] = r
+	}
+	for name, err := range errs {
+		s.Errors[name] = err.Error()
+	}
+	return s
+}
+
+// Scanners returns names of scanners that produced a result or an error, sorted
+func (s *Scan) Scanners() []string {
+	var names []string
+	for name := range s.Results {
+		names = append(names, name)
+	}
+	for name := range s.Errors {
+		if _, ok := s.Results[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ErrorMap returns scan errors as errors, like they're returned by scanners
+func (s *Scan) ErrorMap() map[string]error {
+	errs := map[string]error{}
+	for name, msg := range s.Errors {
+		errs[name] = errors.New(msg)
+	}
+	return errs
+}
+
+// Query filters scans. Zero values match all scans.
+type Query struct {
+	Number  string
+	Country string
+	Scanner string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func (q Query) match(s *Scan) bool {
+	if q.Number != "" && s.Number.E164 != q.Number {
+		return false
+	}
+	if q.Country != "" && s.Number.Country != q.Country {
+		return false
+	}
+	if q.Scanner != "" {
+		_, hasResult := s.Results[q.Scanner]
+		_, hasError := s.Errors[q.Scanner]
+		if !hasResult && !hasError {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && s.Date.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && s.Date.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+type Store interface {
+	Save(*Scan) error
+	Get(id string) (*Scan, error)
+	List(Query) ([]*Scan, error)
+	Delete(id string) error
+	Numbers() ([]string, error)
+}
+
+// Pinger is implemented by stores able to check they can be used
+type Pinger interface {
+	Ping() error
+}
+
+// BoltStore stores scans in a bbolt database file. The file is opened
+// for every operation, so several processes can share it.
+type BoltStore struct {
+	path string
+}
+
+// DefaultPath returns the history database path from PHONEINFOGA_HISTORY_FILE,
+// defaulting to a file in the user configuration directory.
+func DefaultPath() string {
+	if path := os.Getenv(FileEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "history.db"
+	}
+	return filepath.Join(dir, "phoneinfoga", "history.db")
+}
+
+func NewBoltStore(path string) *BoltStore {
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &BoltStore{path: path}
+}
+
+func (s *BoltStore) Path() string {
+	return s.path
+}
+
+func (s *BoltStore) open(readOnly bool) (*bolt.DB, error) {
+	if readOnly {
+		if _, err := os.Stat(s.path); os.IsNotExist(err) {
+			return nil, nil
+		}
+	} else if err := os.MkdirAll(filepath.Dir(s.path), 0750); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open history file %s: %v", s.path, err)
+	}
+	return db, nil
+}
+
+func (s *BoltStore) update(fn func(*bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// view runs fn in a read-only transaction, fn is not called if the file doesn't exist
+func (s *BoltStore) view(fn func(*bolt.Tx) error) error {
+	db, err := s.open(true)
+	if err != nil || db == nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+// Ping checks the database file can be opened for writing, as when saving scans
+func (s *BoltStore) Ping() error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// Save stores the given scan, an ID is generated if it has none
+func (s *BoltStore) Save(scan *Scan) error {
+	if scan.ID == "" {
+		id, err := newID(scan.Date)
+		if err != nil {
+			return err
+		}
+		scan.ID = id
+	}
+
+	data, err := json.Marshal(scan)
+	if err != nil {
+		return err
+	}
+
+	return s.update(func(tx *bolt.Tx) error {
+		scans, err := tx.CreateBucketIfNotExists(scansBucket)
+		if err != nil {
+			return err
+		}
+		numbers, err := tx.CreateBucketIfNotExists(numbersBucket)
+		if err != nil {
+			return err
+		}
+		if err := scans.Put([]byte(scan.ID), data); err != nil {
+			return err
+		}
+		return numbers.Put(numberKey(scan.Number.E164, scan.ID), nil)
+	})
+}
+
+func (s *BoltStore) Get(id string) (*Scan, error) {
+	var scan *Scan
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(scansBucket)
+		if b == nil {
+			return nil
+		}
+		data := b.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		scan = &Scan{}
+		return json.Unmarshal(data, scan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if scan == nil {
+		return nil, ErrNotFound
+	}
+	return scan, nil
+}
+
+// List returns scans matching the query, newest first
+func (s *BoltStore) List(q Query) ([]*Scan, error) {
+	var scans []*Scan
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(scansBucket)
+		if b == nil {
+			return nil
+		}
+
+		add := func(data []byte) error {
+			scan := &Scan{}
+			if err := json.Unmarshal(data, scan); err != nil {
+				return err
+			}
+			if q.match(scan) {
+				scans = append(scans, scan)
+			}
+			return nil
+		}
+
+		// Scans of a single number are looked up through the numbers index
+		if q.Number != "" {
+			index := tx.Bucket(numbersBucket)
+			if index == nil {
+				return nil
+			}
+			prefix := numberKey(q.Number, "")
+			c := index.Cursor()
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				if data := b.Get(k[len(prefix):]); data != nil {
+					if err := add(data); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		return b.ForEach(func(_, data []byte) error {
+			return add(data)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(scans, func(i, j int) bool {
+		return scans[i].Date.After(scans[j].Date)
+	})
+	if q.Limit > 0 && len(scans) > q.Limit {
+		scans = scans[:q.Limit]
+	}
+	return scans, nil
+}
+
+func (s *BoltStore) Delete(id string) error {
+	scan, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(scansBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(numbersBucket).Delete(numberKey(scan.Number.E164, id))
+	})
+}
+
+// Numbers returns all scanned numbers in E164 format, sorted
+func (s *BoltStore) Numbers() ([]string, error) {
+	var numbers []string
+	err := s.view(func(tx *bolt.Tx) error {
+		index := tx.Bucket(numbersBucket)
+		if index == nil {
+			return nil
+		}
+		return index.ForEach(func(k, _ []byte) error {
+			n := string(k[:bytes.IndexByte(k, '/')])
+			if len(numbers) == 0 || numbers[len(numbers)-1] != n {
+				numbers = append(numbers, n)
+			}
+			return nil
+		})
+	})
+	return numbers, err
+}
+
+func numberKey(e164, id string) []byte {
+	return []byte(e164 + "/" + id)
+}
+
+// newID returns an ID sorted by date, with a random suffix to avoid collisions
+func newID(date time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x-%s", date.UnixNano(), hex.EncodeToString(suffix)), nil
+}
+
+// ParseDate parses dates given in queries, either as YYYY-MM-DD or RFC 3339
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339 format", s)
+	}
+	return t, nil
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+)
+
+func newTestScan(t *testing.T, n string, date time.Time, results map[string]interface{}, errs map[string]error) *Scan {
+	num, err := number.NewNumber(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewScan(num, results, errs, map[string]string{MetadataSource: "test"})
+	s.Date = date
+	return s
+}
+
+func TestBoltStore(t *testing.T) {
+	store := NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+
+	// Reading a store that doesn't exist yet
+	scans, err := store.List(Query{})
+	assert.NoError(t, err)
+	assert.Len(t, scans, 0)
+	_, err = store.Get("unknown")
+	assert.Equal(t, ErrNotFound, err)
+
+	day := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	us1 := newTestScan(t, "14152229670", day, map[string]interface{}{"local": map[string]interface{}{"country": "US"}}, map[string]error{})
+	us2 := newTestScan(t, "14152229670", day.Add(24*time.Hour), map[string]interface{}{}, map[string]error{"numverify": errors.New("dummy error")})
+	fr := newTestScan(t, "33679368229", day.Add(48*time.Hour), map[string]interface{}{"local": map[string]interface{}{"country": "FR"}}, map[string]error{})
+
+	for _, s := range []*Scan{us1, us2, fr} {
+		assert.NoError(t, store.Save(s))
+		assert.NotEmpty(t, s.ID)
+	}
+
+	got, err := store.Get(us1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, us1, got)
+
+	testcases := []struct {
+		name     string
+		query    Query
+		expected []*Scan
+	}{
+		{
+			name:     "test all scans, newest first",
+			query:    Query{},
+			expected: []*Scan{fr, us2, us1},
+		},
+		{
+			name:     "test by number",
+			query:    Query{Number: "+14152229670"},
+			expected: []*Scan{us2, us1},
+		},
+		{
+			name:     "test by country",
+			query:    Query{Country: "FR"},
+			expected: []*Scan{fr},
+		},
+		{
+			name:     "test by scanner",
+			query:    Query{Scanner: "numverify"},
+			expected: []*Scan{us2},
+		},
+		{
+			name:     "test by date range",
+			query:    Query{Since: day.Add(time.Hour), Until: day.Add(47 * time.Hour)},
+			expected: []*Scan{us2},
+		},
+		{
+			name:     "test limit",
+			query:    Query{Limit: 1},
+			expected: []*Scan{fr},
+		},
+		{
+			name:  "test no match",
+			query: Query{Number: "+33123456789"},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			scans, err := store.List(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, scans)
+		})
+	}
+
+	numbers, err := store.Numbers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+14152229670", "+33679368229"}, numbers)
+
+	assert.NoError(t, store.Delete(us2.ID))
+	assert.Equal(t, ErrNotFound, store.Delete(us2.ID))
+
+	scans, err = store.List(Query{Number: "+14152229670"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*Scan{us1}, scans)
+}
+
+func TestScan(t *testing.T) {
+	s := NewScan(test.NewFakeUSNumber(), map[string]interface{}{
+		"local": map[string]interface{}{},
+	}, map[string]error{
+		"numverify": errors.New("dummy error"),
+	}, nil)
+
+	assert.Equal(t, "+14152229670", s.Number.E164)
+	assert.Equal(t, []string{"local", "numverify"}, s.Scanners())
+	assert.Equal(t, map[string]error{"numverify": errors.New("dummy error")}, s.ErrorMap())
+	assert.WithinDuration(t, time.Now(), s.Date, time.Minute)
+}
+
+func TestParseDate(t *testing.T) {
+	d, err := ParseDate("2023-01-02")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), d)
+
+	d, err = ParseDate("2023-01-02T15:04:05Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC), d)
+
+	_, err = ParseDate("yesterday")
+	assert.EqualError(t, err, "invalid date \"yesterday\", expected YYYY-MM-DD or RFC 3339 format")
+}
@@ -0,0 +1,128 @@
+// Package metrics exports Prometheus metrics of the web server, scanners and suppliers.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+const namespace = "phoneinfoga"
+
+// Metrics holds collectors in a dedicated registry, so
+// several instances can be used at once, e.g. in tests.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests     *prometheus.CounterVec
+	httpDuration     *prometheus.HistogramVec
+	scans            *prometheus.CounterVec
+	scanDuration     *prometheus.HistogramVec
+	supplierRequests *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled by the web server, by route and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests handled by the web server, by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		scans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scans_total",
+			Help:      "Scanner runs, by scanner and status (success, error, skipped, panic).",
+		}, []string{"scanner", "status"}),
+		scanDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scanner_duration_seconds",
+			Help:      "Latency of scanner runs, by scanner.",
+			Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"scanner"}),
+		supplierRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "supplier_responses_total",
+			Help:      "Responses of upstream suppliers, by host and status code, \"error\" when no response was received.",
+		}, []string{"host", "code"}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.scans,
+		m.scanDuration,
+		m.supplierRequests,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Handler serves metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WriteFile writes metrics to a file, for the node exporter textfile collector
+func (m *Metrics) WriteFile(path string) error {
+	return prometheus.WriteToTextfile(path, m.registry)
+}
+
+// ObserveRequest records an HTTP request handled by the web server
+func (m *Metrics) ObserveRequest(method, route string, code int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveScan records a scanner run, it implements remote.ScanObserver
+func (m *Metrics) ObserveScan(scanner string, status remote.ScanStatus, duration time.Duration) {
+	m.scans.WithLabelValues(scanner, string(status)).Inc()
+	// Skipped scanners didn't actually run
+	if status != remote.ScanSkipped {
+		m.scanDuration.WithLabelValues(scanner).Observe(duration.Seconds())
+	}
+}
+
+// RegisterJobQueue exports the number of background jobs returned by the given function
+func (m *Metrics) RegisterJobQueue(depth func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_queue_depth",
+		Help:      "Background jobs not done yet, such as scans with a callback URL and their webhook deliveries.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// Transport records status codes of responses received through the base transport
+func (m *Metrics) Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base, metrics: m}
+}
+
+type transport struct {
+	base    http.RoundTripper
+	metrics *Metrics
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	t.metrics.supplierRequests.WithLabelValues(req.URL.Hostname(), code).Inc()
+	return res, err
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	m.ObserveRequest("POST", "/api/v2/scans", 200, time.Second)
+	m.ObserveRequest("POST", "/api/v2/scans", 200, time.Second)
+	m.ObserveRequest("POST", "/api/v2/scans", 400, time.Second)
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues("POST", "/api/v2/scans", "200")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.httpDuration))
+
+	m.ObserveScan("local", remote.ScanSuccess, time.Millisecond)
+	m.ObserveScan("numverify", remote.ScanSkipped, 0)
+	m.ObserveScan("ovh", remote.ScanPanic, time.Millisecond)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.scans.WithLabelValues("numverify", "skipped")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.scans.WithLabelValues("ovh", "panic")))
+	// Skipped scanners have no latency
+	assert.Equal(t, 2, testutil.CollectAndCount(m.scanDuration))
+
+	pending := 3
+	m.RegisterJobQueue(func() int { return pending })
+
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	assert.Contains(t, string(body), `phoneinfoga_scans_total{scanner="local",status="success"} 1`)
+	assert.Contains(t, string(body), "phoneinfoga_job_queue_depth 3")
+
+	path := filepath.Join(t.TempDir(), "phoneinfoga.prom")
+	assert.NoError(t, m.WriteFile(path))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), `phoneinfoga_http_requests_total{code="400",method="POST",route="/api/v2/scans"} 1`))
+}
+
+func TestMetrics_Transport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	m := New()
+	client := &http.Client{Transport: m.Transport(http.DefaultTransport)}
+
+	res, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	_ = res.Body.Close()
+	_, err = client.Get("http://127.0.0.1:1")
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.supplierRequests.WithLabelValues("127.0.0.1", "429")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.supplierRequests.WithLabelValues("127.0.0.1", "error")))
+}
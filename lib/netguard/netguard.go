@@ -0,0 +1,160 @@
+// Package netguard keeps requests made on behalf of users, such as webhooks
+// or fetches of plugins, from reaching the host or its internal networks.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrForbidden is returned for destinations the guard doesn't allow
+var ErrForbidden = errors.New("destination is a loopback, private or link-local address")
+
+// Guard rejects loopback, private, link-local and unspecified
+// addresses, unless they belong to an allowed network
+type Guard struct {
+	allowed []*net.IPNet
+	dialer  *net.Dialer
+	// proxies are addresses of proxies requests were sent to,
+	// proxies connect to destinations checked beforehand
+	proxies sync.Map
+}
+
+// New returns a guard allowing the given networks, written in CIDR
+// notation or as single IP addresses, e.g. "10.0.0.0/8" or "127.0.0.1"
+func New(allowed ...string) (*Guard, error) {
+	g := &Guard{dialer: &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}}
+	for _, a := range allowed {
+		if !strings.Contains(a, "/") {
+			ip := net.ParseIP(a)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid allowed network %q", a)
+			}
+			bits := 8 * len(ip)
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			g.allowed = append(g.allowed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network %q: %v", a, err)
+		}
+		g.allowed = append(g.allowed, n)
+	}
+	return g, nil
+}
+
+// SplitNetworks splits a comma separated list of networks
+func SplitNetworks(s string) []string {
+	var res []string
+	for _, n := range strings.Split(s, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// CheckIP returns ErrForbidden if the address isn't allowed
+func (g *Guard) CheckIP(ip net.IP) error {
+	for _, n := range g.allowed {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return fmt.Errorf("%w: %s", ErrForbidden, ip)
+	}
+	return nil
+}
+
+// CheckHost resolves the host and checks each of its addresses
+func (g *Guard) CheckHost(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, g.CheckIP(ip)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, a := range addrs {
+		if err := g.CheckIP(a.IP); err != nil {
+			return nil, fmt.Errorf("host %s: %w", host, err)
+		}
+		ips = append(ips, a.IP)
+	}
+	return ips, nil
+}
+
+// DialContext connects to checked addresses only. The host is resolved
+// once, so it can't resolve to another address when connecting.
+func (g *Guard) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if _, ok := g.proxies.Load(addr); ok {
+		return g.dialer.DialContext(ctx, network, addr)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := g.CheckHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = g.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// Transport returns a transport connecting to checked addresses only.
+// Proxies set in the environment are used once destinations are checked.
+func (g *Guard) Transport() *http.Transport {
+	return &http.Transport{
+		Proxy:                 g.proxy,
+		DialContext:           g.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+func (g *Guard) proxy(req *http.Request) (*url.URL, error) {
+	u, err := http.ProxyFromEnvironment(req)
+	if u == nil || err != nil {
+		return u, err
+	}
+	if _, err := g.CheckHost(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	g.proxies.Store(proxyAddr(u), true)
+	return u, nil
+}
+
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
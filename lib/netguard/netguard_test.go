@@ -0,0 +1,96 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuard_CheckIP(t *testing.T) {
+	g, err := New("10.1.0.0/16", "127.0.0.2")
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		ip      string
+		allowed bool
+	}{
+		{ip: "93.184.216.34", allowed: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", allowed: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "10.0.0.1"},
+		{ip: "172.16.5.4"},
+		{ip: "192.168.1.1"},
+		{ip: "169.254.169.254"},
+		{ip: "fe80::1"},
+		{ip: "fd00::1"},
+		{ip: "0.0.0.0"},
+		{ip: "::ffff:127.0.0.1"},
+		{ip: "10.1.2.3", allowed: true},
+		{ip: "127.0.0.2", allowed: true},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.ip, func(t *testing.T) {
+			err := g.CheckIP(net.ParseIP(tt.ip))
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrForbidden)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidNetwork(t *testing.T) {
+	_, err := New("10.0.0.0/33")
+	assert.EqualError(t, err, `invalid allowed network "10.0.0.0/33": invalid CIDR address: 10.0.0.0/33`)
+
+	_, err = New("localhost")
+	assert.EqualError(t, err, `invalid allowed network "localhost"`)
+}
+
+func TestGuard_Transport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	g, err := New()
+	assert.NoError(t, err)
+	client := &http.Client{Transport: g.Transport()}
+
+	_, err = client.Get(srv.URL)
+	assert.True(t, errors.Is(err, ErrForbidden), err)
+
+	// Hosts are resolved before connecting
+	_, err = client.Get("http://localhost:1")
+	assert.True(t, errors.Is(err, ErrForbidden), err)
+
+	g, err = New("127.0.0.1")
+	assert.NoError(t, err)
+	client = &http.Client{Transport: g.Transport()}
+
+	res, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	_ = res.Body.Close()
+}
+
+func TestGuard_CheckHost(t *testing.T) {
+	g, _ := New()
+	_, err := g.CheckHost(context.Background(), "127.0.0.1")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	ips, err := g.CheckHost(context.Background(), "93.184.216.34")
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("93.184.216.34")}, ips)
+}
+
+func TestSplitNetworks(t *testing.T) {
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1"}, SplitNetworks(" 10.0.0.0/8,, 127.0.0.1 "))
+	assert.Nil(t, SplitNetworks(""))
+}
@@ -49,6 +49,11 @@ func (o *ConsoleOutput) displayResult(val interface{}, prefix string) {
 	reflectType := reflect.TypeOf(val)
 	reflectValue := reflect.ValueOf(val)
 
+	if reflectValue.Kind() == reflect.Map {
+		o.displayMap(reflectValue, prefix)
+		return
+	}
+
 	if reflectValue.Kind() == reflect.Slice {
 		for i := 0; i < reflectValue.Len(); i++ {
 			item := reflectValue.Index(i)
@@ -98,6 +103,65 @@ func (o *ConsoleOutput) displayResult(val interface{}, prefix string) {
 	}
 }
 
+// displayMap displays untyped results, such as the ones
+// decoded from JSON. Keys are used as field titles.
+func (o *ConsoleOutput) displayMap(m reflect.Value, prefix string) {
+	// Keys of any type are sorted by their text
+	keys := m.MapKeys()
+	titles := make(map[reflect.Value]string, len(keys))
+	for _, k := range keys {
+		titles[k] = fmt.Sprintf("%v", k.Interface())
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return titles[keys[i]] < titles[keys[j]]
+	})
+
+	for _, k := range keys {
+		key := titles[k]
+		value := indirect(m.MapIndex(k))
+		if !value.IsValid() {
+			continue
+		}
+
+		switch value.Kind() {
+		case reflect.Map, reflect.Struct:
+			_, _ = fmt.Fprintf(o.w, "%s%s:\n", prefix, key)
+			o.displayResult(value.Interface(), prefix+"\t")
+		case reflect.Slice:
+			_, _ = fmt.Fprintf(o.w, color.WhiteString("%s%s:\n"), prefix, key)
+			for i := 0; i < value.Len(); i++ {
+				item := indirect(value.Index(i))
+				switch item.Kind() {
+				case reflect.Map, reflect.Struct:
+					o.displayResult(item.Interface(), prefix+"\t")
+					if i < value.Len()-1 {
+						_, _ = fmt.Fprintf(o.w, "\n")
+					}
+				case reflect.Invalid:
+				default:
+					_, _ = fmt.Fprintf(o.w, "%s\t", prefix)
+					_, _ = fmt.Fprintf(o.w, color.YellowString("%v\n"), item.Interface())
+				}
+			}
+		default:
+			_, _ = fmt.Fprintf(o.w, "%s%s: ", prefix, key)
+			_, _ = fmt.Fprintf(o.w, color.YellowString("%v\n"), value.Interface())
+		}
+	}
+}
+
+// indirect dereferences pointers and interfaces,
+// it returns the zero Value for nil values.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
 func getSortedResultKeys(m map[string]interface{}) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -105,6 +105,27 @@ func TestConsoleOutput(t *testing.T) {
 			},
 			errs: map[string]error{},
 		},
+		{
+			name:    "should display untyped results",
+			dirName: "testdata/console_valid_map.txt",
+			result: map[string]interface{}{
+				"testscanner": map[string]interface{}{
+					"valid":   true,
+					"info":    "This number is known for scams!",
+					"reports": float64(42),
+					"empty":   nil,
+					"location": map[string]interface{}{
+						"city": "Paris",
+					},
+					"tags": []interface{}{"scam", "robocall"},
+					"links": []interface{}{
+						map[string]interface{}{"url": "http://example.com/1"},
+						map[string]interface{}{"url": "http://example.com/2"},
+					},
+				},
+			},
+			errs: map[string]error{},
+		},
 	}
 
 	for _, tt := range testcases {
@@ -139,3 +160,17 @@ func TestConsoleOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestConsoleOutput_MapKeys(t *testing.T) {
+	result := map[string]interface{}{
+		"plugin": map[int]interface{}{10: "ten", 2: "two"},
+	}
+
+	var buf bytes.Buffer
+	assert.NotPanics(t, func() {
+		assert.NoError(t, NewConsoleOutput(&buf).Write(result, map[string]error{}))
+	})
+	assert.Contains(t, buf.String(), "10: ten\n")
+	assert.Contains(t, buf.String(), "2: two\n")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("10: ten")), bytes.Index(buf.Bytes(), []byte("2: two")))
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type JSONOutput struct {
+	w io.Writer
+}
+
+// JSONResult is the document written by JSONOutput
+type JSONResult struct {
+	Results map[string]interface{} `json:"results"`
+	Errors  map[string]string      `json:"errors"`
+}
+
+func NewJSONOutput(w io.Writer) *JSONOutput {
+	return &JSONOutput{w: w}
+}
+
+func (o *JSONOutput) Write(result map[string]interface{}, errs map[string]error) error {
+	return WriteJSON(o.w, NewJSONResult(result, errs))
+}
+
+// NewJSONResult returns the document written by JSONOutput, scanners
+// without result are left out and errors are written as strings.
+// It can be embedded in documents holding several results.
+func NewJSONResult(result map[string]interface{}, errs map[string]error) JSONResult {
+	res := JSONResult{
+		Results: map[string]interface{}{},
+		Errors:  map[string]string{},
+	}
+	for name, r := range result {
+		if r != nil {
+			res.Results[name] = r
+		}
+	}
+	for name, err := range errs {
+		res.Errors[name] = err.Error()
+	}
+	return res
+}
+
+// WriteJSON writes v indented, like documents of JSONOutput
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
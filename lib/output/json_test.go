@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/test/goldenfile"
+)
+
+func TestJSONOutput(t *testing.T) {
+	testcases := []struct {
+		name    string
+		dirName string
+		result  map[string]interface{}
+		errs    map[string]error
+	}{
+		{
+			name:    "should produce empty output",
+			dirName: "testdata/json_empty.json",
+			result:  map[string]interface{}{},
+			errs:    map[string]error{},
+		},
+		{
+			name:    "should produce valid output with errors",
+			dirName: "testdata/json_valid_with_errors.json",
+			result: map[string]interface{}{
+				"testscanner": nil,
+				"numverify": remote.NumverifyScannerResponse{
+					Valid:       true,
+					Number:      "test",
+					CountryCode: "test",
+					Carrier:     "test",
+				},
+				"custom": map[string]interface{}{
+					"tags": []interface{}{"scam"},
+				},
+			},
+			errs: map[string]error{
+				"googlesearch": errors.New("dummy error"),
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			shouldUpdate := tt.dirName == *goldenfile.Update
+
+			expected, err := os.ReadFile(tt.dirName)
+			if err != nil && !shouldUpdate {
+				t.Fatal(err)
+			}
+
+			got := new(bytes.Buffer)
+			err = GetOutput(JSON, got).Write(tt.result, tt.errs)
+			assert.NoError(t, err)
+
+			if shouldUpdate {
+				err = os.WriteFile(tt.dirName, got.Bytes(), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected = got.Bytes()
+			}
+
+			assert.Equal(t, string(expected), got.String())
+		})
+	}
+}
+
+func TestParseOutputKey(t *testing.T) {
+	for format, expected := range map[string]OutputKey{"": Console, "console": Console, "json": JSON} {
+		got, err := ParseOutputKey(format)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, got)
+	}
+
+	_, err := ParseOutputKey("xml")
+	assert.EqualError(t, err, "unknown output format \"xml\" (console, json)")
+}
+
+func TestNewJSONResult_Embedded(t *testing.T) {
+	doc := struct {
+		ID string `json:"id"`
+		JSONResult
+	}{
+		ID: "1",
+		JSONResult: NewJSONResult(
+			map[string]interface{}{"local": map[string]interface{}{"country": "US"}, "testscanner": nil},
+			map[string]error{"numverify": errors.New("dummy error")},
+		),
+	}
+
+	got := new(bytes.Buffer)
+	assert.NoError(t, WriteJSON(got, []interface{}{doc}))
+	assert.Equal(t, `[
+  {
+    "id": "1",
+    "results": {
+      "local": {
+        "country": "US"
+      }
+    },
+    "errors": {
+      "numverify": "dummy error"
+    }
+  }
+]
+`, got.String())
+}
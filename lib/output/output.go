@@ -1,6 +1,7 @@
 package output
 
 import (
+	"fmt"
 	"io"
 )
 
@@ -12,12 +13,26 @@ type OutputKey int
 
 const (
 	Console OutputKey = iota + 1
+	JSON
 )
 
+// ParseOutputKey returns the output matching the given format name
+func ParseOutputKey(format string) (OutputKey, error) {
+	switch format {
+	case "", "console":
+		return Console, nil
+	case "json":
+		return JSON, nil
+	}
+	return 0, fmt.Errorf("unknown output format %q (console, json)", format)
+}
+
 func GetOutput(o OutputKey, w io.Writer) Output {
 	switch o {
 	case Console:
 		return NewConsoleOutput(w)
+	case JSON:
+		return NewJSONOutput(w)
 	}
 	return nil
 }
@@ -0,0 +1,311 @@
+// Package plugins manages scanner plugins installed in a plugins directory.
+// Each plugin is described by a manifest file, its checksum is verified
+// before the plugin is loaded.
+package plugins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DirEnv = "PHONEINFOGA_PLUGINS_DIR"
+
+	checksumPrefix = "sha256:"
+	manifestExt    = ".yaml"
+)
+
+// Manifest describes an installed plugin
+type Manifest struct {
+	Name            string   `yaml:"name" json:"name"`
+	Version         string   `yaml:"version,omitempty" json:"version,omitempty"`
+	Path            string   `yaml:"path" json:"path"`
+	Checksum        string   `yaml:"checksum" json:"checksum"`
+	RequiredOptions []string `yaml:"required_options,omitempty" json:"required_options,omitempty"`
+
+	dir string
+}
+
+// FullPath returns the plugin path, relative paths being resolved from the plugins directory
+func (m *Manifest) FullPath() string {
+	if filepath.IsAbs(m.Path) {
+		return m.Path
+	}
+	return filepath.Join(m.dir, m.Path)
+}
+
+// Verify checks the plugin file matches the manifest checksum
+func (m *Manifest) Verify() error {
+	_, err := m.read()
+	return err
+}
+
+// read returns the content of the plugin file once checked it matches
+// the manifest checksum, plugins must be loaded from this content
+func (m *Manifest) read() ([]byte, error) {
+	if !strings.HasPrefix(m.Checksum, checksumPrefix) {
+		return nil, fmt.Errorf("plugin %s has no valid sha256 checksum", m.Name)
+	}
+	data, err := os.ReadFile(m.FullPath())
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(data)
+	if sum := checksumPrefix + hex.EncodeToString(h[:]); sum != m.Checksum {
+		return nil, fmt.Errorf("checksum mismatch for plugin %s: expected %s, got %s", m.Name, m.Checksum, sum)
+	}
+	return data, nil
+}
+
+// Load verifies and opens the plugin, its scanner is registered in the remote
+// library. The plugin is loaded from the verified content of its file.
+func (m *Manifest) Load() error {
+	data, err := m.read()
+	if err != nil {
+		return err
+	}
+	if err := remote.OpenPluginData(m.FullPath(), data); err != nil {
+		return fmt.Errorf("plugin %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+// MissingOptions returns required options that are not defined in the given scanner options
+func (m *Manifest) MissingOptions(opts remote.ScannerOptions) []string {
+	var missing []string
+	for _, o := range m.RequiredOptions {
+		if opts.GetStringEnv(o) == "" {
+			missing = append(missing, o)
+		}
+	}
+	return missing
+}
+
+// DefaultDir returns the plugins directory from PHONEINFOGA_PLUGINS_DIR,
+// defaulting to a directory in the user configuration directory.
+func DefaultDir() string {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "plugins"
+	}
+	return filepath.Join(dir, "phoneinfoga", "plugins")
+}
+
+type Registry struct {
+	dir string
+}
+
+func NewRegistry(dir string) *Registry {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Registry{dir: dir}
+}
+
+func (r *Registry) Dir() string {
+	return r.dir
+}
+
+// List returns manifests found in the plugins directory, sorted by name
+func (r *Registry) List() ([]*Manifest, error) {
+	files, err := r.manifestFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []*Manifest
+	for _, f := range files {
+		m, err := r.readManifest(f)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].Name < manifests[j].Name
+	})
+
+	return manifests, nil
+}
+
+// Get returns the manifest of the given plugin
+func (r *Registry) Get(name string) (*Manifest, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	m, err := r.readManifest(r.manifestPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("plugin %s is not installed", name)
+	}
+	return m, err
+}
+
+// Install copies the plugin file into the plugins directory
+// and writes its manifest, including the file checksum.
+func (r *Registry) Install(src string, m Manifest) (*Manifest, error) {
+	if m.Name == "" {
+		m.Name = strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	}
+	if err := validateName(m.Name); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(r.dir, 0750); err != nil {
+		return nil, err
+	}
+
+	m.Path = m.Name + filepath.Ext(src)
+	m.dir = r.dir
+	if err := copyFile(src, m.FullPath()); err != nil {
+		return nil, err
+	}
+
+	sum, err := Checksum(m.FullPath())
+	if err != nil {
+		return nil, err
+	}
+	m.Checksum = sum
+
+	data, err := yaml.Marshal(&m)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(r.manifestPath(m.Name), data, 0600); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Remove deletes the plugin manifest, and the plugin
+// file when it's located in the plugins directory.
+func (r *Registry) Remove(name string) error {
+	m, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	// Relative paths were checked to stay in the plugins directory
+	if !filepath.IsAbs(m.Path) {
+		if err := os.Remove(m.FullPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Remove(r.manifestPath(name))
+}
+
+// Load verifies and opens all installed plugins, so their scanners are
+// registered in the remote library. Plugins failing to load don't prevent
+// others from loading, an error is returned for each of them.
+func (r *Registry) Load() []error {
+	files, err := r.manifestFiles()
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, f := range files {
+		m, err := r.readManifest(f)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := m.Load(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		logrus.WithField("plugin", m.Name).WithField("version", m.Version).Debug("Plugin loaded")
+	}
+	return errs
+}
+
+func (r *Registry) manifestFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(r.dir, "*"+manifestExt))
+}
+
+func (r *Registry) manifestPath(name string) string {
+	return filepath.Join(r.dir, name+manifestExt)
+}
+
+// validateName checks the plugin name can be used as a file name in the plugins directory
+func validateName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("plugin name %q is not valid", name)
+	}
+	return nil
+}
+
+func (r *Registry) readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read plugin manifest: %w", err)
+	}
+
+	m := &Manifest{dir: r.dir}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("plugin manifest %s is not valid: %v", path, err)
+	}
+	if m.Name == "" || m.Path == "" {
+		return nil, fmt.Errorf("plugin manifest %s must define a name and a path", path)
+	}
+	if err := validateName(m.Name); err != nil {
+		return nil, fmt.Errorf("plugin manifest %s: %v", path, err)
+	}
+	// Plugins outside of the directory must be given with an absolute path
+	if !filepath.IsAbs(m.Path) && !filepath.IsLocal(m.Path) {
+		return nil, fmt.Errorf("plugin manifest %s: path %s is outside of the plugins directory", path, m.Path)
+	}
+	return m, nil
+}
+
+// Checksum returns the sha256 checksum of the given file, as written in manifests
+func Checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return checksumPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,234 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+)
+
+const testPlugin = "../remote/testdata/exec_plugin.sh"
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+
+	manifests, err := r.List()
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 0)
+
+	m, err := r.Install(testPlugin, Manifest{Version: "v1.0.0", RequiredOptions: []string{"EXEC_API_KEY"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "exec_plugin", m.Name)
+	assert.Equal(t, "exec_plugin.sh", m.Path)
+	assert.Regexp(t, "^sha256:[a-f0-9]{64}$", m.Checksum)
+	assert.NoError(t, m.Verify())
+
+	info, err := os.Stat(m.FullPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotZero(t, info.Mode()&0111, "plugin must stay executable")
+
+	manifests, err = r.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []*Manifest{m}, manifests)
+
+	got, err := r.Get("exec_plugin")
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+
+	assert.Equal(t, []string{"EXEC_API_KEY"}, got.MissingOptions(remote.ScannerOptions{}))
+	assert.Len(t, got.MissingOptions(remote.ScannerOptions{"EXEC_API_KEY": "secret"}), 0)
+
+	assert.NoError(t, r.Remove("exec_plugin"))
+	_, err = os.Stat(m.FullPath())
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = r.Get("exec_plugin")
+	assert.EqualError(t, err, "plugin exec_plugin is not installed")
+	assert.EqualError(t, r.Remove("exec_plugin"), "plugin exec_plugin is not installed")
+}
+
+func TestRegistry_Verify(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+
+	m, err := r.Install(testPlugin, Manifest{Name: "tampered"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(m.FullPath(), []byte("#!/bin/sh\necho '{\"name\":\"evil\"}'\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Verify()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch for plugin tampered: expected "+m.Checksum)
+
+	// Tampered plugins must not be opened
+	assert.Equal(t, []error{err}, r.Load())
+}
+
+func TestRegistry_Load(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+	t.Cleanup(remote.RemovePluginCopies)
+
+	_, err := r.Install(testPlugin, Manifest{Name: "execscanner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, r.Load(), 0)
+}
+
+func TestManifest_Load(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+	t.Cleanup(remote.RemovePluginCopies)
+
+	m, err := r.Install(testPlugin, Manifest{Name: "execscanner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, m.Load())
+	plugins := remote.Plugins()
+	s := plugins[len(plugins)-1]
+
+	// The plugin runs from the verified content, whatever the file becomes
+	err = os.WriteFile(m.FullPath(), []byte("#!/bin/sh\necho '{\"result\":\"tampered\"}'\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Run(*test.NewFakeUSNumber(), remote.ScannerOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"valid": true, "info": "This number is known for scams!"}, got)
+}
+
+func TestRegistry_LoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRegistry(dir)
+	t.Cleanup(remote.RemovePluginCopies)
+
+	tampered, err := r.Install(testPlugin, Manifest{Name: "a_tampered"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tampered.FullPath(), []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b_broken.yaml"), []byte("name: ["), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Install(testPlugin, Manifest{Name: "c_execscanner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Broken manifests don't prevent next plugins from loading
+	errs := r.Load()
+	if assert.Len(t, errs, 2) {
+		assert.ErrorContains(t, errs[0], "checksum mismatch for plugin a_tampered")
+		assert.ErrorContains(t, errs[1], "plugin manifest "+filepath.Join(dir, "b_broken.yaml")+" is not valid")
+	}
+}
+
+func TestRegistry_InvalidManifests(t *testing.T) {
+	testcases := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{
+			name:     "test invalid yaml",
+			manifest: "name: [",
+			wantErr:  "plugin manifest %s is not valid: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:     "test missing path",
+			manifest: "name: foo",
+			wantErr:  "plugin manifest %s must define a name and a path",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "foo.yaml")
+			if err := os.WriteFile(path, []byte(tt.manifest), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := NewRegistry(dir).List()
+			assert.EqualError(t, err, fmt.Sprintf(tt.wantErr, path))
+		})
+	}
+}
+
+func TestRegistry_InvalidName(t *testing.T) {
+	_, err := NewRegistry(t.TempDir()).Install(testPlugin, Manifest{Name: "../foo"})
+	assert.EqualError(t, err, "plugin name \"../foo\" is not valid")
+}
+
+func TestRegistry_Traversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "plugins")
+	r := NewRegistry(dir)
+	t.Cleanup(remote.RemovePluginCopies)
+	if _, err := r.Install(testPlugin, Manifest{Name: "execscanner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files next to the plugins directory must be left untouched
+	outside := filepath.Join(root, "x.yaml")
+	if err := os.WriteFile(outside, []byte("name: x\npath: x.yaml\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"../x", `..\x`, "", ".hidden"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := r.Get(name)
+			assert.EqualError(t, err, fmt.Sprintf("plugin name %q is not valid", name))
+			assert.EqualError(t, r.Remove(name), fmt.Sprintf("plugin name %q is not valid", name))
+		})
+	}
+
+	t.Run("test relative path escaping the directory", func(t *testing.T) {
+		manifest := filepath.Join(dir, "escape.yaml")
+		if err := os.WriteFile(manifest, []byte("name: escape\npath: ../x.yaml\nchecksum: sha256:0\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		wantErr := fmt.Sprintf("plugin manifest %s: path ../x.yaml is outside of the plugins directory", manifest)
+		_, err := r.Get("escape")
+		assert.EqualError(t, err, wantErr)
+		assert.EqualError(t, r.Remove("escape"), wantErr)
+		assert.ErrorContains(t, errors.Join(r.Load()...), wantErr)
+	})
+
+	t.Run("test manifest with invalid name", func(t *testing.T) {
+		manifest := filepath.Join(dir, "named.yaml")
+		if err := os.WriteFile(manifest, []byte("name: ../x\npath: named.sh\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(manifest)
+
+		_, err := r.Get("named")
+		assert.EqualError(t, err, fmt.Sprintf("plugin manifest %s: plugin name \"../x\" is not valid", manifest))
+	})
+
+	_, err := os.Stat(outside)
+	assert.NoError(t, err)
+}
+
+func TestDefaultDir(t *testing.T) {
+	_ = os.Setenv(DirEnv, "/tmp/plugins")
+	defer os.Unsetenv(DirEnv)
+
+	assert.Equal(t, "/tmp/plugins", DefaultDir())
+	assert.Equal(t, "/tmp/plugins", NewRegistry("").Dir())
+}
@@ -0,0 +1,221 @@
+// Package ratelimit limits requests of clients per route, with a token
+// bucket for bursts and a daily quota reset at midnight UTC.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+)
+
+// Rule limits requests to routes matching the given pattern.
+// Patterns are globs matched against route templates such as
+// "/api/v2/scanners/:scanner/run", see path.Match.
+type Rule struct {
+	Route string `yaml:"route"`
+	// Requests allowed per period, requests aren't rate limited when it's 0
+	Requests int           `yaml:"requests,omitempty"`
+	Per      time.Duration `yaml:"per,omitempty"`
+	// Burst is the number of requests allowed at once (default Requests)
+	Burst int `yaml:"burst,omitempty"`
+	// DailyQuota is the number of requests allowed per day, unlimited when it's 0
+	DailyQuota int `yaml:"daily_quota,omitempty"`
+}
+
+func (r *Rule) validate() error {
+	if r.Route == "" {
+		return errors.New("rate limit rules must have a route")
+	}
+	if _, err := path.Match(r.Route, ""); err != nil {
+		return fmt.Errorf("invalid rate limit route %q: %v", r.Route, err)
+	}
+	if r.Requests < 0 || r.Burst < 0 || r.DailyQuota < 0 {
+		return fmt.Errorf("rate limit of route %s must not be negative", r.Route)
+	}
+	if r.Requests > 0 && r.Per <= 0 {
+		return fmt.Errorf("rate limit of route %s must have a period", r.Route)
+	}
+	return nil
+}
+
+func (r *Rule) burst() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Requests)
+}
+
+// Result is the outcome of a request
+type Result struct {
+	Allowed bool
+	// RetryAfter is the time to wait before retrying a rejected request
+	RetryAfter time.Duration
+	// QuotaExceeded is true when the request was rejected because of the daily quota
+	QuotaExceeded bool
+	// Quota is the daily quota of the route, 0 when unlimited
+	Quota          int
+	QuotaRemaining int
+	QuotaReset     time.Time
+}
+
+type client struct {
+	rule   *Rule
+	tokens float64
+	last   time.Time
+	day    time.Time
+	used   int
+}
+
+type Limiter struct {
+	rules []Rule
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewLimiter(rules ...Rule) (*Limiter, error) {
+	for i := range rules {
+		if err := rules[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Limiter{
+		rules:   rules,
+		clients: map[string]*client{},
+		now:     time.Now,
+	}, nil
+}
+
+// Len returns the number of rules
+func (l *Limiter) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.rules)
+}
+
+// Rule returns the first rule matching the given route
+func (l *Limiter) Rule(route string) (*Rule, bool) {
+	for i := range l.rules {
+		if ok, _ := path.Match(l.rules[i].Route, route); ok {
+			return &l.rules[i], true
+		}
+	}
+	return nil, false
+}
+
+// Allow counts a request of the client to the route. Requests to
+// routes that don't match any rule are always allowed.
+func (l *Limiter) Allow(route, clientID string) Result {
+	rule, ok := l.Rule(route)
+	if !ok {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	key := rule.Route + "\x00" + clientID
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{rule: rule, tokens: rule.burst(), last: now}
+		l.clients[key] = c
+	}
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !c.day.Equal(day) {
+		c.day = day
+		c.used = 0
+	}
+
+	res := Result{Allowed: true, Quota: rule.DailyQuota}
+	if rule.DailyQuota > 0 {
+		res.QuotaReset = day.Add(24 * time.Hour)
+		if c.used >= rule.DailyQuota {
+			res.Allowed = false
+			res.QuotaExceeded = true
+			res.RetryAfter = res.QuotaReset.Sub(now)
+			return res
+		}
+	}
+
+	if rule.Requests > 0 {
+		c.tokens = c.tokensAt(now)
+		c.last = now
+
+		if c.tokens < 1 {
+			rate := float64(rule.Requests) / rule.Per.Seconds()
+			res.Allowed = false
+			res.RetryAfter = time.Duration((1 - c.tokens) / rate * float64(time.Second))
+			if rule.DailyQuota > 0 {
+				res.QuotaRemaining = rule.DailyQuota - c.used
+			}
+			return res
+		}
+		c.tokens--
+	}
+
+	c.used++
+	if rule.DailyQuota > 0 {
+		res.QuotaRemaining = rule.DailyQuota - c.used
+	}
+	return res
+}
+
+// Wait blocks until a request of the client to the route is allowed, or
+// the context is done. It returns an error once the daily quota is exceeded.
+func (l *Limiter) Wait(ctx context.Context, route, clientID string) error {
+	for {
+		res := l.Allow(route, clientID)
+		if res.Allowed {
+			return nil
+		}
+		if res.QuotaExceeded {
+			return fmt.Errorf("daily quota of %d requests exceeded", res.Quota)
+		}
+
+		t := time.NewTimer(res.RetryAfter)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// sweep forgets clients whose bucket is full and whose quota was reset,
+// as they'd start over from the same state
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	for key, c := range l.clients {
+		full := c.rule.Requests == 0 || c.tokensAt(now) >= c.rule.burst()
+		// Quota of the current day must be kept
+		if full && (c.used == 0 || !c.day.Equal(day)) {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// tokensAt returns tokens of the bucket, refilled for the time elapsed since the last request
+func (c *client) tokensAt(now time.Time) float64 {
+	rate := float64(c.rule.Requests) / c.rule.Per.Seconds()
+	tokens := c.tokens + now.Sub(c.last).Seconds()*rate
+	if tokens > c.rule.burst() {
+		return c.rule.burst()
+	}
+	return tokens
+}
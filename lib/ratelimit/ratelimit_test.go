@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l, err := NewLimiter(
+		Rule{Route: "/api/v2/scanners/:scanner/run", Requests: 2, Per: time.Minute},
+		Rule{Route: "/api/v2/scans", Requests: 60, Per: time.Minute, Burst: 1, DailyQuota: 3},
+	)
+	assert.NoError(t, err)
+
+	now := time.Date(2023, 1, 1, 23, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	// Routes without rules
+	assert.Equal(t, Result{Allowed: true}, l.Allow("/api/", "client"))
+
+	// Burst, then refill
+	assert.True(t, l.Allow("/api/v2/scanners/:scanner/run", "client").Allowed)
+	assert.True(t, l.Allow("/api/v2/scanners/:scanner/run", "client").Allowed)
+	res := l.Allow("/api/v2/scanners/:scanner/run", "client")
+	assert.Equal(t, Result{Allowed: false, RetryAfter: 30 * time.Second}, res)
+	assert.True(t, l.Allow("/api/v2/scanners/:scanner/run", "other").Allowed)
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 20*time.Second, l.Allow("/api/v2/scanners/:scanner/run", "client").RetryAfter.Round(time.Second))
+	now = now.Add(20 * time.Second)
+	assert.True(t, l.Allow("/api/v2/scanners/:scanner/run", "client").Allowed)
+
+	// Daily quota
+	reset := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := 2; i >= 0; i-- {
+		now = now.Add(time.Second)
+		res := l.Allow("/api/v2/scans", "client")
+		assert.Equal(t, Result{Allowed: true, Quota: 3, QuotaRemaining: i, QuotaReset: reset}, res)
+	}
+	now = now.Add(time.Second)
+	res = l.Allow("/api/v2/scans", "client")
+	assert.False(t, res.Allowed)
+	assert.True(t, res.QuotaExceeded)
+	assert.Equal(t, reset.Sub(now), res.RetryAfter)
+
+	// Rate limited requests don't count in the quota
+	res = l.Allow("/api/v2/scans", "other")
+	assert.True(t, res.Allowed)
+	res = l.Allow("/api/v2/scans", "other")
+	assert.False(t, res.Allowed)
+	assert.False(t, res.QuotaExceeded)
+	assert.Equal(t, 2, res.QuotaRemaining)
+
+	// Quota is reset the next day
+	now = reset.Add(time.Second)
+	res = l.Allow("/api/v2/scans", "client")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.QuotaRemaining)
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l, err := NewLimiter(Rule{Route: "/api/*", Requests: 1, Per: time.Second, DailyQuota: 10})
+	assert.NoError(t, err)
+
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	l.Allow("/api/scans", "client")
+	assert.Len(t, l.clients, 1)
+
+	// Quota of the day is kept
+	now = now.Add(time.Hour)
+	l.Allow("/api/scans", "other")
+	assert.Len(t, l.clients, 2)
+
+	now = now.Add(24 * time.Hour)
+	l.Allow("/api/scans", "other")
+	assert.Len(t, l.clients, 1)
+}
+
+func TestNewLimiter_Invalid(t *testing.T) {
+	testcases := []struct {
+		name    string
+		rule    Rule
+		wantErr string
+	}{
+		{name: "test missing route", rule: Rule{Requests: 1, Per: time.Second}, wantErr: "rate limit rules must have a route"},
+		{name: "test invalid route", rule: Rule{Route: "["}, wantErr: `invalid rate limit route "[": syntax error in pattern`},
+		{name: "test missing period", rule: Rule{Route: "/api/*", Requests: 1}, wantErr: "rate limit of route /api/* must have a period"},
+		{name: "test negative quota", rule: Rule{Route: "/api/*", DailyQuota: -1}, wantErr: "rate limit of route /api/* must not be negative"},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLimiter(tt.rule)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l, err := NewLimiter(
+		Rule{Route: "fetch", Requests: 20, Per: time.Second, Burst: 1},
+		Rule{Route: "quota", DailyQuota: 1},
+	)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, l.Wait(context.Background(), "fetch", "plugin"))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, l.Wait(ctx, "fetch", "plugin"))
+
+	assert.NoError(t, l.Wait(context.Background(), "quota", "plugin"))
+	assert.EqualError(t, l.Wait(context.Background(), "quota", "plugin"), "daily quota of 1 requests exceeded")
+}
@@ -0,0 +1,226 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+)
+
+// Methods of the exec plugin protocol. Each call spawns the plugin
+// executable, writes a single ExecRequest as JSON on its standard input,
+// and reads a single ExecResponse as JSON from its standard output.
+// Anything written on standard error is forwarded to debug logs.
+const (
+	ExecMethodDescribe = "describe"
+	ExecMethodDryRun   = "dry_run"
+	ExecMethodRun      = "run"
+)
+
+// protocolCallTimeout is the maximum duration of describe and dry run
+// calls, scans are bound by the context they're given
+const protocolCallTimeout = 10 * time.Second
+
+// ExecNumber is the phone number sent to exec plugins
+type ExecNumber struct {
+	Valid         bool   `json:"valid"`
+	RawLocal      string `json:"raw_local"`
+	Local         string `json:"local"`
+	E164          string `json:"e164"`
+	International string `json:"international"`
+	CountryCode   int32  `json:"country_code"`
+	Country       string `json:"country"`
+	Carrier       string `json:"carrier"`
+}
+
+// ExecRequest is written to the standard input of exec plugins
+type ExecRequest struct {
+	Method  string         `json:"method"`
+	Number  *ExecNumber    `json:"number,omitempty"`
+	Options ScannerOptions `json:"options,omitempty"`
+}
+
+// ExecResponse is read from the standard output of exec plugins.
+// Name, Description, Options and Tags are only expected for the describe method.
+type ExecResponse struct {
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Options     []ScannerOption        `json:"options,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Result      map[string]interface{} `json:"result,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+// pluginCaller sends a single request to an out-of-process plugin
+type pluginCaller interface {
+	call(context.Context, ExecRequest) (*ExecResponse, error)
+}
+
+// protocolScanner adapts plugins speaking the exec plugin protocol to the Scanner interface
+type protocolScanner struct {
+	caller      pluginCaller
+	name        string
+	description string
+	options     []ScannerOption
+	tags        []string
+}
+
+type execScanner struct {
+	path string
+}
+
+// NewExecScanner describes the plugin executable at the given
+// path and returns a scanner calling it for every dry run and scan.
+func NewExecScanner(path string) (Scanner, error) {
+	return newProtocolScanner(path, &execScanner{path: path})
+}
+
+func newProtocolScanner(path string, caller pluginCaller) (Scanner, error) {
+	s := &protocolScanner{caller: caller}
+
+	ctx, cancel := context.WithTimeout(context.Background(), protocolCallTimeout)
+	defer cancel()
+
+	res, err := caller.call(ctx, ExecRequest{Method: ExecMethodDescribe})
+	if err != nil {
+		return nil, err
+	}
+	if res.Name == "" {
+		return nil, fmt.Errorf("plugin %s did not describe its name", path)
+	}
+
+	s.name = res.Name
+	s.description = res.Description
+	s.options = res.Options
+	s.tags = res.Tags
+
+	return s, nil
+}
+
+func (s *protocolScanner) Name() string {
+	return s.name
+}
+
+func (s *protocolScanner) Description() string {
+	return s.description
+}
+
+func (s *protocolScanner) Options() []ScannerOption {
+	return s.options
+}
+
+func (s *protocolScanner) Tags() []string {
+	return s.tags
+}
+
+func (s *protocolScanner) DryRun(n number.Number, opts ScannerOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), protocolCallTimeout)
+	defer cancel()
+
+	res, err := s.caller.call(ctx, s.request(ExecMethodDryRun, n, opts))
+	if err != nil {
+		return err
+	}
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+	return nil
+}
+
+func (s *protocolScanner) Run(n number.Number, opts ScannerOptions) (interface{}, error) {
+	return s.RunContext(context.Background(), n, opts)
+}
+
+func (s *protocolScanner) RunContext(ctx context.Context, n number.Number, opts ScannerOptions) (interface{}, error) {
+	res, err := s.caller.call(ctx, s.request(ExecMethodRun, n, opts))
+	if err != nil {
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	if res.Result == nil {
+		return nil, nil
+	}
+	return res.Result, nil
+}
+
+// request builds a request for the plugin. Options declared by the
+// plugin are resolved from environment variables when not given.
+func (s *protocolScanner) request(method string, n number.Number, opts ScannerOptions) ExecRequest {
+	resolved := ScannerOptions{}
+	for k, v := range opts {
+		resolved[k] = v
+	}
+	for _, o := range s.options {
+		if v := opts.GetStringEnv(o.Name); v != "" {
+			resolved[o.Name] = v
+		}
+	}
+
+	return ExecRequest{
+		Method: method,
+		Number: &ExecNumber{
+			Valid:         n.Valid,
+			RawLocal:      n.RawLocal,
+			Local:         n.Local,
+			E164:          n.E164,
+			International: n.International,
+			CountryCode:   n.CountryCode,
+			Country:       n.Country,
+			Carrier:       n.Carrier,
+		},
+		Options: resolved,
+	}
+}
+
+func (s *execScanner) call(ctx context.Context, req ExecRequest) (*ExecResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	forwardPluginLogs(logs.FromContext(ctx).WithField("plugin", s.path), &stderr)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %v", s.path, err)
+	}
+
+	return decodePluginResponse(s.path, stdout.Bytes())
+}
+
+func decodePluginResponse(path string, data []byte) (*ExecResponse, error) {
+	var res ExecResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("plugin %s returned an invalid response: %v", path, err)
+	}
+	return &res, nil
+}
+
+func forwardPluginLogs(logger *logrus.Entry, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			logger.Debug(line)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote/remotetest"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+)
+
+func TestExecScanner_Metadata(t *testing.T) {
+	scanner, err := remote.NewExecScanner("testdata/exec_plugin.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "execscanner", scanner.Name())
+	assert.Equal(t, "Dummy exec scanner", scanner.Description())
+	assert.Equal(t, []remote.ScannerOption{
+		{Name: "EXEC_API_KEY", Required: true, Secret: true},
+	}, scanner.(remote.OptionsScanner).Options())
+	assert.Equal(t, []string{"network"}, scanner.(remote.TaggedScanner).Tags())
+}
+
+func TestExecScanner_InvalidPlugin(t *testing.T) {
+	_, err := remote.NewExecScanner("testdata/exec_invalid.sh")
+	assert.EqualError(t, err, "plugin testdata/exec_invalid.sh returned an invalid response: invalid character 'o' in literal null (expecting 'u')")
+
+	err = remote.OpenPlugin("testdata/invalid.so.txt")
+	assert.EqualError(t, err, "given path testdata/invalid.so.txt does not exist")
+
+	err = remote.OpenPlugin("testdata")
+	assert.EqualError(t, err, "given plugin testdata is not executable")
+}
+
+func TestExecScanner(t *testing.T) {
+	testcases := []struct {
+		name       string
+		number     *number.Number
+		opts       remote.ScannerOptions
+		env        map[string]string
+		expected   map[string]interface{}
+		wantErrors map[string]error
+	}{
+		{
+			name:   "successful scan",
+			number: test.NewFakeUSNumber(),
+			opts:   remote.ScannerOptions{"EXEC_API_KEY": "secret"},
+			expected: map[string]interface{}{
+				"execscanner": map[string]interface{}{
+					"valid": true,
+					"info":  "This number is known for scams!",
+				},
+			},
+			wantErrors: map[string]error{},
+		},
+		{
+			name:   "successful scan with env var",
+			number: test.NewFakeUSNumber(),
+			env:    map[string]string{"EXEC_API_KEY": "secret"},
+			expected: map[string]interface{}{
+				"execscanner": map[string]interface{}{
+					"valid": true,
+					"info":  "This number is known for scams!",
+				},
+			},
+			wantErrors: map[string]error{},
+		},
+		{
+			name: "failed scan",
+			number: func() *number.Number {
+				n, _ := number.NewNumber("15556661212")
+				return n
+			}(),
+			opts:     remote.ScannerOptions{"EXEC_API_KEY": "secret"},
+			expected: map[string]interface{}{},
+			wantErrors: map[string]error{
+				"execscanner": errors.New("number is blocked"),
+			},
+		},
+		{
+			name:       "should not run",
+			number:     test.NewFakeUSNumber(),
+			expected:   map[string]interface{}{},
+			wantErrors: map[string]error{},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				_ = os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			scanner, err := remote.NewExecScanner("testdata/exec_plugin.sh")
+			if err != nil {
+				t.Fatal(err)
+			}
+			lib := remote.NewLibrary(filter.NewEngine())
+			lib.AddScanner(scanner)
+
+			got, errs := lib.Scan(tt.number, tt.opts)
+			assert.Equal(t, tt.wantErrors, errs)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestExecScanner_Cancellation(t *testing.T) {
+	scanner, err := remote.NewExecScanner("testdata/exec_plugin.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = remote.RunContext(ctx, scanner, *test.NewFakeUSNumber(), remote.ScannerOptions{"EXEC_API_KEY": "secret"})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestExecScanner_Conformance(t *testing.T) {
+	scanner, err := remote.NewExecScanner("testdata/exec_plugin.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remotetest.TestScanner(t, scanner, remotetest.Config{
+		Options:    remote.ScannerOptions{"EXEC_API_KEY": "secret"},
+		MapResults: true,
+	})
+}
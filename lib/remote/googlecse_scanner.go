@@ -9,6 +9,7 @@ import (
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"google.golang.org/api/customsearch/v1"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/googleapi/transport"
 	"google.golang.org/api/option"
 	"net/http"
 	"os"
@@ -17,6 +18,8 @@ import (
 
 const GoogleCSE = "googlecse"
 
+const googleCSEBaseURL = "https://customsearch.googleapis.com"
+
 type googleCSEScanner struct {
 	MaxResults int64
 	httpClient *http.Client
@@ -63,6 +66,22 @@ func (s *googleCSEScanner) Description() string {
 	return "Googlecse searches for footprints of a given phone number on the web using Google Custom Search Engine."
 }
 
+func (s *googleCSEScanner) Tags() []string {
+	return []string{TagNetwork, TagPaid}
+}
+
+func (s *googleCSEScanner) Options() []ScannerOption {
+	return []ScannerOption{
+		{Name: "GOOGLECSE_CX", Description: "ID of the Google Custom Search Engine", Required: true},
+		{Name: "GOOGLE_API_KEY", Description: "Google Cloud API key with the Custom Search API enabled", Required: true, Secret: true},
+		{Name: "GOOGLECSE_MAX_RESULTS", Description: "Maximum number of results per dork, up to 100 (default 10)"},
+	}
+}
+
+func (s *googleCSEScanner) Endpoints() []string {
+	return []string{googleCSEBaseURL}
+}
+
 func (s *googleCSEScanner) DryRun(_ number.Number, opts ScannerOptions) error {
 	if opts.GetStringEnv("GOOGLECSE_CX") == "" || opts.GetStringEnv("GOOGLE_API_KEY") == "" {
 		return errors.New("search engine ID and/or API key is not defined")
@@ -71,6 +90,10 @@ func (s *googleCSEScanner) DryRun(_ number.Number, opts ScannerOptions) error {
 }
 
 func (s *googleCSEScanner) Run(n number.Number, opts ScannerOptions) (interface{}, error) {
+	return s.RunContext(context.Background(), n, opts)
+}
+
+func (s *googleCSEScanner) RunContext(ctx context.Context, n number.Number, opts ScannerOptions) (interface{}, error) {
 	var allItems []*customsearch.Result
 	var dorks []*GoogleSearchDork
 	var totalResultCount int
@@ -78,19 +101,26 @@ func (s *googleCSEScanner) Run(n number.Number, opts ScannerOptions) (interface{
 	var cx = opts.GetStringEnv("GOOGLECSE_CX")
 	var apikey = opts.GetStringEnv("GOOGLE_API_KEY")
 
-	dorks = append(dorks, s.generateDorkQueries(n)...)
+	dorks = append(dorks, GoogleCSEDorks(n)...)
+
+	// Requests go through the supplier transport chain by default,
+	// so they can be recorded or replayed like other suppliers
+	httpClient := s.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Transport: &transport.APIKey{Key: apikey, Transport: Transport()}}
+	}
 
 	customsearchService, err := customsearch.NewService(
-		context.Background(),
+		ctx,
 		option.WithAPIKey(apikey),
-		option.WithHTTPClient(s.httpClient),
+		option.WithHTTPClient(httpClient),
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, req := range dorks {
-		n, items, err := s.search(customsearchService, req.Dork, cx)
+		n, items, err := s.search(ctx, customsearchService, req.Dork, cx)
 		if err != nil {
 			if s.isRateLimit(err) {
 				return nil, errors.New("rate limit exceeded, see https://developers.google.com/custom-search/v1/overview#pricing")
@@ -117,13 +147,13 @@ func (s *googleCSEScanner) Run(n number.Number, opts ScannerOptions) (interface{
 	return data, nil
 }
 
-func (s *googleCSEScanner) search(service *customsearch.Service, q string, cx string) (int, []*customsearch.Result, error) {
+func (s *googleCSEScanner) search(ctx context.Context, service *customsearch.Service, q string, cx string) (int, []*customsearch.Result, error) {
 	var results []*customsearch.Result
 	var totalResultCount int
 
 	offset := int64(0)
 	for offset < s.MaxResults {
-		search := service.Cse.List()
+		search := service.Cse.List().Context(ctx)
 		search.Cx(cx)
 		search.Q(q)
 		search.Start(offset)
@@ -159,7 +189,8 @@ func (s *googleCSEScanner) isRateLimit(theError error) bool {
 	return true
 }
 
-func (s *googleCSEScanner) generateDorkQueries(number number.Number) (results []*GoogleSearchDork) {
+// GoogleCSEDorks returns dorks the googlecse scanner requests results for
+func GoogleCSEDorks(number number.Number) (results []*GoogleSearchDork) {
 	var dorks = []*googlesearch.GoogleSearch{
 		dorkgen.NewGoogleSearch().
 			InText(number.International).
@@ -208,6 +239,7 @@ func (s *googleCSEScanner) generateDorkQueries(number number.Number) (results []
 	for _, dork := range dorks {
 		results = append(results, &GoogleSearchDork{
 			Number: number.E164,
+			Engine: "google",
 			Dork:   dork.String(),
 			URL:    dork.URL(),
 		})
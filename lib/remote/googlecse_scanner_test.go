@@ -18,6 +18,8 @@ func TestGoogleCSEScanner_Metadata(t *testing.T) {
 	scanner := NewGoogleCSEScanner(&http.Client{})
 	assert.Equal(t, GoogleCSE, scanner.Name())
 	assert.NotEmpty(t, scanner.Description())
+	assert.Len(t, scanner.(OptionsScanner).Options(), 3)
+	assert.Equal(t, []string{"https://customsearch.googleapis.com"}, scanner.(EndpointsScanner).Endpoints())
 }
 
 func TestGoogleCSEScanner_Scan_Success(t *testing.T) {
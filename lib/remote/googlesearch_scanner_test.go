@@ -32,26 +32,31 @@ func TestGoogleSearchScanner(t *testing.T) {
 					SocialMedia: []*remote.GoogleSearchDork{
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:facebook.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Afacebook.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:twitter.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Atwitter.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:linkedin.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Alinkedin.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:instagram.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Ainstagram.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:vk.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Avk.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
@@ -59,105 +64,126 @@ func TestGoogleSearchScanner(t *testing.T) {
 					DisposableProviders: []*remote.GoogleSearchDork{
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:hs3x.com intext:\"15556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Ahs3x.com+intext%3A%2215556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receive-sms-now.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceive-sms-now.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:smslisten.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asmslisten.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:smsnumbersonline.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asmsnumbersonline.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:freesmscode.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Afreesmscode.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						}, {
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:catchsms.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Acatchsms.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:smstibo.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asmstibo.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:smsreceiving.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asmsreceiving.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:getfreesmsnumber.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Agetfreesmsnumber.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:sellaite.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asellaite.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receive-sms-online.info intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceive-sms-online.info+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receivesmsonline.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceivesmsonline.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receive-a-sms.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceive-a-sms.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:sms-receive.net intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asms-receive.net+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receivefreesms.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceivefreesms.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receive-sms.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceive-sms.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receivetxt.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceivetxt.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:freephonenum.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Afreephonenum.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:freesmsverification.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Afreesmsverification.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:receive-sms-online.com intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Areceive-sms-online.com+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:smslive.co intext:\"15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Asmslive.co+intext%3A%2215556661212%22+%7C+intext%3A%225556661212%22",
 						},
@@ -165,51 +191,61 @@ func TestGoogleSearchScanner(t *testing.T) {
 					Reputation: []*remote.GoogleSearchDork{
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:whosenumber.info intext:\"+15556661212\" intitle:\"who called\"",
 							URL:    "https://www.google.com/search?q=site%3Awhosenumber.info+intext%3A%22%2B15556661212%22+intitle%3A%22who+called%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "intitle:\"Phone Fraud\" intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=intitle%3A%22Phone+Fraud%22+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:findwhocallsme.com intext:\"+15556661212\" | intext:\"15556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Afindwhocallsme.com+intext%3A%22%2B15556661212%22+%7C+intext%3A%2215556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:yellowpages.ca intext:\"+15556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Ayellowpages.ca+intext%3A%22%2B15556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:phonenumbers.ie intext:\"+15556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Aphonenumbers.ie+intext%3A%22%2B15556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:who-calledme.com intext:\"+15556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Awho-calledme.com+intext%3A%22%2B15556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:usphonesearch.net intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Ausphonesearch.net+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:whocalled.us inurl:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Awhocalled.us+inurl%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:quinumero.info intext:\"5556661212\" | intext:\"15556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Aquinumero.info+intext%3A%225556661212%22+%7C+intext%3A%2215556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:uk.popularphotolook.com inurl:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Auk.popularphotolook.com+inurl%3A%225556661212%22",
 						},
@@ -217,36 +253,43 @@ func TestGoogleSearchScanner(t *testing.T) {
 					Individuals: []*remote.GoogleSearchDork{
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:numinfo.net intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Anuminfo.net+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:sync.me intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Async.me+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:whocallsyou.de intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Awhocallsyou.de+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:pastebin.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Apastebin.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:whycall.me intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Awhycall.me+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:locatefamily.com intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Alocatefamily.com+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "site:spytox.com intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=site%3Aspytox.com+intext%3A%225556661212%22",
 						},
@@ -254,11 +297,13 @@ func TestGoogleSearchScanner(t *testing.T) {
 					General: []*remote.GoogleSearchDork{
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\" | intext:\"(555) 666-1212\"",
 							URL:    "https://www.google.com/search?q=intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22+%7C+intext%3A%22%28555%29+666-1212%22",
 						},
 						{
 							Number: "+15556661212",
+							Engine: "google",
 							Dork:   "(ext:doc | ext:docx | ext:odt | ext:pdf | ext:rtf | ext:sxw | ext:psw | ext:ppt | ext:pptx | ext:pps | ext:csv | ext:txt | ext:xls) intext:\"15556661212\" | intext:\"+15556661212\" | intext:\"5556661212\"",
 							URL:    "https://www.google.com/search?q=%28ext%3Adoc+%7C+ext%3Adocx+%7C+ext%3Aodt+%7C+ext%3Apdf+%7C+ext%3Artf+%7C+ext%3Asxw+%7C+ext%3Apsw+%7C+ext%3Appt+%7C+ext%3Apptx+%7C+ext%3Apps+%7C+ext%3Acsv+%7C+ext%3Atxt+%7C+ext%3Axls%29+intext%3A%2215556661212%22+%7C+intext%3A%22%2B15556661212%22+%7C+intext%3A%225556661212%22",
 						},
@@ -289,3 +334,46 @@ func TestGoogleSearchScanner(t *testing.T) {
 		})
 	}
 }
+
+func TestGoogleSearchScanner_Engines(t *testing.T) {
+	n, err := number.NewNumber("15556661212")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := remote.NewGoogleSearchScanner()
+
+	t.Run("test dorks are generated for each engine", func(t *testing.T) {
+		opts := remote.ScannerOptions{"GOOGLESEARCH_ENGINES": "bing,yandex"}
+		assert.Nil(t, scanner.DryRun(*n, opts))
+
+		got, err := scanner.Run(*n, opts)
+		assert.Nil(t, err)
+
+		res := got.(remote.GoogleSearchResponse)
+		assert.Len(t, res.SocialMedia, 10)
+		assert.Equal(t, []*remote.GoogleSearchDork{
+			{
+				Number: "+15556661212",
+				Engine: "bing",
+				Dork:   "site:facebook.com inbody:\"15556661212\" OR inbody:\"+15556661212\" OR inbody:\"5556661212\"",
+				URL:    "https://www.bing.com/search?q=site%3Afacebook.com+inbody%3A%2215556661212%22+OR+inbody%3A%22%2B15556661212%22+OR+inbody%3A%225556661212%22",
+			},
+			{
+				Number: "+15556661212",
+				Engine: "yandex",
+				Dork:   "site:facebook.com \"15556661212\" | \"+15556661212\" | \"5556661212\"",
+				URL:    "https://yandex.com/search/?text=site%3Afacebook.com+%2215556661212%22+%7C+%22%2B15556661212%22+%7C+%225556661212%22",
+			},
+		}, res.SocialMedia[:2])
+		assert.Len(t, res.General, 4)
+	})
+
+	t.Run("test unknown engine", func(t *testing.T) {
+		opts := remote.ScannerOptions{"GOOGLESEARCH_ENGINES": "altavista"}
+		assert.EqualError(t, scanner.DryRun(*n, opts), `unknown search engine "altavista", supported engines are baidu, bing, duckduckgo, google, yandex`)
+
+		got, err := scanner.Run(*n, opts)
+		assert.Nil(t, got)
+		assert.NotNil(t, err)
+	})
+}
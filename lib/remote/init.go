@@ -6,7 +6,9 @@ import (
 
 func InitScanners(remote *Library) {
 	numverifySupplier := suppliers.NewNumverifySupplier()
+	numverifySupplier.Client = HTTPClient()
 	ovhSupplier := suppliers.NewOVHSupplier()
+	ovhSupplier.Client = HTTPClient()
 
 	remote.AddScanner(NewLocalScanner())
 	remote.AddScanner(NewNumverifyScanner(numverifySupplier))
@@ -30,6 +30,10 @@ func (s *localScanner) Description() string {
 	return "Gather offline info about a given phone number."
 }
 
+func (s *localScanner) Tags() []string {
+	return []string{TagOffline}
+}
+
 func (s *localScanner) DryRun(_ number.Number, _ ScannerOptions) error {
 	return nil
 }
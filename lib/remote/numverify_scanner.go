@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"context"
 	"errors"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote/suppliers"
@@ -37,6 +38,23 @@ func (s *numverifyScanner) Description() string {
 	return "Request info about a given phone number through the Numverify API."
 }
 
+func (s *numverifyScanner) Tags() []string {
+	return []string{TagNetwork, TagPaid}
+}
+
+func (s *numverifyScanner) Options() []ScannerOption {
+	return []ScannerOption{
+		{Name: "NUMVERIFY_API_KEY", Description: "API key of the Numverify API, from apilayer.com", Required: true, Secret: true},
+	}
+}
+
+func (s *numverifyScanner) Endpoints() []string {
+	if c, ok := s.client.(*suppliers.NumverifySupplier); ok {
+		return []string{c.Uri}
+	}
+	return nil
+}
+
 func (s *numverifyScanner) DryRun(_ number.Number, opts ScannerOptions) error {
 	if opts.GetStringEnv("NUMVERIFY_API_KEY") != "" {
 		return nil
@@ -45,9 +63,17 @@ func (s *numverifyScanner) DryRun(_ number.Number, opts ScannerOptions) error {
 }
 
 func (s *numverifyScanner) Run(n number.Number, opts ScannerOptions) (interface{}, error) {
+	return s.RunContext(context.Background(), n, opts)
+}
+
+func (s *numverifyScanner) RunContext(ctx context.Context, n number.Number, opts ScannerOptions) (interface{}, error) {
 	apiKey := opts.GetStringEnv("NUMVERIFY_API_KEY")
 
-	res, err := s.client.Request().SetApiKey(apiKey).ValidateNumber(n.International)
+	req := s.client.Request().SetApiKey(apiKey)
+	if r, ok := req.(suppliers.NumverifyContextRequest); ok {
+		req = r.SetContext(ctx)
+	}
+	res, err := req.ValidateNumber(n.International)
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,13 @@ func TestNumverifyScanner_Metadata(t *testing.T) {
 	scanner := remote.NewNumverifyScanner(&mocks.NumverifySupplier{})
 	assert.Equal(t, remote.Numverify, scanner.Name())
 	assert.NotEmpty(t, scanner.Description())
+	assert.Equal(t, []remote.ScannerOption{
+		{Name: "NUMVERIFY_API_KEY", Description: "API key of the Numverify API, from apilayer.com", Required: true, Secret: true},
+	}, scanner.(remote.OptionsScanner).Options())
+	assert.Empty(t, scanner.(remote.EndpointsScanner).Endpoints())
+
+	scanner = remote.NewNumverifyScanner(suppliers.NewNumverifySupplier())
+	assert.Equal(t, []string{"https://api.apilayer.com"}, scanner.(remote.EndpointsScanner).Endpoints())
 }
 
 func TestNumverifyScanner(t *testing.T) {
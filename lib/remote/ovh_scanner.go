@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"context"
 	"fmt"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote/suppliers"
@@ -32,6 +33,14 @@ func (s *ovhScanner) Description() string {
 	return "Search a phone number through the OVH Telecom REST API."
 }
 
+func (s *ovhScanner) Tags() []string {
+	return []string{TagNetwork}
+}
+
+func (s *ovhScanner) Endpoints() []string {
+	return []string{suppliers.OVHBaseURL}
+}
+
 func (s *ovhScanner) DryRun(n number.Number, _ ScannerOptions) error {
 	if !s.isSupported(n.CountryCode) {
 		return fmt.Errorf("country code %d is not supported", n.CountryCode)
@@ -39,8 +48,18 @@ func (s *ovhScanner) DryRun(n number.Number, _ ScannerOptions) error {
 	return nil
 }
 
-func (s *ovhScanner) Run(n number.Number, _ ScannerOptions) (interface{}, error) {
-	res, err := s.client.Search(n)
+func (s *ovhScanner) Run(n number.Number, opts ScannerOptions) (interface{}, error) {
+	return s.RunContext(context.Background(), n, opts)
+}
+
+func (s *ovhScanner) RunContext(ctx context.Context, n number.Number, _ ScannerOptions) (interface{}, error) {
+	var res *suppliers.OVHScannerResponse
+	var err error
+	if c, ok := s.client.(suppliers.OVHContextSupplier); ok {
+		res, err = c.SearchContext(ctx, n)
+	} else {
+		res, err = s.client.Search(n)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,7 @@ func TestOVHScanner_Metadata(t *testing.T) {
 	scanner := remote.NewOVHScanner(&mocks.OVHSupplier{})
 	assert.Equal(t, remote.OVH, scanner.Name())
 	assert.NotEmpty(t, scanner.Description())
+	assert.Equal(t, []string{suppliers.OVHBaseURL}, scanner.(remote.EndpointsScanner).Endpoints())
 }
 
 func TestOVHScanner(t *testing.T) {
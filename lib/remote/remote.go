@@ -1,30 +1,50 @@
 package remote
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tracing"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
+	"time"
 )
 
 var mu sync.Locker = &sync.RWMutex{}
 var plugins []Scanner
+var pluginNames = map[string]bool{}
 
 type Library struct {
 	m        *sync.RWMutex
 	scanners []Scanner
-	results  map[string]interface{}
-	errors   map[string]error
 	filter   filter.Filter
+	observer ScanObserver
+}
+
+// ScanStatus is the outcome of a scanner run
+type ScanStatus string
+
+const (
+	ScanSuccess ScanStatus = "success"
+	ScanError   ScanStatus = "error"
+	// ScanSkipped is the status of scanners whose dry run failed
+	ScanSkipped ScanStatus = "skipped"
+	ScanPanic   ScanStatus = "panic"
+)
+
+// ScanObserver is notified of every scanner run, e.g. to export metrics
+type ScanObserver interface {
+	ObserveScan(scanner string, status ScanStatus, duration time.Duration)
 }
 
 func NewLibrary(filterEngine filter.Filter) *Library {
 	return &Library{
 		m:        &sync.RWMutex{},
 		scanners: []Scanner{},
-		results:  map[string]interface{}{},
-		errors:   map[string]error{},
 		filter:   filterEngine,
 	}
 }
@@ -36,61 +56,108 @@ func (r *Library) LoadPlugins() {
 }
 
 func (r *Library) AddScanner(s Scanner) {
-	if r.filter.Match(s.Name()) {
-		logrus.WithField("scanner", s.Name()).Debug("Scanner was ignored by filter")
+	name := s.Name()
+	if r.filter.Match(name, scannerTags(s, name)...) {
+		logrus.WithField("scanner", name).Debug("Scanner was ignored by filter")
 		return
 	}
 	r.scanners = append(r.scanners, s)
 }
 
-func (r *Library) addResult(k string, v interface{}) {
-	r.m.Lock()
-	defer r.m.Unlock()
-	r.results[k] = v
+// Observe notifies the given observer of scanner runs of the library
+func (r *Library) Observe(o ScanObserver) {
+	r.observer = o
 }
 
-func (r *Library) addError(k string, err error) {
-	r.m.Lock()
-	defer r.m.Unlock()
-	r.errors[k] = err
+// observe ends the span of a scanner run and notifies the observer
+func (r *Library) observe(span trace.Span, scanner string, status ScanStatus, start time.Time, err error) {
+	tracing.EndScanner(span, string(status), err)
+	if r.observer != nil {
+		r.observer.ObserveScan(scanner, status, time.Since(start))
+	}
+}
+
+// Filtered returns a library with the scanners of r that are not matched by the given filter
+func (r *Library) Filtered(f filter.Filter) *Library {
+	r.m.RLock()
+	defer r.m.RUnlock()
+	lib := NewLibrary(f)
+	lib.observer = r.observer
+	for _, s := range r.scanners {
+		lib.AddScanner(s)
+	}
+	return lib
 }
 
 func (r *Library) Scan(n *number.Number, opts ScannerOptions) (map[string]interface{}, map[string]error) {
+	return r.ScanContext(context.Background(), n, opts)
+}
+
+// ScanContext runs all scanners with the given number. When the context is
+// cancelled, scanners still running are reported with the context error.
+func (r *Library) ScanContext(ctx context.Context, n *number.Number, opts ScannerOptions) (map[string]interface{}, map[string]error) {
 	var wg sync.WaitGroup
+	res := newScanResult()
 
 	for _, s := range r.scanners {
 		wg.Add(1)
 		go func(s Scanner) {
 			defer wg.Done()
+			name := s.Name()
+			defer res.finish(name)
+			start := time.Now()
+			logger := logs.FromContext(ctx).WithField("scanner", name)
+			scanCtx, span := tracing.StartScanner(logs.NewContext(ctx, logger), name, n)
 			defer func() {
 				if err := recover(); err != nil {
-					logrus.WithField("scanner", s.Name()).WithField("error", err).Debug("Scanner panicked")
-					r.addError(s.Name(), errors.New("panic occurred while running scan, see debug logs"))
+					logger.WithField("error", err).Debug("Scanner panicked")
+					res.addError(name, errors.New("panic occurred while running scan, see debug logs"))
+					r.observe(span, name, ScanPanic, start, fmt.Errorf("panic: %v", err))
 				}
 			}()
 
+			if err := scanCtx.Err(); err != nil {
+				res.addError(name, err)
+				r.observe(span, name, ScanError, start, err)
+				return
+			}
+
 			if err := s.DryRun(*n, opts); err != nil {
-				logrus.
-					WithField("scanner", s.Name()).
+				logger.
 					WithField("reason", err.Error()).
 					Debug("Scanner was ignored because it should not run")
+				r.observe(span, name, ScanSkipped, start, nil)
 				return
 			}
 
-			data, err := s.Run(*n, opts)
+			data, err := RunContext(scanCtx, s, *n, opts)
 			if err != nil {
-				r.addError(s.Name(), err)
+				res.addError(name, err)
+				r.observe(span, name, ScanError, start, err)
 				return
 			}
 			if data != nil {
-				r.addResult(s.Name(), data)
+				res.addResult(name, data)
 			}
+			r.observe(span, name, ScanSuccess, start, nil)
 		}(s)
 	}
 
-	wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
 
-	return r.results, r.errors
+	select {
+	case <-done:
+	case <-ctx.Done():
+		for _, s := range r.scanners {
+			res.cancel(s.Name(), ctx.Err())
+		}
+	}
+
+	return res.close()
 }
 
 func (r *Library) GetAllScanners() []Scanner {
@@ -108,8 +175,94 @@ func (r *Library) GetScanner(name string) Scanner {
 	return nil
 }
 
+// scanResult collects results of a single scan. Once closed,
+// results of scanners still running are discarded.
+type scanResult struct {
+	m        sync.Mutex
+	closed   bool
+	results  map[string]interface{}
+	errors   map[string]error
+	finished map[string]bool
+}
+
+func newScanResult() *scanResult {
+	return &scanResult{
+		results:  map[string]interface{}{},
+		errors:   map[string]error{},
+		finished: map[string]bool{},
+	}
+}
+
+// finish marks the given scanner as done, whatever its outcome
+func (r *scanResult) finish(k string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.finished[k] = true
+}
+
+// cancel records the error for the given scanner when it's still running,
+// scanners skipped or done already keep their outcome
+func (r *scanResult) cancel(k string, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if !r.finished[k] {
+		r.setError(k, err)
+	}
+}
+
+func (r *scanResult) addResult(k string, v interface{}) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed {
+		return
+	}
+	r.results[k] = v
+}
+
+// addError records an error for the given scanner,
+// unless it already produced a result or an error.
+func (r *scanResult) addError(k string, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.setError(k, err)
+}
+
+func (r *scanResult) setError(k string, err error) {
+	if r.closed {
+		return
+	}
+	if _, ok := r.results[k]; ok {
+		return
+	}
+	if _, ok := r.errors[k]; ok {
+		return
+	}
+	r.errors[k] = err
+}
+
+func (r *scanResult) close() (map[string]interface{}, map[string]error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.closed = true
+	return r.results, r.errors
+}
+
 func RegisterPlugin(s Scanner) {
 	mu.Lock()
 	defer mu.Unlock()
 	plugins = append(plugins, s)
+	pluginNames[s.Name()] = true
+}
+
+// Plugins returns registered plugins
+func Plugins() []Scanner {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Scanner(nil), plugins...)
+}
+
+func isPlugin(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return pluginNames[name]
 }
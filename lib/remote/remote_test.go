@@ -1,13 +1,16 @@
 package remote_test
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
 	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestRemoteLibrary_SuccessScan(t *testing.T) {
@@ -167,3 +170,137 @@ func TestRemoteLibrary_AddIgnoredScanner(t *testing.T) {
 
 	assert.Equal(t, []remote.Scanner{fakeScanner}, lib.GetAllScanners())
 }
+
+func TestRemoteLibrary_Filtered(t *testing.T) {
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fake")
+
+	fakeScanner2 := &mocks.Scanner{}
+	fakeScanner2.On("Name").Return("fake2")
+
+	lib := remote.NewLibrary(filter.NewEngine())
+	lib.AddScanner(fakeScanner)
+	lib.AddScanner(fakeScanner2)
+
+	f := filter.NewEngine()
+	f.AddAllowRule("fake2")
+
+	assert.Equal(t, []remote.Scanner{fakeScanner2}, lib.Filtered(f).GetAllScanners())
+	assert.Equal(t, []remote.Scanner{fakeScanner, fakeScanner2}, lib.GetAllScanners())
+}
+
+func TestRemoteLibrary_CancelledScan(t *testing.T) {
+	num, err := number.NewNumber("15556661212")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan time.Time)
+	defer close(release)
+
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fake")
+	fakeScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(nil).Once()
+	fakeScanner.On("Run", *num, remote.ScannerOptions{}).WaitUntil(release).Return(nil, nil).Once()
+
+	// Scanners done before the cancellation keep their outcome
+	skippedScanner := &mocks.Scanner{}
+	skippedScanner.On("Name").Return("skipped")
+	skippedScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(errors.New("not configured")).Once()
+	doneScanner := &mocks.Scanner{}
+	doneScanner.On("Name").Return("done")
+	doneScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(nil).Once()
+	doneScanner.On("Run", *num, remote.ScannerOptions{}).Return("result", nil).Once()
+
+	lib := remote.NewLibrary(filter.NewEngine())
+
+	lib.AddScanner(fakeScanner)
+	lib.AddScanner(skippedScanner)
+	lib.AddScanner(doneScanner)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, errs := lib.ScanContext(ctx, num, remote.ScannerOptions{})
+	assert.Equal(t, map[string]interface{}{"done": "result"}, result)
+	assert.Equal(t, map[string]error{"fake": context.DeadlineExceeded}, errs)
+	skippedScanner.AssertExpectations(t)
+	doneScanner.AssertExpectations(t)
+}
+
+func TestRemoteLibrary_ScanWithCancelledContext(t *testing.T) {
+	num, err := number.NewNumber("15556661212")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fake")
+
+	lib := remote.NewLibrary(filter.NewEngine())
+
+	lib.AddScanner(fakeScanner)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, errs := lib.ScanContext(ctx, num, remote.ScannerOptions{})
+	assert.Equal(t, map[string]interface{}{}, result)
+	assert.Equal(t, map[string]error{"fake": context.Canceled}, errs)
+
+	fakeScanner.AssertExpectations(t)
+}
+
+type fakeObserver struct {
+	mu       sync.Mutex
+	statuses map[string]remote.ScanStatus
+}
+
+func (o *fakeObserver) ObserveScan(scanner string, status remote.ScanStatus, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.statuses[scanner] = status
+}
+
+func TestRemoteLibrary_Observe(t *testing.T) {
+	num, err := number.NewNumber("15556661212")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	successScanner := &mocks.Scanner{}
+	successScanner.On("Name").Return("success")
+	successScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(nil)
+	successScanner.On("Run", *num, remote.ScannerOptions{}).Return(map[string]string{}, nil)
+
+	errorScanner := &mocks.Scanner{}
+	errorScanner.On("Name").Return("error")
+	errorScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(nil)
+	errorScanner.On("Run", *num, remote.ScannerOptions{}).Return(nil, errors.New("dummy error"))
+
+	skippedScanner := &mocks.Scanner{}
+	skippedScanner.On("Name").Return("skipped")
+	skippedScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(errors.New("not configured"))
+
+	panicScanner := &mocks.Scanner{}
+	panicScanner.On("Name").Return("panic")
+	panicScanner.On("DryRun", *num, remote.ScannerOptions{}).Return(nil)
+	panicScanner.On("Run", *num, remote.ScannerOptions{}).Panic("dummy panic")
+
+	o := &fakeObserver{statuses: map[string]remote.ScanStatus{}}
+	lib := remote.NewLibrary(filter.NewEngine())
+	lib.Observe(o)
+	for _, s := range []remote.Scanner{successScanner, errorScanner, skippedScanner, panicScanner} {
+		lib.AddScanner(s)
+	}
+
+	// Filtered libraries keep the observer
+	lib.Filtered(filter.NewEngine()).Scan(num, remote.ScannerOptions{})
+
+	assert.Equal(t, map[string]remote.ScanStatus{
+		"success": remote.ScanSuccess,
+		"error":   remote.ScanError,
+		"skipped": remote.ScanSkipped,
+		"panic":   remote.ScanPanic,
+	}, o.statuses)
+}
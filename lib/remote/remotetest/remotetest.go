@@ -0,0 +1,288 @@
+// Package remotetest implements checks for scanner implementations.
+// It's meant to be used by plugin authors in their own test suite.
+//
+//	func TestCustomScanner_Conformance(t *testing.T) {
+//		remotetest.TestScanner(t, &customScanner{}, remotetest.Config{})
+//	}
+package remotetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/output"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+)
+
+// DefaultTimeout is the maximum duration of a single scan
+const DefaultTimeout = 10 * time.Second
+
+// DefaultDeadline is the deadline of the scan checking cancellation
+const DefaultDeadline = 100 * time.Millisecond
+
+// builtinScanners are names plugins can't use
+var builtinScanners = []string{
+	remote.Local,
+	remote.Numverify,
+	remote.Googlesearch,
+	remote.OVH,
+	remote.GoogleCSE,
+}
+
+// Config customizes the conformance checks
+type Config struct {
+	// Options are passed to DryRun and Run
+	Options remote.ScannerOptions
+	// Numbers are scanned in addition to edge case numbers
+	Numbers []*number.Number
+	// Timeout is the maximum duration of a single scan, defaults to DefaultTimeout
+	Timeout time.Duration
+	// Deadline is the deadline of the scan checking cancellation, defaults to DefaultDeadline
+	Deadline time.Duration
+	// ReservedNames are names the scanner must not use, in addition
+	// to built-in scanners and other registered plugins
+	ReservedNames []string
+	// MapResults accepts results of type map[string]interface{}, for
+	// out-of-process plugins which can't return structs
+	MapResults bool
+}
+
+// TestScanner runs the scanner through standard checks:
+// its name must be unique and non-empty, DryRun must be deterministic,
+// Run must return a struct rendering correctly in console and JSON output,
+// edge case numbers must not cause panics, and cancellation must be respected.
+// Cancellation is checked with supplier requests blocked until the deadline.
+func TestScanner(t *testing.T, s remote.Scanner, c Config) {
+	if c.Options == nil {
+		c.Options = remote.ScannerOptions{}
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.Deadline == 0 {
+		c.Deadline = DefaultDeadline
+	}
+	numbers := append([]*number.Number{test.NewFakeUSNumber()}, c.Numbers...)
+	numbers = append(numbers, test.NewEdgeCaseNumbers()...)
+
+	t.Run("name", func(t *testing.T) {
+		testName(t, s, c)
+	})
+	t.Run("dry run is deterministic", func(t *testing.T) {
+		for _, n := range numbers {
+			testDryRun(t, s, *n, c)
+		}
+	})
+	t.Run("run output", func(t *testing.T) {
+		for _, n := range numbers {
+			testRun(t, s, *n, c)
+		}
+	})
+	t.Run("cancellation", func(t *testing.T) {
+		testCancellation(t, s, *numbers[0], c)
+	})
+}
+
+// reporter is the part of testing.T checks report failures to,
+// so checks can be tested with a fake
+type reporter interface {
+	Errorf(format string, args ...interface{})
+	Helper()
+}
+
+func testName(t reporter, s remote.Scanner, c Config) {
+	t.Helper()
+	name := s.Name()
+	assert.NotEmpty(t, name, "scanner name must not be empty")
+	assert.Equal(t, strings.TrimSpace(name), name, "scanner name must not have leading or trailing spaces")
+	assert.NotContains(t, name, "/", "scanner name is used in URLs and must not contain slashes")
+	assert.NotEmpty(t, s.Description(), "scanner description must not be empty")
+
+	for _, reserved := range append(builtinScanners, c.ReservedNames...) {
+		assert.NotEqual(t, reserved, name, "scanner name %q is already used", reserved)
+	}
+	// Plugins usually register themselves in init, so
+	// plugins of the same type as the scanner are skipped
+	for _, p := range remote.Plugins() {
+		if p.Name() == name && reflect.TypeOf(p) != reflect.TypeOf(s) {
+			t.Errorf("scanner name %q is already used by plugin %T", name, p)
+		}
+	}
+}
+
+func testDryRun(t *testing.T, s remote.Scanner, n number.Number, c Config) {
+	var first error
+	for i := 0; i < 3; i++ {
+		panicked, err := safeDryRun(s, n, c.Options)
+		if !assert.Nil(t, panicked, "DryRun panicked with number %s", n.E164) {
+			return
+		}
+		if i == 0 {
+			first = err
+			continue
+		}
+		assert.Equal(t, errString(first), errString(err), "DryRun is not deterministic with number %s", n.E164)
+	}
+}
+
+func testRun(t *testing.T, s remote.Scanner, n number.Number, c Config) {
+	if _, err := safeDryRun(s, n, c.Options); err != nil {
+		return
+	}
+
+	var (
+		result   interface{}
+		err      error
+		panicked interface{}
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			panicked = recover()
+		}()
+		result, err = s.Run(n, c.Options)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(c.Timeout):
+		t.Errorf("Run with number %s didn't complete within %s", n.E164, c.Timeout)
+		return
+	}
+
+	if !assert.Nil(t, panicked, "Run panicked with number %s", n.E164) {
+		return
+	}
+	if err != nil || result == nil {
+		return
+	}
+
+	testResult(t, result, n, c)
+}
+
+func testResult(t *testing.T, result interface{}, n number.Number, c Config) {
+	v := reflect.ValueOf(result)
+	switch {
+	case v.Kind() == reflect.Struct:
+	case c.MapResults && v.Type() == reflect.TypeOf(map[string]interface{}{}):
+		// Untyped results of out-of-process plugins use keys as titles
+	default:
+		t.Errorf("Run must return a struct, got %T with number %s", result, n.E164)
+		return
+	}
+
+	for i := 0; v.Kind() == reflect.Struct && i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		_, hasJSON := field.Tag.Lookup("json")
+		assert.True(t, hasJSON, "field %s of %T must have a json tag", field.Name, result)
+		_, hasConsole := field.Tag.Lookup("console")
+		assert.True(t, hasConsole, "field %s of %T must have a console tag", field.Name, result)
+	}
+
+	_, err := json.Marshal(result)
+	assert.NoError(t, err, "result of type %T can't be encoded to JSON", result)
+
+	var buf bytes.Buffer
+	assert.NotPanics(t, func() {
+		err = output.NewConsoleOutput(&buf).Write(map[string]interface{}{"conformance": result}, map[string]error{})
+	}, "result of type %T can't be rendered in console output", result)
+	assert.NoError(t, err)
+}
+
+func testCancellation(t *testing.T, s remote.Scanner, n number.Number, c Config) {
+	if _, err := safeDryRun(s, n, c.Options); err != nil {
+		t.Skipf("scanner can't run with number %s: %v", n.E164, err)
+	}
+
+	// Supplier requests block until their context is done, like
+	// requests to an unresponsive API, and are never sent
+	release := make(chan struct{})
+	defer close(release)
+	remote.SetTransport(remote.TransportChain{Fixtures: func(http.RoundTripper) http.RoundTripper {
+		return blockingTransport(release)
+	}})
+	defer remote.SetTransport(remote.TransportChain{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Deadline)
+	defer cancel()
+
+	var (
+		err      error
+		panicked interface{}
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			panicked = recover()
+		}()
+		_, err = remote.RunContext(ctx, s, n, c.Options)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(c.Timeout):
+		if _, ok := s.(remote.ContextScanner); ok {
+			t.Errorf("RunContext didn't return within %s after its context was cancelled", c.Timeout)
+		} else {
+			t.Errorf("Run didn't return within %s, consider implementing remote.ContextScanner", c.Timeout)
+		}
+		return
+	}
+
+	if !assert.Nil(t, panicked, "RunContext panicked with number %s", n.E164) {
+		return
+	}
+	// Scanners completing before the deadline had nothing to cancel
+	if ctx.Err() == nil {
+		return
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RunContext must return the error of its context once it's done, got %v", err)
+	}
+}
+
+// blockingTransport holds requests until their context
+// is done or the release channel is closed
+type blockingTransport chan struct{}
+
+func (t blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case <-t:
+		return nil, errors.New("request blocked by conformance checks")
+	}
+}
+
+func safeDryRun(s remote.Scanner, n number.Number, opts remote.ScannerOptions) (panicked interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = r
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return nil, s.DryRun(n, opts)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
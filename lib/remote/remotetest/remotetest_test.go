@@ -0,0 +1,110 @@
+package remotetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type namedScanner struct {
+	remote.Scanner
+	name string
+}
+
+func (s *namedScanner) Name() string {
+	return s.name
+}
+
+func TestScanner_Builtin(t *testing.T) {
+	testcases := []struct {
+		name    string
+		scanner remote.Scanner
+	}{
+		{
+			name:    "test local scanner",
+			scanner: remote.NewLocalScanner(),
+		},
+		{
+			name:    "test googlesearch scanner",
+			scanner: remote.NewGoogleSearchScanner(),
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			// Built-in names are reserved for built-in scanners
+			TestScanner(t, &namedScanner{Scanner: tt.scanner, name: "conformance_" + tt.scanner.Name()}, Config{})
+		})
+	}
+}
+
+// supplierScanner requests an API through the supplier transport chain
+type supplierScanner struct{}
+
+type supplierScannerResult struct {
+	Status int `json:"status" console:"Status"`
+}
+
+func (s *supplierScanner) Name() string        { return "conformance_supplier" }
+func (s *supplierScanner) Description() string { return "Requests a supplier API" }
+
+func (s *supplierScanner) DryRun(number.Number, remote.ScannerOptions) error {
+	return nil
+}
+
+func (s *supplierScanner) Run(n number.Number, opts remote.ScannerOptions) (interface{}, error) {
+	return s.RunContext(context.Background(), n, opts)
+}
+
+func (s *supplierScanner) RunContext(ctx context.Context, _ number.Number, _ remote.ScannerOptions) (interface{}, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.example.com/numbers", nil)
+	res, err := remote.HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return supplierScannerResult{Status: res.StatusCode}, nil
+}
+
+func TestScanner_Blocking(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.example.com").Get("/numbers").Times(20).Reply(200)
+
+	// Requests are blocked by the cancellation check until its deadline
+	TestScanner(t, &supplierScanner{}, Config{Deadline: 20 * time.Millisecond})
+}
+
+func TestScanner_PluginNameCollision(t *testing.T) {
+	remote.RegisterPlugin(&namedScanner{Scanner: remote.NewLocalScanner(), name: "conformance_collision"})
+
+	// Plugins of the same type are the scanner itself
+	r := &recordingT{}
+	testName(r, &namedScanner{Scanner: remote.NewLocalScanner(), name: "conformance_collision"}, Config{})
+	assert.Empty(t, r.errors)
+
+	r = &recordingT{}
+	testName(r, &collidingScanner{namedScanner{Scanner: remote.NewLocalScanner(), name: "conformance_collision"}}, Config{})
+	assert.Equal(t, []string{`scanner name "conformance_collision" is already used by plugin *remotetest.namedScanner`}, r.errors)
+}
+
+// recordingT records failures of checks
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Helper() {}
+
+type collidingScanner struct {
+	namedScanner
+}
@@ -1,9 +1,15 @@
 package remote
 
 import (
+	"context"
+	"debug/elf"
+	"debug/macho"
 	"fmt"
 	"os"
+	"path/filepath"
 	"plugin"
+	"runtime"
+	"sync"
 
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 )
@@ -17,6 +23,62 @@ func (o ScannerOptions) GetStringEnv(k string) string {
 	return os.Getenv(k)
 }
 
+// ScannerOption describes an option accepted by a scanner.
+// Options are named like their environment variable equivalent.
+type ScannerOption struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+}
+
+// OptionsScanner is implemented by scanners describing the options they accept
+type OptionsScanner interface {
+	Scanner
+	Options() []ScannerOption
+}
+
+// EndpointsScanner is implemented by scanners requesting external
+// services, it returns base URLs of the services
+type EndpointsScanner interface {
+	Scanner
+	Endpoints() []string
+}
+
+// Tags declared by built-in scanners, they can be used in filter rules
+const (
+	// TagOffline marks scanners that don't send any request
+	TagOffline = "offline"
+	// TagNetwork marks scanners requesting external services
+	TagNetwork = "network"
+	// TagPaid marks scanners requesting paid APIs
+	TagPaid = "paid"
+	// TagPlugin is added to tags of scanners loaded from plugins
+	TagPlugin = "plugin"
+)
+
+// TaggedScanner is implemented by scanners declaring tags
+type TaggedScanner interface {
+	Scanner
+	Tags() []string
+}
+
+// ScannerTags returns tags of the given scanner, including TagPlugin for plugins
+func ScannerTags(s Scanner) []string {
+	return scannerTags(s, s.Name())
+}
+
+func scannerTags(s Scanner, name string) []string {
+	var tags []string
+	if ts, ok := s.(TaggedScanner); ok {
+		tags = append(tags, ts.Tags()...)
+	}
+	if isPlugin(name) {
+		tags = append(tags, TagPlugin)
+	}
+	return tags
+}
+
 type Plugin interface {
 	Lookup(string) (plugin.Symbol, error)
 }
@@ -28,15 +90,144 @@ type Scanner interface {
 	Run(number.Number, ScannerOptions) (interface{}, error)
 }
 
+// ContextScanner is implemented by scanners able to
+// abort a running scan when the given context is cancelled.
+type ContextScanner interface {
+	Scanner
+	RunContext(context.Context, number.Number, ScannerOptions) (interface{}, error)
+}
+
+// RunContext runs the given scanner, passing the context
+// through when the scanner supports cancellation.
+func RunContext(ctx context.Context, s Scanner, n number.Number, opts ScannerOptions) (interface{}, error) {
+	if cs, ok := s.(ContextScanner); ok {
+		return cs.RunContext(ctx, n, opts)
+	}
+	return s.Run(n, opts)
+}
+
+// OpenPlugin loads the plugin at the given path. Shared objects are loaded
+// as Go plugins whatever their extension, WebAssembly modules (.wasm) are
+// run in a sandbox, and any other executable is run as an exec plugin.
 func OpenPlugin(path string) error {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
 		return fmt.Errorf("given path %s does not exist", path)
 	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case filepath.Ext(path) == ".wasm":
+		return openWasmPlugin(path)
+	case filepath.Ext(path) == ".so", !info.IsDir() && isSharedObject(path):
+		return openGoPlugin(path)
+	default:
+		return openExecPlugin(path, info)
+	}
+}
+
+var (
+	copiesMu sync.Mutex
+	copyDirs []string
+)
+
+// OpenPluginData loads the plugin at the given path from its content, such
+// as content whose checksum was verified, so changes made to the file once
+// read are never loaded. WebAssembly modules are compiled from data, other
+// plugins are loaded from a private copy removed by RemovePluginCopies.
+func OpenPluginData(path string, data []byte) error {
+	if filepath.Ext(path) == ".wasm" {
+		return openWasmPluginData(path, data)
+	}
+
+	dir, err := os.MkdirTemp("", "phoneinfoga-plugin-")
+	if err != nil {
+		return err
+	}
+	copiesMu.Lock()
+	copyDirs = append(copyDirs, dir)
+	copiesMu.Unlock()
+
+	p := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(p, data, 0700); err != nil {
+		return err
+	}
+	return OpenPlugin(p)
+}
+
+// RemovePluginCopies removes copies of plugins loaded with OpenPluginData,
+// it must be called once scanners are not used anymore.
+func RemovePluginCopies() {
+	copiesMu.Lock()
+	defer copiesMu.Unlock()
+	for _, dir := range copyDirs {
+		_ = os.RemoveAll(dir)
+	}
+	copyDirs = nil
+}
+
+// isSharedObject reports whether the file is an ELF shared object or a
+// Mach-O dynamic library or bundle, which Go plugins are built as.
+// Position independent executables are ELF shared objects too,
+// but unlike Go plugins they have a program interpreter.
+func isSharedObject(path string) bool {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		if f.Type != elf.ET_DYN {
+			return false
+		}
+		for _, p := range f.Progs {
+			if p.Type == elf.PT_INTERP {
+				return false
+			}
+		}
+		return true
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return f.Type == macho.TypeDylib || f.Type == macho.TypeBundle
+	}
+	return false
+}
 
+func openGoPlugin(path string) error {
 	_, err := plugin.Open(path)
 	if err != nil {
 		return fmt.Errorf("given plugin %s is not valid: %v", path, err)
 	}
+	return nil
+}
+
+func openExecPlugin(path string, info os.FileInfo) error {
+	if info.IsDir() || (runtime.GOOS != "windows" && info.Mode()&0111 == 0) {
+		return fmt.Errorf("given plugin %s is not executable", path)
+	}
+
+	s, err := NewExecScanner(path)
+	if err != nil {
+		return fmt.Errorf("given plugin %s is not valid: %v", path, err)
+	}
+	RegisterPlugin(s)
+
+	return nil
+}
+
+func openWasmPlugin(path string) error {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return openWasmPluginData(path, code)
+}
+
+func openWasmPluginData(path string, code []byte) error {
+	s, err := newWasmScanner(path, code)
+	if err != nil {
+		return fmt.Errorf("given plugin %s is not valid: %v", path, err)
+	}
+	RegisterPlugin(s)
 
 	return nil
 }
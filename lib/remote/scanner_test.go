@@ -3,6 +3,7 @@ package remote
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -40,6 +41,27 @@ func Test_ValidatePlugin_Errors(t *testing.T) {
 	}
 }
 
+func TestIsSharedObject(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a Go plugin is slow")
+	}
+	// Go plugins can be named without the .so extension
+	goPlugin := filepath.Join(t.TempDir(), "scanner.plugin")
+	output, err := exec.Command("go", "build", "-buildmode=plugin", "-o", goPlugin, "../../examples/plugin").CombinedOutput()
+	if err != nil {
+		t.Skipf("unable to build Go plugin: %v\n%s", err, output)
+	}
+	testBinary, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, isSharedObject(goPlugin))
+	assert.False(t, isSharedObject(testBinary))
+	assert.False(t, isSharedObject("testdata/exec_plugin.sh"))
+	assert.False(t, isSharedObject("testdata/invalid.so"))
+}
+
 func TestScannerOptions(t *testing.T) {
 	testcases := []struct {
 		name  string
@@ -78,3 +100,42 @@ func TestScannerOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestScannerTags(t *testing.T) {
+	execScanner, err := NewExecScanner("testdata/exec_plugin.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		scanner  Scanner
+		expected []string
+	}{
+		{
+			name:     "test offline scanner",
+			scanner:  NewLocalScanner(),
+			expected: []string{TagOffline},
+		},
+		{
+			name:     "test paid scanner",
+			scanner:  NewGoogleCSEScanner(nil),
+			expected: []string{TagNetwork, TagPaid},
+		},
+		{
+			name:     "test plugin scanner",
+			scanner:  execScanner,
+			expected: []string{TagNetwork, TagPlugin},
+		},
+	}
+
+	// Plugins are tagged from the moment they're registered
+	assert.Equal(t, []string{TagNetwork}, ScannerTags(execScanner))
+	RegisterPlugin(execScanner)
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ScannerTags(tt.scanner))
+		})
+	}
+}
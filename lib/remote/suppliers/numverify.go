@@ -1,10 +1,11 @@
 package suppliers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
 	"net/http"
 )
 
@@ -17,6 +18,13 @@ type NumverifySupplierRequestInterface interface {
 	ValidateNumber(string) (*NumverifyValidateResponse, error)
 }
 
+// NumverifyContextRequest is implemented by requests able to
+// carry a context, so they're cancelled along with the scan.
+type NumverifyContextRequest interface {
+	NumverifySupplierRequestInterface
+	SetContext(context.Context) NumverifySupplierRequestInterface
+}
+
 type NumverifyErrorResponse struct {
 	Message string `json:"message"`
 }
@@ -37,6 +45,8 @@ type NumverifyValidateResponse struct {
 
 type NumverifySupplier struct {
 	Uri string
+	// Client sends requests, http.DefaultClient is used if nil
+	Client *http.Client
 }
 
 func NewNumverifySupplier() *NumverifySupplier {
@@ -46,12 +56,23 @@ func NewNumverifySupplier() *NumverifySupplier {
 }
 
 type NumverifyRequest struct {
+	ctx    context.Context
 	apiKey string
 	uri    string
+	client *http.Client
 }
 
 func (s *NumverifySupplier) Request() NumverifySupplierRequestInterface {
-	return &NumverifyRequest{uri: s.Uri}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &NumverifyRequest{ctx: context.Background(), uri: s.Uri, client: client}
+}
+
+func (r *NumverifyRequest) SetContext(ctx context.Context) NumverifySupplierRequestInterface {
+	r.ctx = ctx
+	return r
 }
 
 func (r *NumverifyRequest) SetApiKey(k string) NumverifySupplierRequestInterface {
@@ -60,18 +81,17 @@ func (r *NumverifyRequest) SetApiKey(k string) NumverifySupplierRequestInterface
 }
 
 func (r *NumverifyRequest) ValidateNumber(internationalNumber string) (res *NumverifyValidateResponse, err error) {
-	logrus.
+	logs.FromContext(r.ctx).
 		WithField("number", internationalNumber).
 		Debug("Running validate operation through Numverify API")
 
 	url := fmt.Sprintf("%s/number_verification/validate?number=%s", r.uri, internationalNumber)
 
 	// Build the request
-	client := &http.Client{}
-	req, _ := http.NewRequest("GET", url, nil)
+	req, _ := http.NewRequestWithContext(r.ctx, "GET", url, nil)
 	req.Header.Set("Apikey", r.apiKey)
 
-	response, err := client.Do(req)
+	response, err := r.client.Do(req)
 
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package suppliers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,10 +11,20 @@ import (
 	"strings"
 )
 
+// OVHBaseURL is the base URL of the OVH Telecom REST API
+const OVHBaseURL = "https://api.ovh.com/1.0"
+
 type OVHSupplierInterface interface {
 	Search(number.Number) (*OVHScannerResponse, error)
 }
 
+// OVHContextSupplier is implemented by suppliers able to
+// search with a context, so they're cancelled along with the scan.
+type OVHContextSupplier interface {
+	OVHSupplierInterface
+	SearchContext(context.Context, number.Number) (*OVHScannerResponse, error)
+}
+
 // OVHAPIResponseNumber is a type that describes an OVH number range
 type OVHAPIResponseNumber struct {
 	MatchingCriteria    interface{} `json:"matchingCriteria"`
@@ -39,13 +50,20 @@ type OVHScannerResponse struct {
 	ZipCode     string
 }
 
-type OVHSupplier struct{}
+type OVHSupplier struct {
+	// Client sends requests, http.DefaultClient is used if nil
+	Client *http.Client
+}
 
 func NewOVHSupplier() *OVHSupplier {
 	return &OVHSupplier{}
 }
 
 func (s *OVHSupplier) Search(num number.Number) (*OVHScannerResponse, error) {
+	return s.SearchContext(context.Background(), num)
+}
+
+func (s *OVHSupplier) SearchContext(ctx context.Context, num number.Number) (*OVHScannerResponse, error) {
 	countryCode := strings.ToLower(num.Country)
 
 	if countryCode == "" {
@@ -53,7 +71,15 @@ func (s *OVHSupplier) Search(num number.Number) (*OVHScannerResponse, error) {
 	}
 
 	// Build the request
-	response, err := http.Get(fmt.Sprintf("https://api.ovh.com/1.0/telephony/number/detailedZones?country=%s", countryCode))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/telephony/number/detailedZones?country=%s", OVHBaseURL, countryCode), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
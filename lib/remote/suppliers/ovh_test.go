@@ -3,8 +3,10 @@ package suppliers
 import (
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/fixtures"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"gopkg.in/h2non/gock.v1"
+	"net/http"
 	"net/url"
 	"testing"
 )
@@ -89,3 +91,22 @@ func TestOVHSupplierCountryCodeError(t *testing.T) {
 	assert.Nil(t, got)
 	assert.EqualError(t, err, "[country] Given data (co) does not belong to the NumberCountryEnum enumeration")
 }
+
+func TestOVHSupplierReplay(t *testing.T) {
+	num, _ := number.NewNumber("33365172812")
+
+	s := NewOVHSupplier()
+	s.Client = &http.Client{Transport: fixtures.NewTransport(fixtures.Replay, "testdata/fixtures", nil)}
+
+	got, err := s.Search(*num)
+	assert.Nil(t, err)
+
+	expectedResult := &OVHScannerResponse{
+		Found:       true,
+		NumberRange: "036517xxxx",
+		City:        "Abbeville",
+		ZipCode:     "80100",
+	}
+
+	assert.Equal(t, expectedResult, got)
+}
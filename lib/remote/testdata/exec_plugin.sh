@@ -0,0 +1,25 @@
+#!/bin/sh
+# Exec plugin used in tests, see exec_scanner.go for the protocol.
+req=$(cat)
+
+case "$req" in
+*'"method":"describe"'*)
+  echo '{"name":"execscanner","description":"Dummy exec scanner","options":[{"name":"EXEC_API_KEY","required":true,"secret":true}],"tags":["network"]}'
+  ;;
+*'"method":"dry_run"'*)
+  case "$req" in
+  *'"EXEC_API_KEY":"secret"'*) echo '{}' ;;
+  *) echo '{"error":"API key is not defined"}' ;;
+  esac
+  ;;
+*'"method":"run"'*)
+  echo "running scan" >&2
+  case "$req" in
+  *'"e164":"+15556661212"'*) echo '{"error":"number is blocked"}' ;;
+  *) echo '{"result":{"valid":true,"info":"This number is known for scams!"}}' ;;
+  esac
+  ;;
+*)
+  exit 1
+  ;;
+esac
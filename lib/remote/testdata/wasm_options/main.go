@@ -0,0 +1,55 @@
+//go:build wasip1
+
+// This WASM plugin returns the value of the option named by
+// the OPTION_NAME option, to check which options plugins can read.
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"unsafe"
+)
+
+//go:wasmimport phoneinfoga option
+func hostOption(ptr unsafe.Pointer, size uint32) uint32
+
+//go:wasmimport phoneinfoga read_result
+func hostReadResult(ptr unsafe.Pointer)
+
+func option(key string) string {
+	b := []byte(key)
+	size := hostOption(unsafe.Pointer(&b[0]), uint32(len(b)))
+	if size == 0 {
+		return ""
+	}
+	buf := make([]byte, size)
+	hostReadResult(unsafe.Pointer(&buf[0]))
+	return string(buf)
+}
+
+func main() {
+	var req struct {
+		Method string `json:"method"`
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		os.Exit(1)
+	}
+
+	var res interface{}
+	switch req.Method {
+	case "describe":
+		res = map[string]interface{}{
+			"name":    "wasmoptions",
+			"options": []map[string]interface{}{{"name": "WASM_DECLARED_OPTION"}},
+		}
+	case "dry_run":
+		res = map[string]string{}
+	case "run":
+		name := option("OPTION_NAME")
+		res = map[string]interface{}{
+			"result": map[string]interface{}{name: option(name)},
+		}
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(res)
+}
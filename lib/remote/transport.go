@@ -0,0 +1,97 @@
+package remote
+
+import (
+	"net/http"
+	"sync"
+)
+
+// TransportLayer wraps the transport supplier requests go through
+type TransportLayer func(base http.RoundTripper) http.RoundTripper
+
+// TransportChain holds the layers supplier requests go through. Layers are
+// applied in a fixed order whatever the order they're set in: fixtures are
+// the closest to the network, so replayed requests are still traced and
+// recorded by metrics. Unset layers are skipped.
+type TransportChain struct {
+	Fixtures TransportLayer
+	Tracing  TransportLayer
+	Metrics  TransportLayer
+}
+
+// build returns a transport applying layers of the chain to the base
+func (c TransportChain) build(base http.RoundTripper) http.RoundTripper {
+	rt := base
+	for _, layer := range []TransportLayer{c.Fixtures, c.Tracing, c.Metrics} {
+		if layer != nil {
+			rt = layer(rt)
+		}
+	}
+	return rt
+}
+
+var (
+	chainMu sync.RWMutex
+	chain   TransportChain
+	// chainVersion is incremented by SetTransport, so
+	// transports know when to build the chain again
+	chainVersion int
+)
+
+var supplierTransport = TransportOn(defaultTransport{})
+
+// SetTransport sets the chain supplier requests go through,
+// including clients of scanners initialized beforehand
+func SetTransport(c TransportChain) {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+	chain = c
+	chainVersion++
+}
+
+// Transport returns the transport supplier clients must use
+func Transport() http.RoundTripper {
+	return supplierTransport
+}
+
+// TransportOn returns a transport applying the chain to the given base
+// instead of http.DefaultTransport, for clients needing their own dialer
+func TransportOn(base http.RoundTripper) http.RoundTripper {
+	return &chainTransport{base: base, version: -1}
+}
+
+// HTTPClient returns a client sending requests through Transport
+func HTTPClient() *http.Client {
+	return &http.Client{Transport: Transport()}
+}
+
+type chainTransport struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	rt      http.RoundTripper
+	version int
+}
+
+func (t *chainTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	chainMu.RLock()
+	c, version := chain, chainVersion
+	chainMu.RUnlock()
+
+	t.mu.Lock()
+	if t.version != version {
+		t.rt = c.build(t.base)
+		t.version = version
+	}
+	rt := t.rt
+	t.mu.Unlock()
+
+	return rt.RoundTrip(req)
+}
+
+// defaultTransport looks up http.DefaultTransport on each request,
+// so it can be replaced by tests mocking suppliers
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,44 @@
+package remote
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/h2non/gock.v1"
+)
+
+type layerTransport struct {
+	name  string
+	calls *[]string
+	base  http.RoundTripper
+}
+
+func (t *layerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	*t.calls = append(*t.calls, t.name)
+	return t.base.RoundTrip(req)
+}
+
+func TestSetTransport(t *testing.T) {
+	defer gock.Off()
+	defer SetTransport(TransportChain{})
+
+	gock.New("https://api.ovh.com").Get("/1.0/me").Reply(200)
+
+	var calls []string
+	layer := func(name string) TransportLayer {
+		return func(base http.RoundTripper) http.RoundTripper {
+			return &layerTransport{name: name, calls: &calls, base: base}
+		}
+	}
+
+	client := HTTPClient()
+	// The chain applies to clients created beforehand
+	SetTransport(TransportChain{Metrics: layer("metrics"), Fixtures: layer("fixtures"), Tracing: layer("tracing")})
+
+	res, err := client.Get("https://api.ovh.com/1.0/me")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, []string{"metrics", "tracing", "fixtures"}, calls)
+	assert.True(t, gock.IsDone())
+}
@@ -0,0 +1,335 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/netguard"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// WASM plugins are WASI command modules speaking the exec plugin protocol
+// over standard input and output. They run in a sandbox without any access
+// to the host filesystem or environment, only get options configured for
+// them with SetWasmOptions, and can only use the host module functions below.
+const (
+	// WasmHostModule is the name of the module providing host functions
+	WasmHostModule = "phoneinfoga"
+
+	// WasmRateLimitEnv is the maximum number of HTTP requests per second
+	// a WASM plugin can make, defaults to DefaultWasmRateLimit
+	WasmRateLimitEnv     = "PHONEINFOGA_WASM_RATE_LIMIT"
+	DefaultWasmRateLimit = 5
+
+	// WasmAllowedNetworksEnv gives networks WASM plugins can reach despite
+	// being loopback, private or link-local, separated by commas
+	WasmAllowedNetworksEnv = "PHONEINFOGA_WASM_ALLOWED_NETWORKS"
+
+	// maxWasmFetchSize is the maximum size of HTTP response bodies given to plugins
+	maxWasmFetchSize = 5 << 20
+
+	// wasmFetchRoute is the route of the rate limit rule of fetches
+	wasmFetchRoute = "http_fetch"
+)
+
+// WasmFetchRequest is the request passed to the http_fetch host function
+type WasmFetchRequest struct {
+	Method string            `json:"method,omitempty"`
+	URL    string            `json:"url"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body,omitempty"`
+}
+
+// WasmFetchResponse is the response of the http_fetch host function
+type WasmFetchResponse struct {
+	Status int               `json:"status,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+var (
+	wasmOptionsMu sync.RWMutex
+	wasmOptions   map[string]map[string]string
+)
+
+// SetWasmOptions sets options given to WASM plugins, by plugin name.
+// Unlike other scanners, WASM plugins neither read environment variables
+// nor options given for a scan, so they can't read keys of other scanners.
+func SetWasmOptions(options map[string]map[string]string) {
+	wasmOptionsMu.Lock()
+	defer wasmOptionsMu.Unlock()
+	wasmOptions = options
+}
+
+func wasmPluginOptions(name string) ScannerOptions {
+	wasmOptionsMu.RLock()
+	defer wasmOptionsMu.RUnlock()
+	opts := ScannerOptions{}
+	for k, v := range wasmOptions[name] {
+		opts[k] = v
+	}
+	return opts
+}
+
+type wasmScanner struct {
+	path    string
+	name    string
+	runtime wazero.Runtime
+	module  wazero.CompiledModule
+	limiter *ratelimit.Limiter
+	client  *http.Client
+}
+
+// wasmCall holds the state of a single plugin call, shared with host functions
+type wasmCall struct {
+	options ScannerOptions
+	result  []byte
+}
+
+type wasmCallKey struct{}
+
+// NewWasmScanner compiles the WASM module at the given path
+// and returns a scanner instantiating it for every call.
+func NewWasmScanner(path string) (Scanner, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newWasmScanner(path, code)
+}
+
+func newWasmScanner(path string, code []byte) (Scanner, error) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.Rule{Route: wasmFetchRoute, Requests: wasmRateLimit(), Per: time.Second, Burst: 1})
+	if err != nil {
+		return nil, err
+	}
+	guard, err := netguard.New(netguard.SplitNetworks(os.Getenv(WasmAllowedNetworksEnv))...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+
+	s := &wasmScanner{
+		path:    path,
+		runtime: r,
+		limiter: limiter,
+		client:  &http.Client{Timeout: 30 * time.Second, Transport: TransportOn(guard.Transport())},
+	}
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+	if err := s.instantiateHostModule(ctx); err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+
+	s.module, err = r.CompileModule(ctx, code)
+	if err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+
+	scanner, err := newProtocolScanner(path, s)
+	if err != nil {
+		_ = r.Close(ctx)
+		return nil, err
+	}
+	s.name = scanner.Name()
+	return scanner, nil
+}
+
+func (s *wasmScanner) call(ctx context.Context, req ExecRequest) (*ExecResponse, error) {
+	req.Options = wasmPluginOptions(s.name)
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	config := wazero.NewModuleConfig().
+		// Allow several instances to run concurrently
+		WithName("").
+		WithArgs(s.path).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+
+	ctx = context.WithValue(ctx, wasmCallKey{}, &wasmCall{options: req.Options})
+
+	mod, err := s.runtime.InstantiateModule(ctx, s.module, config)
+	forwardPluginLogs(logs.FromContext(ctx).WithField("plugin", s.path), &stderr)
+	if mod != nil {
+		_ = mod.Close(ctx)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		// WASI modules may explicitly exit with code 0 once done
+		var exitErr *sys.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 0 {
+			return nil, fmt.Errorf("plugin %s failed: %v", s.path, err)
+		}
+	}
+
+	return decodePluginResponse(s.path, stdout.Bytes())
+}
+
+// instantiateHostModule defines the host functions available to plugins.
+// Functions returning data store it in the call state, its size is returned
+// and plugins must then call read_result with a buffer large enough.
+func (s *wasmScanner) instantiateHostModule(ctx context.Context) error {
+	_, err := s.runtime.NewHostModuleBuilder(WasmHostModule).
+		NewFunctionBuilder().
+		WithFunc(s.hostLog).
+		WithParameterNames("level", "ptr", "len").
+		Export("log").
+		NewFunctionBuilder().
+		WithFunc(s.hostOption).
+		WithParameterNames("key_ptr", "key_len").
+		Export("option").
+		NewFunctionBuilder().
+		WithFunc(s.hostFetch).
+		WithParameterNames("req_ptr", "req_len").
+		Export("http_fetch").
+		NewFunctionBuilder().
+		WithFunc(s.hostReadResult).
+		WithParameterNames("ptr").
+		Export("read_result").
+		Instantiate(ctx)
+	return err
+}
+
+func (s *wasmScanner) hostLog(ctx context.Context, m api.Module, level, ptr, size uint32) {
+	msg, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		return
+	}
+	logger := logs.FromContext(ctx).WithField("plugin", s.path)
+	switch level {
+	case 0:
+		logger.Debug(string(msg))
+	case 1:
+		logger.Info(string(msg))
+	case 2:
+		logger.Warn(string(msg))
+	default:
+		logger.Error(string(msg))
+	}
+}
+
+func (s *wasmScanner) hostOption(ctx context.Context, m api.Module, ptr, size uint32) uint32 {
+	call := ctx.Value(wasmCallKey{}).(*wasmCall)
+	key, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		call.result = nil
+		return 0
+	}
+	// Only options configured for the plugin are exposed
+	v, _ := call.options[string(key)].(string)
+	call.result = []byte(v)
+	return uint32(len(call.result))
+}
+
+func (s *wasmScanner) hostFetch(ctx context.Context, m api.Module, ptr, size uint32) uint32 {
+	call := ctx.Value(wasmCallKey{}).(*wasmCall)
+
+	var res WasmFetchResponse
+	if data, ok := m.Memory().Read(ptr, size); !ok {
+		res.Error = "invalid request pointer"
+	} else {
+		res = s.fetch(ctx, data)
+	}
+
+	call.result, _ = json.Marshal(res)
+	return uint32(len(call.result))
+}
+
+func (s *wasmScanner) hostReadResult(ctx context.Context, m api.Module, ptr uint32) {
+	call := ctx.Value(wasmCallKey{}).(*wasmCall)
+	m.Memory().Write(ptr, call.result)
+	call.result = nil
+}
+
+// fetch performs an HTTP request on behalf of the plugin. Requests go
+// through the supplier transport chain, like requests of built-in scanners,
+// and can't reach loopback, private or link-local addresses.
+func (s *wasmScanner) fetch(ctx context.Context, data []byte) WasmFetchResponse {
+	var fr WasmFetchRequest
+	if err := json.Unmarshal(data, &fr); err != nil {
+		return WasmFetchResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+	}
+	if !strings.HasPrefix(fr.URL, "http://") && !strings.HasPrefix(fr.URL, "https://") {
+		return WasmFetchResponse{Error: "only http and https URLs are allowed"}
+	}
+	if fr.Method == "" {
+		fr.Method = http.MethodGet
+	}
+
+	if err := s.limiter.Wait(ctx, wasmFetchRoute, s.path); err != nil {
+		return WasmFetchResponse{Error: err.Error()}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, fr.Method, fr.URL, strings.NewReader(fr.Body))
+	if err != nil {
+		return WasmFetchResponse{Error: err.Error()}
+	}
+	for k, v := range fr.Header {
+		req.Header.Set(k, v)
+	}
+
+	logs.FromContext(ctx).WithField("plugin", s.path).WithField("url", fr.URL).Debug("Plugin is fetching URL")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return WasmFetchResponse{Error: err.Error()}
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxWasmFetchSize))
+	if err != nil {
+		return WasmFetchResponse{Error: err.Error()}
+	}
+
+	header := map[string]string{}
+	for k := range res.Header {
+		header[k] = res.Header.Get(k)
+	}
+
+	return WasmFetchResponse{
+		Status: res.StatusCode,
+		Header: header,
+		Body:   string(body),
+	}
+}
+
+func wasmRateLimit() int {
+	if v, err := strconv.Atoi(os.Getenv(WasmRateLimitEnv)); err == nil && v > 0 {
+		return v
+	}
+	return DefaultWasmRateLimit
+}
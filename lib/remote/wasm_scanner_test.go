@@ -0,0 +1,189 @@
+package remote_test
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote/remotetest"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+)
+
+// goBinary is looked up before tests run, as some of them clear env vars
+var goBinary, _ = exec.LookPath("go")
+var goEnv = os.Environ()
+
+// buildWasmPlugin builds the WASM plugin of the given package, by
+// default the example plugin. It requires Go 1.21 or later.
+func buildWasmPlugin(t *testing.T, pkg ...string) string {
+	src := "../../examples/wasm-plugin"
+	if len(pkg) > 0 {
+		src = pkg[0]
+	}
+	out := filepath.Join(t.TempDir(), "wasmscanner.wasm")
+	cmd := exec.Command(goBinary, "build", "-o", out, src)
+	cmd.Env = append(goEnv, "GOOS=wasip1", "GOARCH=wasm")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("unable to build WASM plugin: %v\n%s", err, output)
+	}
+	return out
+}
+
+// setWasmOptions configures options of WASM plugins for the test
+func setWasmOptions(t *testing.T, options map[string]map[string]string) {
+	remote.SetWasmOptions(options)
+	t.Cleanup(func() { remote.SetWasmOptions(nil) })
+}
+
+func TestWasmScanner(t *testing.T) {
+	path := buildWasmPlugin(t)
+	t.Setenv(remote.WasmAllowedNetworksEnv, "127.0.0.1")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "reported number %s", r.URL.Query().Get("number"))
+	}))
+	defer srv.Close()
+
+	scanner, err := remote.NewWasmScanner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "wasmscanner", scanner.Name())
+	assert.Equal(t, "This is a dummy WASM scanner", scanner.Description())
+	assert.Equal(t, []remote.ScannerOption{
+		{Name: "WASM_SCANNER_URL", Description: "URL to look the number up", Required: true},
+	}, scanner.(remote.OptionsScanner).Options())
+	assert.Equal(t, []string{"network"}, scanner.(remote.TaggedScanner).Tags())
+
+	lib := remote.NewLibrary(filter.NewEngine())
+	lib.AddScanner(scanner)
+
+	got, errs := lib.Scan(test.NewFakeUSNumber(), remote.ScannerOptions{})
+	assert.Equal(t, map[string]interface{}{}, got)
+	assert.Equal(t, map[string]error{}, errs)
+
+	setWasmOptions(t, map[string]map[string]string{"wasmscanner": {"WASM_SCANNER_URL": srv.URL}})
+	got, errs = lib.Scan(test.NewFakeUSNumber(), remote.ScannerOptions{})
+	assert.Equal(t, map[string]error{}, errs)
+	assert.Equal(t, map[string]interface{}{
+		"wasmscanner": map[string]interface{}{
+			"valid":  true,
+			"status": float64(200),
+			"info":   "reported number +14152229670",
+		},
+	}, got)
+
+	remotetest.TestScanner(t, scanner, remotetest.Config{
+		MapResults: true,
+	})
+
+	setWasmOptions(t, map[string]map[string]string{"wasmscanner": {"WASM_SCANNER_URL": "file:///etc/passwd"}})
+	got, errs = lib.Scan(test.NewFakeUSNumber(), remote.ScannerOptions{})
+	assert.Equal(t, map[string]interface{}{}, got)
+	assert.EqualError(t, errs["wasmscanner"], "only http and https URLs are allowed")
+}
+
+func TestWasmScanner_PrivateNetworks(t *testing.T) {
+	path := buildWasmPlugin(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("plugin reached a loopback address")
+	}))
+	defer srv.Close()
+
+	scanner, err := remote.NewWasmScanner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []string{
+		srv.URL,
+		fmt.Sprintf("http://localhost:%d", srv.Listener.Addr().(*net.TCPAddr).Port),
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.1",
+		"http://[::1]:1",
+	}
+
+	for _, u := range testcases {
+		t.Run(u, func(t *testing.T) {
+			setWasmOptions(t, map[string]map[string]string{"wasmscanner": {"WASM_SCANNER_URL": u}})
+			_, err := scanner.Run(*test.NewFakeUSNumber(), remote.ScannerOptions{})
+			assert.ErrorContains(t, err, "destination is a loopback, private or link-local address")
+		})
+	}
+}
+
+func TestWasmScanner_Options(t *testing.T) {
+	path := buildWasmPlugin(t, "./testdata/wasm_options")
+	t.Setenv("NUMVERIFY_API_KEY", "secret")
+	t.Setenv("WASM_DECLARED_OPTION", "declared")
+
+	scanner, err := remote.NewWasmScanner(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		config   map[string]map[string]string
+		opts     remote.ScannerOptions
+		expected map[string]interface{}
+	}{
+		{
+			name: "test option configured for the plugin",
+			config: map[string]map[string]string{
+				"wasmoptions": {"OPTION_NAME": "WASM_DECLARED_OPTION", "WASM_DECLARED_OPTION": "configured"},
+			},
+			expected: map[string]interface{}{"WASM_DECLARED_OPTION": "configured"},
+		},
+		{
+			name: "test declared option isn't read from env",
+			config: map[string]map[string]string{
+				"wasmoptions": {"OPTION_NAME": "WASM_DECLARED_OPTION"},
+			},
+			expected: map[string]interface{}{"WASM_DECLARED_OPTION": ""},
+		},
+		{
+			name: "test option of another scanner isn't exposed",
+			config: map[string]map[string]string{
+				"wasmoptions": {"OPTION_NAME": "NUMVERIFY_API_KEY"},
+				"numverify":   {"NUMVERIFY_API_KEY": "configured"},
+			},
+			opts:     remote.ScannerOptions{"NUMVERIFY_API_KEY": "given"},
+			expected: map[string]interface{}{"NUMVERIFY_API_KEY": ""},
+		},
+		{
+			name: "test options given for the scan aren't exposed",
+			config: map[string]map[string]string{
+				"wasmoptions": {"OPTION_NAME": "WASM_DECLARED_OPTION"},
+			},
+			opts:     remote.ScannerOptions{"WASM_DECLARED_OPTION": "given"},
+			expected: map[string]interface{}{"WASM_DECLARED_OPTION": ""},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			setWasmOptions(t, tt.config)
+			got, err := scanner.Run(*test.NewFakeUSNumber(), tt.opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestWasmScanner_InvalidModule(t *testing.T) {
+	_, err := remote.NewWasmScanner("testdata/exec_plugin.sh")
+	assert.Error(t, err)
+
+	err = remote.OpenPlugin("testdata/doesnotexist.wasm")
+	assert.EqualError(t, err, "given path testdata/doesnotexist.wasm does not exist")
+}
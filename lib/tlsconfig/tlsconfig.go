@@ -0,0 +1,186 @@
+// Package tlsconfig serves TLS from certificate files, which are reloaded
+// when they change on disk, e.g. when they're renewed by a cron job.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Options gives the files to load, the key must be unencrypted
+type Options struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds PEM encoded CA certificates client certificates
+	// are verified with. Client certificates are not requested when it's empty.
+	ClientCAFile string
+}
+
+// Reloader holds the certificate and client CAs loaded from files. Files
+// are checked for changes at most once per second, on new connections.
+type Reloader struct {
+	opts     Options
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    map[string]time.Time
+	checked   time.Time
+}
+
+func NewReloader(opts Options) (*Reloader, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key file")
+	}
+
+	r := &Reloader{opts: opts, interval: time.Second}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Config returns the TLS configuration of the server. When a client CA
+// is configured, client certificates are requested and verified if given,
+// it's up to the server to require them.
+func (r *Reloader) Config() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.reload()
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+	}
+	if r.opts.ClientCAFile != "" {
+		// Certificates are verified by VerifyConnection, as client
+		// CAs of the configuration can't be replaced once it's used
+		config.ClientAuth = tls.RequestClientCert
+		config.VerifyConnection = r.verifyClient
+	}
+	return config
+}
+
+func (r *Reloader) verifyClient(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return nil
+	}
+
+	r.mu.RLock()
+	roots := r.clientCAs
+	r.mu.RUnlock()
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+// reload loads files again when they changed since the last check.
+// The previous certificate is kept when they can't be loaded, such as
+// when the key was renewed but not the certificate yet.
+func (r *Reloader) reload() {
+	r.mu.RLock()
+	due := time.Since(r.checked) >= r.interval
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	r.mu.Lock()
+	r.checked = time.Now()
+	changed := false
+	for path, stamp := range r.stamps {
+		if current, err := modTime(path); err != nil || !current.Equal(stamp) {
+			changed = true
+		}
+	}
+	r.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		logrus.WithField("error", err).Warn("Unable to reload TLS certificate, keeping the previous one")
+		return
+	}
+	logrus.WithField("cert", r.opts.CertFile).Info("TLS certificate reloaded")
+}
+
+func (r *Reloader) load() error {
+	// Times are read first, so changes made while loading are seen next time
+	stamps := map[string]time.Time{}
+	for _, path := range []string{r.opts.CertFile, r.opts.KeyFile, r.opts.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		stamp, err := modTime(path)
+		if err != nil {
+			return err
+		}
+		stamps[path] = stamp
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load TLS certificate: %v", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.opts.ClientCAFile != "" {
+		data, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read client CA file: %v", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("client CA file %s holds no PEM encoded certificate", r.opts.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.stamps = stamps
+	return nil
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// Identity returns the name identifying the owner of a client certificate:
+// its common name, or its first DNS name, email address or URI otherwise
+func Identity(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+)
+
+func writeCert(t *testing.T, ca *test.CertificateAuthority, dir, commonName string, modTime time.Time) {
+	cert, key, err := ca.Issue(commonName, x509.ExtKeyUsageServerAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"cert.pem": cert, "key.pem": key} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, data, 0600))
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+}
+
+func servedName(t *testing.T, config *tls.Config) string {
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	ca, err := test.NewCertificateAuthority("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	now := time.Now()
+	writeCert(t, ca, dir, "first", now.Add(-time.Minute))
+
+	r, err := NewReloader(Options{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")})
+	assert.NoError(t, err)
+	r.interval = 0
+	config := r.Config()
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+	assert.Equal(t, "first", servedName(t, config))
+
+	writeCert(t, ca, dir, "renewed", now)
+	assert.Equal(t, "renewed", servedName(t, config))
+
+	// A partially written certificate is ignored until it's valid
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cert.pem"), []byte("invalid"), 0600))
+	assert.Equal(t, "renewed", servedName(t, config))
+}
+
+func TestNewReloader_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("invalid"), 0600))
+	ca, err := test.NewCertificateAuthority("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCert(t, ca, dir, "server", time.Now())
+
+	testcases := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{
+			name:    "test missing key",
+			opts:    Options{CertFile: filepath.Join(dir, "cert.pem")},
+			wantErr: "TLS requires both a certificate and a key file",
+		},
+		{
+			name:    "test missing certificate file",
+			opts:    Options{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: filepath.Join(dir, "key.pem")},
+			wantErr: "stat " + filepath.Join(dir, "missing.pem") + ": no such file or directory",
+		},
+		{
+			name:    "test invalid client CA",
+			opts:    Options{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem"), ClientCAFile: filepath.Join(dir, "ca.pem")},
+			wantErr: "client CA file " + filepath.Join(dir, "ca.pem") + " holds no PEM encoded certificate",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReloader(tt.opts)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReloader_VerifyClient(t *testing.T) {
+	ca, err := test.NewCertificateAuthority("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := test.NewCertificateAuthority("other CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeCert(t, ca, dir, "server", time.Now())
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "ca.pem"), ca.PEM, 0600))
+
+	r, err := NewReloader(Options{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	})
+	assert.NoError(t, err)
+	config := r.Config()
+	assert.Equal(t, tls.RequestClientCert, config.ClientAuth)
+
+	parse := func(ca *test.CertificateAuthority, usage x509.ExtKeyUsage) []*x509.Certificate {
+		certPEM, keyPEM, err := ca.Issue("client", usage)
+		assert.NoError(t, err)
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		assert.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		assert.NoError(t, err)
+		return []*x509.Certificate{leaf}
+	}
+
+	testcases := []struct {
+		name    string
+		certs   []*x509.Certificate
+		wantErr bool
+	}{
+		{name: "test no client certificate"},
+		{name: "test trusted client certificate", certs: parse(ca, x509.ExtKeyUsageClientAuth)},
+		{name: "test untrusted client certificate", certs: parse(other, x509.ExtKeyUsageClientAuth), wantErr: true},
+		{name: "test server certificate", certs: parse(ca, x509.ExtKeyUsageServerAuth), wantErr: true},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.VerifyConnection(tls.ConnectionState{PeerCertificates: tt.certs})
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	testcases := []struct {
+		name     string
+		cert     *x509.Certificate
+		expected string
+	}{
+		{
+			name:     "test common name",
+			cert:     &x509.Certificate{Subject: pkix.Name{CommonName: "soar"}, DNSNames: []string{"soar.internal"}},
+			expected: "soar",
+		},
+		{
+			name:     "test DNS name",
+			cert:     &x509.Certificate{DNSNames: []string{"soar.internal"}},
+			expected: "soar.internal",
+		},
+		{
+			name:     "test email address",
+			cert:     &x509.Certificate{EmailAddresses: []string{"soc@example.com"}},
+			expected: "soc@example.com",
+		},
+		{
+			name:     "test URI",
+			cert:     &x509.Certificate{URIs: []*url.URL{{Scheme: "spiffe", Host: "example.com", Path: "/soar"}}},
+			expected: "spiffe://example.com/soar",
+		},
+		{
+			name: "test anonymous certificate",
+			cert: &x509.Certificate{},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Identity(tt.cert))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package tracing exports OpenTelemetry traces of HTTP requests, scanners and suppliers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/sundowndev/phoneinfoga/v2/build"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters spans can be sent to
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+const instrumentationName = "github.com/sundowndev/phoneinfoga/v2"
+
+// Attributes set on scanner spans, and on spans of supplier requests they make
+const (
+	ScannerKey     = attribute.Key("phoneinfoga.scanner")
+	CountryCodeKey = attribute.Key("phoneinfoga.country_code")
+	CountryKey     = attribute.Key("phoneinfoga.country")
+	ScanStatusKey  = attribute.Key("phoneinfoga.scan.status")
+)
+
+// Options configures the exporter. The OTLP exporter is
+// configured with standard OTEL_EXPORTER_OTLP_* variables.
+type Options struct {
+	Exporter string
+	// File is the path spans are appended to with the file exporter
+	File string
+}
+
+// Setup installs a global tracer provider sending spans to the given exporter,
+// tracing is a no-op until then. The returned function flushes pending spans.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	var closer io.Closer
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch opts.Exporter {
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterFile:
+		if opts.File == "" {
+			return nil, fmt.Errorf("%s exporter requires a file", ExporterFile)
+		}
+		var file *os.File
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		closer = file
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (otlp, stdout, file)", opts.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceNameKey.String("phoneinfoga"),
+			semconv.ServiceVersionKey.String(build.String()),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// Tracer returns the tracer of the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+type scanAttributesKey struct{}
+
+// StartScanner starts the span of a scanner run. Supplier requests
+// made with the returned context are traced as its children.
+func StartScanner(ctx context.Context, scanner string, n *number.Number) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		ScannerKey.String(scanner),
+		CountryCodeKey.Int(int(n.CountryCode)),
+		CountryKey.String(n.Country),
+	}
+	ctx = context.WithValue(ctx, scanAttributesKey{}, attrs)
+	return Tracer().Start(ctx, "scanner "+scanner, trace.WithAttributes(attrs...))
+}
+
+// EndScanner ends the span of a scanner run with its status
+func EndScanner(span trace.Span, status string, err error) {
+	span.SetAttributes(ScanStatusKey.String(status))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transport traces requests sent through the base transport
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// The query is left out as it may hold API keys or the phone number
+	attrs := []attribute.KeyValue{
+		semconv.HTTPMethodKey.String(req.Method),
+		semconv.HTTPURLKey.String(req.URL.Scheme + "://" + req.URL.Host + req.URL.Path),
+		semconv.NetPeerNameKey.String(req.URL.Hostname()),
+	}
+	if scan, ok := ctx.Value(scanAttributesKey{}).([]attribute.KeyValue); ok {
+		attrs = append(attrs, scan...)
+	}
+
+	ctx, span := Tracer().Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(res.StatusCode))
+	if res.StatusCode >= 400 {
+		span.SetStatus(codes.Error, res.Status)
+	}
+	return res, nil
+}
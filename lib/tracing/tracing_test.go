@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func attributes(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSetup(t *testing.T) {
+	testcases := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{
+			name:    "test unknown exporter",
+			opts:    Options{Exporter: "jaeger"},
+			wantErr: "unknown trace exporter \"jaeger\" (otlp, stdout, file)",
+		},
+		{
+			name:    "test file exporter without file",
+			opts:    Options{Exporter: ExporterFile},
+			wantErr: "file exporter requires a file",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Setup(context.Background(), tt.opts)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterFile, File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ := number.NewNumber("14152229670")
+	_, span := StartScanner(context.Background(), "local", n)
+	EndScanner(span, "success", nil)
+	assert.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"scanner local"`)
+	assert.Contains(t, string(data), `"Value":"phoneinfoga"`)
+}
+
+func TestScannerSpan(t *testing.T) {
+	recorder := useRecorder(t)
+
+	n, _ := number.NewNumber("+33678786545")
+	_, span := StartScanner(context.Background(), "ovh", n)
+	EndScanner(span, "error", assert.AnError)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "scanner ovh", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		ScannerKey:     attribute.StringValue("ovh"),
+		CountryCodeKey: attribute.IntValue(33),
+		CountryKey:     attribute.StringValue("FR"),
+		ScanStatusKey:  attribute.StringValue("error"),
+	}, attributes(spans[0]))
+}
+
+func TestTransport(t *testing.T) {
+	recorder := useRecorder(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	n, _ := number.NewNumber("14152229670")
+	ctx, span := StartScanner(context.Background(), "numverify", n)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/validate?access_key=secret", nil)
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	EndScanner(span, "success", nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	supplier := spans[0]
+	assert.Equal(t, "HTTP GET", supplier.Name())
+	assert.Equal(t, span.SpanContext().SpanID(), supplier.Parent().SpanID())
+	assert.Equal(t, codes.Error, supplier.Status().Code)
+
+	attrs := attributes(supplier)
+	assert.Equal(t, srv.URL+"/validate", attrs["http.url"].AsString())
+	assert.Equal(t, int64(429), attrs["http.status_code"].AsInt64())
+	assert.Equal(t, "numverify", attrs[ScannerKey].AsString())
+	assert.Equal(t, int64(1), attrs[CountryCodeKey].AsInt64())
+}
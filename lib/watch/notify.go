@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+)
+
+// Change is emitted when a new scan of a watched number differs from the previous one
+type Change struct {
+	Number   string     `json:"number"`
+	Previous time.Time  `json:"previous_scan"`
+	Date     time.Time  `json:"date"`
+	Diff     *diff.Diff `json:"diff"`
+}
+
+// Notifier sends changes somewhere
+type Notifier interface {
+	Notify(Change) error
+}
+
+// WriterNotifier writes changes to a writer, as text or JSON lines
+type WriterNotifier struct {
+	w    io.Writer
+	json bool
+}
+
+func NewWriterNotifier(w io.Writer, json bool) *WriterNotifier {
+	return &WriterNotifier{w: w, json: json}
+}
+
+func (n *WriterNotifier) Notify(c Change) error {
+	if n.json {
+		return json.NewEncoder(n.w).Encode(c)
+	}
+	_, err := fmt.Fprintf(n.w, color.WhiteString("Changes for %s since %s\n\n"), c.Number, c.Previous.Local().Format(time.RFC1123))
+	if err != nil {
+		return err
+	}
+	c.Diff.WriteConsole(n.w)
+	_, err = fmt.Fprintln(n.w)
+	return err
+}
+
+// ChangedEvent is the webhook event of changes
+const ChangedEvent = "watch.changed"
+
+// WebhookNotifier posts changes as JSON to a URL,
+// signed like webhooks of the web server
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, secret: secret, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(c Change) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	req, err := webhook.NewRequest(context.Background(), n.url, n.secret, ChangedEvent, "", data)
+	if err != nil {
+		return err
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+)
+
+// StateFileEnv overrides the default location of the watch state file
+const StateFileEnv = "PHONEINFOGA_WATCH_STATE_FILE"
+
+// Entry is the latest scan of a watched number
+type Entry struct {
+	LastScan time.Time              `json:"last_scan"`
+	Results  map[string]interface{} `json:"results"`
+	Errors   map[string]string      `json:"errors"`
+}
+
+func (e *Entry) result() diff.Result {
+	return diff.Result{Results: e.Results, Errors: e.Errors}
+}
+
+// State holds the latest scan of watched numbers, it's persisted
+// so restarting a watch doesn't scan numbers before they're due,
+// nor reports changes that were already reported.
+type State struct {
+	Numbers map[string]*Entry `json:"numbers"`
+
+	path string
+}
+
+// DefaultStatePath returns the state file path from PHONEINFOGA_WATCH_STATE_FILE,
+// defaulting to a file in the user configuration directory.
+func DefaultStatePath() string {
+	if path := os.Getenv(StateFileEnv); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "watch.json"
+	}
+	return filepath.Join(dir, "phoneinfoga", "watch.json")
+}
+
+// LoadState reads the state file at the given path, an empty
+// state is returned if it doesn't exist yet.
+func LoadState(path string) (*State, error) {
+	if path == "" {
+		path = DefaultStatePath()
+	}
+	s := &State{Numbers: map[string]*Entry{}, path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read watch state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("watch state %s is not valid: %v", path, err)
+	}
+	if s.Numbers == nil {
+		s.Numbers = map[string]*Entry{}
+	}
+	return s, nil
+}
+
+func (s *State) Path() string {
+	return s.path
+}
+
+// Save writes the state file, through a temporary file so an
+// interrupted write doesn't corrupt the previous state.
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0750); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,203 @@
+// Package watch periodically scans a list of numbers
+// and reports changes between consecutive scans.
+package watch
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+)
+
+// ScanFunc scans a number, such as remote.Library.ScanContext
+type ScanFunc func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error)
+
+type Watcher struct {
+	numbers   []*number.Number
+	interval  time.Duration
+	scan      ScanFunc
+	state     *State
+	notifiers []Notifier
+	now       func() time.Time
+	// attempts holds when numbers were last scanned without updating
+	// their state, so they're scanned again at the next interval
+	attempts map[string]time.Time
+	timeout  time.Duration
+}
+
+func NewWatcher(numbers []*number.Number, interval time.Duration, scan ScanFunc, state *State, notifiers ...Notifier) *Watcher {
+	return &Watcher{
+		numbers:   numbers,
+		interval:  interval,
+		scan:      scan,
+		state:     state,
+		notifiers: notifiers,
+		now:       time.Now,
+		attempts:  map[string]time.Time{},
+	}
+}
+
+// SetTimeout sets the maximum duration of a scan, such as the timeout of a
+// profile. Scans cut short by the timeout are neither compared nor saved.
+func (w *Watcher) SetTimeout(d time.Duration) {
+	w.timeout = d
+}
+
+// RunOnce scans numbers that are due, and returns the
+// time the next scan is due at. The first scan of a
+// number is saved as a baseline, without reporting it.
+func (w *Watcher) RunOnce(ctx context.Context) (time.Time, error) {
+	for _, n := range w.numbers {
+		if err := ctx.Err(); err != nil {
+			return time.Time{}, err
+		}
+
+		if due, ok := w.due(n); ok && w.now().Before(due) {
+			continue
+		}
+
+		if err := w.scanNumber(ctx, n, w.state.Numbers[n.E164]); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return w.nextRun(), nil
+}
+
+// Run scans numbers when they're due until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	for {
+		next, err := w.RunOnce(ctx)
+		if err != nil {
+			return err
+		}
+
+		logrus.WithField("next", next).Debug("Waiting for next scans")
+		timer := time.NewTimer(next.Sub(w.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *Watcher) scanNumber(ctx context.Context, n *number.Number, previous *Entry) error {
+	logrus.WithField("number", n.E164).Debug("Scanning watched number")
+
+	scanCtx := ctx
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	results, errs := w.scan(scanCtx, n)
+	// Cancelled scans are incomplete, they must not be compared
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Neither are scans cut short by their own timeout. Scanners failing
+	// with a timeout of their own, e.g. of an HTTP client, are errors of
+	// the scan like any other.
+	if scanCtx.Err() != nil {
+		logrus.WithField("number", n.E164).Warn("Scan timed out, it's ignored until the next scan")
+		w.attempts[n.E164] = w.now()
+		return nil
+	}
+
+	entry := &Entry{LastScan: w.now(), Results: results, Errors: map[string]string{}}
+	for name, err := range errs {
+		entry.Errors[name] = err.Error()
+	}
+
+	if previous != nil {
+		d, err := diff.Compare(previous.result(), entry.result())
+		if err != nil {
+			return err
+		}
+		// State is kept until the change is delivered, so it's reported again
+		// at the next scan, possibly twice to notifiers that didn't fail
+		if !d.Empty() && !w.notify(Change{Number: n.E164, Previous: previous.LastScan, Date: entry.LastScan, Diff: d}) {
+			w.attempts[n.E164] = entry.LastScan
+			return nil
+		}
+	}
+
+	delete(w.attempts, n.E164)
+	w.state.Numbers[n.E164] = entry
+	return w.state.Save()
+}
+
+// notify sends the change to all notifiers and returns whether they all
+// succeeded. Failures are logged, and don't prevent other notifiers from
+// being notified.
+func (w *Watcher) notify(c Change) bool {
+	ok := true
+	for _, n := range w.notifiers {
+		if err := n.Notify(c); err != nil {
+			logrus.WithField("number", c.Number).WithField("error", err).Error("Unable to notify changes")
+			ok = false
+		}
+	}
+	return ok
+}
+
+// due returns when the number must be scanned again, if it was scanned before
+func (w *Watcher) due(n *number.Number) (time.Time, bool) {
+	var last time.Time
+	if entry, ok := w.state.Numbers[n.E164]; ok {
+		last = entry.LastScan
+	}
+	if attempt, ok := w.attempts[n.E164]; ok && attempt.After(last) {
+		last = attempt
+	}
+	return last.Add(w.interval), !last.IsZero()
+}
+
+func (w *Watcher) nextRun() time.Time {
+	var next time.Time
+	for _, n := range w.numbers {
+		due, ok := w.due(n)
+		if !ok {
+			due = w.now()
+		}
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next
+}
+
+// ReadNumbers reads numbers from a watch list, one per line.
+// Empty lines and lines starting with # are ignored, invalid
+// numbers are skipped.
+func ReadNumbers(r io.Reader) ([]*number.Number, error) {
+	var numbers []*number.Number
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		n, err := number.NewNumber(line)
+		if err != nil || !number.IsValid(line) {
+			logrus.WithField("input", line).Warn("Skipping invalid phone number")
+			continue
+		}
+		if seen[n.E164] {
+			continue
+		}
+		seen[n.E164] = true
+		numbers = append(numbers, n)
+	}
+	return numbers, scanner.Err()
+}
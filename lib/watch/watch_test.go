@@ -0,0 +1,273 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+)
+
+type fakeNotifier struct {
+	changes []Change
+}
+
+func (n *fakeNotifier) Notify(c Change) error {
+	n.changes = append(n.changes, c)
+	return nil
+}
+
+func TestWatcher(t *testing.T) {
+	numbers, err := ReadNumbers(strings.NewReader("# fraud cases\n+1 415-222-9670\n\ninvalid\n14152229670\n+33679368229\n"))
+	assert.NoError(t, err)
+	assert.Len(t, numbers, 2)
+
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+	state, err := LoadState(statePath)
+	assert.NoError(t, err)
+
+	country := "CA"
+	scans := map[string]int{}
+	scan := func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error) {
+		scans[n.E164]++
+		return map[string]interface{}{"local": map[string]interface{}{"country": country}}, map[string]error{"numverify": errors.New("dummy error")}
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notifier := &fakeNotifier{}
+	w := NewWatcher(numbers, 24*time.Hour, scan, state, notifier)
+	w.now = func() time.Time { return now }
+
+	// First scans are baselines
+	next, err := w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(24*time.Hour), next)
+	assert.Equal(t, map[string]int{"+14152229670": 1, "+33679368229": 1}, scans)
+	assert.Len(t, notifier.changes, 0)
+
+	// Numbers are not due yet
+	now = now.Add(time.Hour)
+	_, err = w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"+14152229670": 1, "+33679368229": 1}, scans)
+
+	// Restarting from the persisted state
+	state, err = LoadState(statePath)
+	assert.NoError(t, err)
+	w = NewWatcher(numbers[:1], 24*time.Hour, scan, state, notifier)
+	w.now = func() time.Time { return now }
+
+	now = now.Add(23 * time.Hour)
+	_, err = w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, scans["+14152229670"])
+	assert.Len(t, notifier.changes, 0)
+
+	now = now.Add(24 * time.Hour)
+	country = "US"
+	_, err = w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{
+		Number:   "+14152229670",
+		Previous: now.Add(-24 * time.Hour),
+		Date:     now,
+		Diff: &diff.Diff{Scanners: []diff.ScannerDiff{
+			{Scanner: "local", Type: diff.Changed, Changes: []diff.Change{
+				{Path: "country", Type: diff.Changed, From: "CA", To: "US"},
+			}},
+		}},
+	}}, notifier.changes)
+}
+
+func TestWatcher_Cancelled(t *testing.T) {
+	numbers, _ := ReadNumbers(strings.NewReader("14152229670"))
+	state, _ := LoadState(filepath.Join(t.TempDir(), "watch.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scan := func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error) {
+		cancel()
+		return map[string]interface{}{}, map[string]error{}
+	}
+
+	_, err := NewWatcher(numbers, time.Hour, scan, state).RunOnce(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, state.Numbers, 0)
+}
+
+type failingNotifier struct {
+	err     error
+	changes []Change
+}
+
+func (n *failingNotifier) Notify(c Change) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.changes = append(n.changes, c)
+	return nil
+}
+
+func TestWatcher_NotifyFailure(t *testing.T) {
+	numbers, _ := ReadNumbers(strings.NewReader("14152229670"))
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+	state, _ := LoadState(statePath)
+
+	country := "CA"
+	scan := func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error) {
+		return map[string]interface{}{"local": map[string]interface{}{"country": country}}, map[string]error{}
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	baseline := now
+	notifier := &failingNotifier{err: errors.New("webhook is down")}
+	w := NewWatcher(numbers, time.Hour, scan, state, notifier)
+	w.now = func() time.Time { return now }
+
+	_, err := w.RunOnce(context.Background())
+	assert.NoError(t, err)
+
+	// The change isn't delivered, the baseline is kept
+	now = now.Add(time.Hour)
+	country = "US"
+	next, err := w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), next)
+	persisted, _ := LoadState(statePath)
+	assert.Equal(t, baseline, persisted.Numbers["+14152229670"].LastScan)
+
+	// It's reported at the next scan once notifiers are back
+	now = now.Add(time.Hour)
+	notifier.err = nil
+	_, err = w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, notifier.changes, 1)
+	assert.Equal(t, baseline, notifier.changes[0].Previous)
+	persisted, _ = LoadState(statePath)
+	assert.Equal(t, now, persisted.Numbers["+14152229670"].LastScan)
+}
+
+func TestWatcher_ScanTimeout(t *testing.T) {
+	numbers, _ := ReadNumbers(strings.NewReader("14152229670"))
+	state, _ := LoadState(filepath.Join(t.TempDir(), "watch.json"))
+
+	timeout := false
+	scan := func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error) {
+		if timeout {
+			<-ctx.Done()
+			return map[string]interface{}{}, map[string]error{"local": ctx.Err(), "numverify": ctx.Err()}
+		}
+		return map[string]interface{}{"local": map[string]interface{}{"country": "US"}}, map[string]error{}
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notifier := &fakeNotifier{}
+	w := NewWatcher(numbers, time.Hour, scan, state, notifier)
+	w.SetTimeout(10 * time.Millisecond)
+	w.now = func() time.Time { return now }
+
+	_, err := w.RunOnce(context.Background())
+	assert.NoError(t, err)
+
+	// Scans cut short by their timeout aren't compared nor saved
+	now = now.Add(time.Hour)
+	timeout = true
+	next, err := w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), next)
+	assert.Len(t, notifier.changes, 0)
+	assert.Equal(t, map[string]interface{}{"local": map[string]interface{}{"country": "US"}}, state.Numbers["+14152229670"].Results)
+}
+
+func TestWatcher_ScannerTimeout(t *testing.T) {
+	numbers, _ := ReadNumbers(strings.NewReader("14152229670"))
+	state, _ := LoadState(filepath.Join(t.TempDir(), "watch.json"))
+
+	failing := false
+	scan := func(ctx context.Context, n *number.Number) (map[string]interface{}, map[string]error) {
+		if failing {
+			// e.g. the timeout of an HTTP client
+			return map[string]interface{}{}, map[string]error{"numverify": fmt.Errorf("request failed: %w", context.DeadlineExceeded)}
+		}
+		return map[string]interface{}{}, map[string]error{}
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	notifier := &fakeNotifier{}
+	w := NewWatcher(numbers, time.Hour, scan, state, notifier)
+	w.SetTimeout(time.Minute)
+	w.now = func() time.Time { return now }
+
+	_, err := w.RunOnce(context.Background())
+	assert.NoError(t, err)
+
+	// Scanners timing out on their own don't make the scan ignored
+	now = now.Add(time.Hour)
+	failing = true
+	_, err = w.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, now, state.Numbers["+14152229670"].LastScan)
+	assert.Equal(t, map[string]string{"numverify": "request failed: context deadline exceeded"}, state.Numbers["+14152229670"].Errors)
+}
+
+func TestLoadState_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.json")
+	state, err := LoadState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, path, state.Path())
+
+	state.Numbers["+14152229670"] = &Entry{LastScan: time.Now().UTC().Truncate(time.Second)}
+	assert.NoError(t, state.Save())
+
+	got, err := LoadState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, state, got)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+	_, err = LoadState(path)
+	assert.EqualError(t, err, "watch state "+path+" is not valid: unexpected end of JSON input")
+}
+
+func TestNotifiers(t *testing.T) {
+	c := Change{
+		Number: "+14152229670",
+		Diff: &diff.Diff{Scanners: []diff.ScannerDiff{
+			{Scanner: "local", Type: diff.Changed, Changes: []diff.Change{{Path: "country", Type: diff.Changed, From: "CA", To: "US"}}},
+		}},
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, NewWriterNotifier(buf, true).Notify(c))
+	var got Change
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, c.Number, got.Number)
+
+	var received []byte
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := &bytes.Buffer{}
+		_, _ = buf.ReadFrom(r.Body)
+		received = buf.Bytes()
+		signature = r.Header.Get(webhook.SignatureHeader)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	assert.NoError(t, NewWebhookNotifier(srv.URL, "secret").Notify(c))
+	assert.JSONEq(t, buf.String(), string(received))
+	assert.True(t, webhook.Verify("secret", received, signature))
+	assert.EqualError(t, NewWebhookNotifier(srv.URL+"/fail", "").Notify(c), "webhook responded with status 500")
+}
@@ -0,0 +1,379 @@
+// Package webhook delivers JSON payloads to callback URLs. Payloads
+// are signed with HMAC-SHA256, failed deliveries are retried with
+// exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/lib/netguard"
+)
+
+const (
+	SecretEnv = "PHONEINFOGA_WEBHOOK_SECRET"
+
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request
+	// body, prefixed with "sha256=", computed with the webhook secret.
+	SignatureHeader = "X-PhoneInfoga-Signature"
+	DeliveryHeader  = "X-PhoneInfoga-Delivery"
+	EventHeader     = "X-PhoneInfoga-Event"
+
+	signaturePrefix = "sha256="
+)
+
+// ErrNoSecret is returned by dispatchers without a secret, receivers
+// couldn't tell payloads apart from forged ones
+var ErrNoSecret = errors.New("webhooks are disabled, a secret must be set in " + SecretEnv + " to sign payloads")
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusFailed    Status = "failed"
+)
+
+// Attempt is a single try to deliver a payload
+type Attempt struct {
+	Date       time.Time `json:"date"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Delivery is a payload sent to a callback URL, along with its attempts
+type Delivery struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Event     string    `json:"event"`
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	Attempts  []Attempt `json:"attempts"`
+	// Owner is the name of the API key the delivery was created with,
+	// deliveries are only listed to their owner
+	Owner string `json:"-"`
+}
+
+type Options struct {
+	// Secret used to sign payloads, deliveries are refused when it's empty
+	Secret string
+	// MaxAttempts is the number of tries before a delivery fails (default 5)
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on each retry (default 1s)
+	Backoff time.Duration
+	// LogSize is the number of deliveries kept in the delivery log (default 100)
+	LogSize int
+	// Guard rejects callback URLs of loopback, private and link-local
+	// addresses, unless they're allowed (default guard allows none)
+	Guard *netguard.Guard
+	// Client delivers payloads, it should connect through the guard (default
+	// client uses the guard transport with a timeout of 30 seconds)
+	Client *http.Client
+}
+
+// Dispatcher sends payloads in the background and keeps a log of latest deliveries
+type Dispatcher struct {
+	opts Options
+
+	// ctx is cancelled on shutdown to abort background jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu         sync.RWMutex
+	deliveries []*Delivery
+	wg         sync.WaitGroup
+	pending    atomic.Int64
+}
+
+func NewDispatcher(opts Options) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.LogSize <= 0 {
+		opts.LogSize = 100
+	}
+	if opts.Guard == nil {
+		// A guard without allowed networks can't fail
+		opts.Guard, _ = netguard.New()
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second, Transport: opts.Guard.Transport()}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{opts: opts, ctx: ctx, cancel: cancel}
+}
+
+// ValidateURL checks the syntax of the given callback URL, its
+// destination is checked by dispatchers before each delivery
+func ValidateURL(callback string) error {
+	u, err := url.Parse(callback)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid callback URL %q: only http and https URLs are allowed", callback)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid callback URL %q: missing host", callback)
+	}
+	return nil
+}
+
+// Sign returns the signature of the given body, as sent in the signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the body, for receivers
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// NewRequest returns a signed POST request of the given payload
+func NewRequest(ctx context.Context, callback, secret, event, deliveryID string, payload []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callback, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if deliveryID != "" {
+		req.Header.Set(DeliveryHeader, deliveryID)
+	}
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, payload))
+	}
+	return req, nil
+}
+
+// ValidateURL checks the given callback URL can be delivered to, its
+// host must not resolve to an address rejected by the guard
+func (d *Dispatcher) ValidateURL(ctx context.Context, callback string) error {
+	if err := ValidateURL(callback); err != nil {
+		return err
+	}
+	u, _ := url.Parse(callback)
+	if _, err := d.opts.Guard.CheckHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("invalid callback URL %q: %w", callback, err)
+	}
+	return nil
+}
+
+// Send delivers the payload in the background, encoded as JSON. The returned
+// delivery is pending, its status is updated in the delivery log. The owner
+// is the API key the delivery is listed to, if any.
+func (d *Dispatcher) Send(owner, callback, event string, payload interface{}) (*Delivery, error) {
+	return d.SendFunc(owner, callback, event, func(context.Context) interface{} { return payload })
+}
+
+// SendFunc is like Send, but the payload is computed in the background
+// first, such as results of a scan that's still running. The given
+// context is cancelled when the dispatcher is shut down.
+func (d *Dispatcher) SendFunc(owner, callback, event string, payload func(ctx context.Context) interface{}) (*Delivery, error) {
+	if d.opts.Secret == "" {
+		return nil, ErrNoSecret
+	}
+	if err := d.ValidateURL(d.ctx, callback); err != nil {
+		return nil, err
+	}
+
+	delivery, err := d.newDelivery(owner, callback, event)
+	if err != nil {
+		return nil, err
+	}
+	// Copied before delivery starts updating it
+	pending := delivery.copy()
+
+	d.wg.Add(1)
+	d.pending.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer d.pending.Add(-1)
+		body, err := json.Marshal(payload(d.ctx))
+		if err != nil {
+			d.mu.Lock()
+			delivery.Status = StatusFailed
+			delivery.Attempts = append(delivery.Attempts, Attempt{Date: time.Now(), Error: err.Error()})
+			d.mu.Unlock()
+			return
+		}
+		d.deliver(delivery, body)
+	}()
+
+	return pending, nil
+}
+
+// Wait blocks until pending deliveries are done, including their payload
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Shutdown waits for pending deliveries like Wait, until the given context
+// is done. Jobs still running are then cancelled and their deliveries fail.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Pending returns the number of background jobs not done yet,
+// computing their payload or delivering it
+func (d *Dispatcher) Pending() int {
+	return int(d.pending.Load())
+}
+
+// Deliveries returns deliveries of the given owner from the delivery log, newest first
+func (d *Dispatcher) Deliveries(owner string) []*Delivery {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	deliveries := make([]*Delivery, 0, len(d.deliveries))
+	for i := len(d.deliveries) - 1; i >= 0; i-- {
+		if d.deliveries[i].Owner == owner {
+			deliveries = append(deliveries, d.deliveries[i].copy())
+		}
+	}
+	return deliveries
+}
+
+// Get returns a delivery of the given owner from the delivery log
+func (d *Dispatcher) Get(id, owner string) (*Delivery, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, delivery := range d.deliveries {
+		if delivery.ID == id && delivery.Owner == owner {
+			return delivery.copy(), true
+		}
+	}
+	return nil, false
+}
+
+func (d *Dispatcher) newDelivery(owner, callback, event string) (*Delivery, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	delivery := &Delivery{
+		ID:        hex.EncodeToString(b),
+		Owner:     owner,
+		URL:       callback,
+		Event:     event,
+		Status:    StatusPending,
+		CreatedAt: time.Now(),
+		Attempts:  []Attempt{},
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deliveries = append(d.deliveries, delivery)
+	if len(d.deliveries) > d.opts.LogSize {
+		d.deliveries = d.deliveries[len(d.deliveries)-d.opts.LogSize:]
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) deliver(delivery *Delivery, body []byte) {
+	backoff := d.opts.Backoff
+	for i := 1; i <= d.opts.MaxAttempts; i++ {
+		attempt := d.attempt(delivery, body)
+
+		d.mu.Lock()
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		if attempt.Error == "" {
+			delivery.Status = StatusDelivered
+		} else if i == d.opts.MaxAttempts {
+			delivery.Status = StatusFailed
+		}
+		d.mu.Unlock()
+
+		if attempt.Error == "" {
+			return
+		}
+		logrus.WithFields(logrus.Fields{
+			"delivery": delivery.ID,
+			"attempt":  i,
+			"error":    attempt.Error,
+		}).Warn("Webhook delivery failed")
+
+		if i < d.opts.MaxAttempts && !d.sleep(backoff) {
+			d.mu.Lock()
+			delivery.Status = StatusFailed
+			d.mu.Unlock()
+			return
+		}
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) attempt(delivery *Delivery, body []byte) Attempt {
+	attempt := Attempt{Date: time.Now()}
+
+	req, err := NewRequest(d.ctx, delivery.URL, d.opts.Secret, delivery.Event, delivery.ID, body)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	res, err := d.opts.Client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer res.Body.Close()
+
+	attempt.StatusCode = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("callback responded with status %d", res.StatusCode)
+	}
+	return attempt
+}
+
+// sleep waits for the given duration, it returns false
+// when the dispatcher was shut down in the meantime
+func (d *Dispatcher) sleep(duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-d.ctx.Done():
+		return false
+	}
+}
+
+func (d *Delivery) copy() *Delivery {
+	c := *d
+	c.Attempts = append([]Attempt{}, d.Attempts...)
+	return &c
+}
@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/netguard"
+)
+
+// newDispatcher returns a dispatcher allowed to deliver to test servers
+func newDispatcher(t *testing.T, opts Options) *Dispatcher {
+	guard, err := netguard.New("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Guard = guard
+	if opts.Secret == "" {
+		opts.Secret = "secret"
+	}
+	return NewDispatcher(opts)
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header)
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	testcases := []struct {
+		name             string
+		failures         int
+		expectedStatus   Status
+		expectedAttempts []int
+	}{
+		{
+			name:             "test delivered on first attempt",
+			expectedStatus:   StatusDelivered,
+			expectedAttempts: []int{200},
+		},
+		{
+			name:             "test delivered after retries",
+			failures:         2,
+			expectedStatus:   StatusDelivered,
+			expectedAttempts: []int{503, 503, 200},
+		},
+		{
+			name:             "test failed delivery",
+			failures:         5,
+			expectedStatus:   StatusFailed,
+			expectedAttempts: []int{503, 503, 503},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &receiver{failures: tt.failures}
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			d := newDispatcher(t, Options{MaxAttempts: 3, Backoff: time.Millisecond})
+			delivery, err := d.Send("", srv.URL, "scan.completed", map[string]string{"number": "+14152229670"})
+			assert.NoError(t, err)
+			assert.Equal(t, StatusPending, delivery.Status)
+
+			d.Wait()
+			assert.Equal(t, 0, d.Pending())
+
+			got, ok := d.Get(delivery.ID, "")
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectedStatus, got.Status)
+			var codes []int
+			for _, a := range got.Attempts {
+				codes = append(codes, a.StatusCode)
+			}
+			assert.Equal(t, tt.expectedAttempts, codes)
+			assert.Equal(t, []*Delivery{got}, d.Deliveries(""))
+
+			for i, body := range r.bodies {
+				assert.JSONEq(t, `{"number": "+14152229670"}`, string(body))
+				assert.Equal(t, delivery.ID, r.headers[i].Get(DeliveryHeader))
+				assert.Equal(t, "scan.completed", r.headers[i].Get(EventHeader))
+				assert.True(t, Verify("secret", body, r.headers[i].Get(SignatureHeader)))
+			}
+		})
+	}
+}
+
+func TestDispatcher_LogSize(t *testing.T) {
+	srv := httptest.NewServer(&receiver{})
+	defer srv.Close()
+
+	d := newDispatcher(t, Options{LogSize: 2})
+	var ids []string
+	for i := 0; i < 3; i++ {
+		delivery, err := d.Send("", srv.URL, "test", i)
+		assert.NoError(t, err)
+		ids = append(ids, delivery.ID)
+	}
+	d.Wait()
+
+	deliveries := d.Deliveries("")
+	assert.Len(t, deliveries, 2)
+	assert.Equal(t, ids[2], deliveries[0].ID)
+	assert.Equal(t, ids[1], deliveries[1].ID)
+	_, ok := d.Get(ids[0], "")
+	assert.False(t, ok)
+}
+
+func TestDispatcher_Owner(t *testing.T) {
+	srv := httptest.NewServer(&receiver{})
+	defer srv.Close()
+
+	d := newDispatcher(t, Options{})
+	delivery, err := d.Send("soar", srv.URL, "test", nil)
+	assert.NoError(t, err)
+	d.Wait()
+
+	assert.Len(t, d.Deliveries("soar"), 1)
+	assert.Empty(t, d.Deliveries("other"))
+	assert.Empty(t, d.Deliveries(""))
+
+	_, ok := d.Get(delivery.ID, "soar")
+	assert.True(t, ok)
+	_, ok = d.Get(delivery.ID, "other")
+	assert.False(t, ok)
+}
+
+func TestDispatcher_Destinations(t *testing.T) {
+	srv := httptest.NewServer(&receiver{})
+	defer srv.Close()
+
+	d := NewDispatcher(Options{Secret: "secret"})
+	testcases := []string{
+		srv.URL,
+		"http://localhost/hook",
+		"http://10.0.0.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+	}
+	for _, callback := range testcases {
+		t.Run(callback, func(t *testing.T) {
+			_, err := d.Send("", callback, "test", nil)
+			assert.ErrorIs(t, err, netguard.ErrForbidden)
+		})
+	}
+	assert.Empty(t, d.Deliveries(""))
+}
+
+func TestDispatcher_NoSecret(t *testing.T) {
+	_, err := NewDispatcher(Options{}).Send("", "https://example.com/hook", "test", nil)
+	assert.ErrorIs(t, err, ErrNoSecret)
+}
+
+func TestValidateURL(t *testing.T) {
+	assert.NoError(t, ValidateURL("https://example.com/hook"))
+	assert.EqualError(t, ValidateURL("file:///etc/passwd"), `invalid callback URL "file:///etc/passwd": only http and https URLs are allowed`)
+	assert.EqualError(t, ValidateURL("http://"), `invalid callback URL "http://": missing host`)
+
+	_, err := NewDispatcher(Options{Secret: "secret"}).Send("", "ftp://example.com", "test", nil)
+	assert.Error(t, err)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"a":"b"}`)
+	assert.Equal(t, "sha256=5782eb1e80a9c0cc789474a5ab9faf38384866cac1c0151663b2ec9d0082743e", Sign("secret", body))
+	assert.True(t, Verify("secret", body, Sign("secret", body)))
+	assert.False(t, Verify("other", body, Sign("secret", body)))
+}
+
+func TestDispatcher_Shutdown(t *testing.T) {
+	testcases := []struct {
+		name           string
+		block          bool
+		expectedError  error
+		expectedStatus Status
+	}{
+		{
+			name:           "test pending delivery completes",
+			expectedStatus: StatusDelivered,
+		},
+		{
+			name:           "test running job cancelled after timeout",
+			block:          true,
+			expectedError:  context.DeadlineExceeded,
+			expectedStatus: StatusFailed,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(&receiver{})
+			defer srv.Close()
+
+			d := newDispatcher(t, Options{Backoff: time.Millisecond})
+			delivery, err := d.SendFunc("", srv.URL, "test", func(ctx context.Context) interface{} {
+				if tt.block {
+					<-ctx.Done()
+				}
+				return "payload"
+			})
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			assert.ErrorIs(t, d.Shutdown(ctx), tt.expectedError)
+			assert.Equal(t, 0, d.Pending())
+
+			got, _ := d.Get(delivery.ID, "")
+			assert.Equal(t, tt.expectedStatus, got.Status)
+		})
+	}
+}
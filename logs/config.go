@@ -1,30 +1,73 @@
 package logs
 
 import (
+	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/sundowndev/phoneinfoga/v2/build"
 	"os"
+	"strconv"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Environment variables configuring logs before the configuration file is loaded
+const (
+	LevelEnv         = "LOG_LEVEL"
+	FormatEnv        = "LOG_FORMAT"
+	FileEnv          = "LOG_FILE"
+	RedactNumbersEnv = "LOG_REDACT_NUMBERS"
 )
 
 type Config struct {
 	Level        logrus.Level
 	ReportCaller bool
+	// Format is either text (default) or json
+	Format string
+	// File receives logs instead of stderr when defined,
+	// it's rotated once it reaches MaxSize megabytes
+	File       string
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+	// RedactNumbers masks phone numbers in messages and fields
+	RedactNumbers bool
 }
 
 func getConfig() Config {
 	config := Config{
 		Level:        logrus.WarnLevel,
 		ReportCaller: false,
+		Format:       os.Getenv(FormatEnv),
+		File:         os.Getenv(FileEnv),
 	}
 
 	if !build.IsRelease() {
 		config.Level = logrus.DebugLevel
 	}
 
-	if lvl := os.Getenv("LOG_LEVEL"); lvl != "" {
+	if lvl := os.Getenv(LevelEnv); lvl != "" {
 		loglevel, _ := logrus.ParseLevel(lvl)
 		config.Level = loglevel
 	}
 
+	if v := os.Getenv(RedactNumbersEnv); v != "" {
+		config.RedactNumbers, _ = strconv.ParseBool(v)
+	}
+
 	return config
 }
+
+func (c Config) formatter() (logrus.Formatter, error) {
+	switch c.Format {
+	case "", FormatText:
+		return &logrus.TextFormatter{}, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (text, json)", c.Format)
+	}
+}
@@ -0,0 +1,22 @@
+package logs
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the given logger, so
+// fields such as a request ID are added to logs down the line
+func NewContext(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by the context, or the standard logger
+func FromContext(ctx context.Context) *logrus.Entry {
+	if logger, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+		return logger
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
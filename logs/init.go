@@ -2,10 +2,51 @@ package logs
 
 import (
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
 )
 
+// file is the rotated log file currently in use, if any
+var file *lumberjack.Logger
+
 func Init() {
-	config := getConfig()
+	if err := Setup(getConfig()); err != nil {
+		logrus.WithField("error", err).Warn("Invalid log configuration")
+	}
+}
+
+// Setup configures the standard logger. It can be called again
+// once the configuration file is loaded.
+func Setup(config Config) error {
+	formatter, err := config.formatter()
+	if err != nil {
+		return err
+	}
+
 	logrus.SetLevel(config.Level)
 	logrus.SetReportCaller(config.ReportCaller)
+	logrus.SetFormatter(formatter)
+
+	hooks := make(logrus.LevelHooks)
+	if config.RedactNumbers {
+		hooks.Add(redactHook{})
+	}
+	logrus.StandardLogger().ReplaceHooks(hooks)
+
+	if file != nil {
+		_ = file.Close()
+		file = nil
+	}
+	if config.File == "" {
+		logrus.SetOutput(os.Stderr)
+		return nil
+	}
+	file = &lumberjack.Logger{
+		Filename:   config.File,
+		MaxSize:    config.MaxSize,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     config.MaxAge,
+	}
+	logrus.SetOutput(file)
+	return nil
 }
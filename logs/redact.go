@@ -0,0 +1,90 @@
+package logs
+
+import (
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Redacted replaces phone numbers in logs
+const Redacted = "[REDACTED]"
+
+// numberFields are fields holding a phone number, they're always redacted
+var numberFields = map[string]bool{
+	"number": true,
+	"input":  true,
+	"phone":  true,
+}
+
+// numberPattern matches phone numbers in international format with at least
+// 7 digits, or in national format with at least 9 digits, so most dates and
+// durations are left as is. Digits may be separated by spaces, dashes or parentheses.
+var numberPattern = regexp.MustCompile(`\+\d(?:[ ()-]{0,2}\d){6,14}|\d(?:[ ()-]{0,2}\d){8,14}`)
+
+// RedactNumbers replaces phone numbers found in s
+func RedactNumbers(s string) string {
+	matches := numberPattern.FindAllStringIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		// Skip digits that are part of a word, such as IDs or hashes
+		if partOfWord(s, m[0]-1, -1) || partOfWord(s, m[1], 1) {
+			continue
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(Redacted)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// partOfWord reports whether the character at i, next to a match, joins
+// it to a word, directly or through a dash as in "1234567890-abc"
+func partOfWord(s string, i, direction int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	if s[i] == '-' {
+		i += direction
+		return i >= 0 && i < len(s) && isWordChar(s[i])
+	}
+	return isWordChar(s[i])
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// redactHook redacts phone numbers from messages and fields of log entries
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = RedactNumbers(entry.Message)
+	for k, v := range entry.Data {
+		var s string
+		switch v := v.(type) {
+		case string:
+			s = v
+		case error:
+			s = v.Error()
+		default:
+			continue
+		}
+		if numberFields[k] && s != "" {
+			entry.Data[k] = Redacted
+		} else if r := RedactNumbers(s); r != s {
+			entry.Data[k] = r
+		}
+	}
+	return nil
+}
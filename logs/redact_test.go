@@ -0,0 +1,81 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRedactNumbers(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "test E164 number",
+			input:    "Scanning +14152229670",
+			expected: "Scanning [REDACTED]",
+		},
+		{
+			name:     "test formatted number",
+			input:    "number: +1 (415) 222-9670.",
+			expected: "number: [REDACTED].",
+		},
+		{
+			name:     "test national number",
+			input:    "0678786545 and 06 78 78 65 45",
+			expected: "[REDACTED] and [REDACTED]",
+		},
+		{
+			name:     "test several numbers",
+			input:    "from +33678786545 to 14152229670",
+			expected: "from [REDACTED] to [REDACTED]",
+		},
+		{
+			name:     "test dates and short numbers",
+			input:    "retry in 30 seconds, quota reset on 2023-06-01",
+			expected: "retry in 30 seconds, quota reset on 2023-06-01",
+		},
+		{
+			name:     "test identifiers",
+			input:    "delivery a4f0123456789b, scan 20230601120000-abc",
+			expected: "delivery a4f0123456789b, scan 20230601120000-abc",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RedactNumbers(tt.input))
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer Init()
+
+	var buf bytes.Buffer
+	assert.NoError(t, Setup(Config{Level: logrus.InfoLevel, Format: FormatJSON, RedactNumbers: true}))
+	logrus.SetOutput(&buf)
+
+	logrus.WithField("number", "+14152229670").
+		WithField("error", errors.New("invalid number 14152229670")).
+		WithField("scanner", "local").
+		Info("Scanning +14152229670")
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	delete(entry, "time")
+	assert.Equal(t, map[string]interface{}{
+		"level":   "info",
+		"msg":     "Scanning [REDACTED]",
+		"number":  "[REDACTED]",
+		"error":   "invalid number [REDACTED]",
+		"scanner": "local",
+	}, entry)
+
+	assert.EqualError(t, Setup(Config{Format: "xml"}), "unknown log format \"xml\" (text, json)")
+}
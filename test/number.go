@@ -6,3 +6,23 @@ func NewFakeUSNumber() *number.Number {
 	n, _ := number.NewNumber("+1.4152229670")
 	return n
 }
+
+// NewEdgeCaseNumbers returns unusual numbers that can still be parsed,
+// such as invalid, very short or very long numbers, or numbers without
+// any country. Scanners are expected to handle them without panicking.
+func NewEdgeCaseNumbers() []*number.Number {
+	inputs := []string{
+		"15556661212",       // Invalid US number
+		"+2905123",          // Short number (Saint Helena)
+		"+4930123456789012", // Long number (Germany)
+		"+88163200000",      // Satellite network, no country
+		"+80012345678",      // International toll free, no country
+	}
+
+	var numbers []*number.Number
+	for _, in := range inputs {
+		n, _ := number.NewNumber(in)
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+	"time"
+)
+
+// accessLog logs each request once it's served. Requests are logged by route
+// template, since paths of most routes hold phone numbers. Paths of unmatched
+// requests are logged with numbers redacted.
+func accessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = logs.RedactNumbers(c.Request.URL.Path)
+		}
+		// The request ID is added to the logger of the request context by API routes
+		logs.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":    c.Request.Method,
+			"route":     route,
+			"status":    c.Writer.Status(),
+			"duration":  time.Since(start).String(),
+			"client_ip": c.ClientIP(),
+		}).Info("Request served")
+	}
+}
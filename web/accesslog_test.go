@@ -0,0 +1,83 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	var logs, ginLogs bytes.Buffer
+	logrus.SetOutput(&logs)
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.InfoLevel)
+	defer func() {
+		logrus.SetOutput(os.Stderr)
+		logrus.SetFormatter(&logrus.TextFormatter{})
+		logrus.SetLevel(level)
+	}()
+
+	// Gin request logs write there, it's set before they're created
+	writer := gin.DefaultWriter
+	gin.DefaultWriter = &ginLogs
+	defer func() { gin.DefaultWriter = writer }()
+
+	srv, err := NewServer(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		path     string
+		expected map[string]interface{}
+	}{
+		{
+			name: "test route template",
+			path: "/api/numbers/14152229670/validate",
+			expected: map[string]interface{}{
+				"method": "GET",
+				"route":  "/api/numbers/:number/validate",
+				"status": float64(200),
+			},
+		},
+		{
+			name: "test unmatched path",
+			path: "/unknown/+14152229670",
+			expected: map[string]interface{}{
+				"method": "GET",
+				"route":  "/unknown/[REDACTED]",
+				"status": float64(404),
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			ginLogs.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			srv.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.NotContains(t, logs.String(), "4152229670")
+			assert.Empty(t, ginLogs.String())
+
+			var entry map[string]interface{}
+			if !assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry)) {
+				return
+			}
+			assert.Equal(t, "Request served", entry["msg"])
+			for k, v := range tt.expected {
+				assert.Equal(t, v, entry[k], k)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"crypto/x509"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tlsconfig"
+	webErrors "github.com/sundowndev/phoneinfoga/v2/web/errors"
+	"strings"
+)
+
+// apiKey returns the API key of the request, from the API key header or a bearer token
+func apiKey(c *gin.Context) string {
+	if key := c.GetHeader(auth.Header); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// publicRoute reports whether the route is open to unauthenticated
+// clients, which are the health check and readiness probe
+func publicRoute(c *gin.Context) bool {
+	return c.Request.Method == "GET" && (c.FullPath() == "/api/" || c.FullPath() == "/api/v2/health/ready")
+}
+
+// clientCertificate returns the verified TLS client certificate of the request, if any
+func clientCertificate(c *gin.Context) *x509.Certificate {
+	if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return c.Request.TLS.PeerCertificates[0]
+}
+
+// requireClientCert rejects requests without a TLS client certificate,
+// except for the health check route
+func requireClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if publicRoute(c) || clientCertificate(c) != nil {
+			c.Next()
+			return
+		}
+		handleError(c, webErrors.NewUnauthorized(errors.New("missing TLS client certificate")))
+	}
+}
+
+// authenticate requires a valid API key, except for the health check route.
+// Clients with a certificate bound to a key don't need to send the key.
+// The key is attached to the request context, so handlers can check its scanners.
+func authenticate(keyring *auth.Keyring) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if publicRoute(c) {
+			c.Next()
+			return
+		}
+
+		key, err := keyring.Authenticate(apiKey(c))
+		if cert := clientCertificate(c); cert != nil && err == auth.ErrMissingKey {
+			key, err = keyring.AuthenticateCert(tlsconfig.Identity(cert))
+		}
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="phoneinfoga"`)
+			handleError(c, webErrors.NewUnauthorized(err))
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), key))
+		c.Next()
+	}
+}
+
+// allowScanner rejects requests whose API key isn't allowed to use the given scanner
+func allowScanner(s remote.Scanner) gin.HandlerFunc {
+	name, tags := s.Name(), remote.ScannerTags(s)
+	return func(c *gin.Context) {
+		if k := auth.FromContext(c.Request.Context()); k != nil && !k.AllowsScanner(name, tags...) {
+			handleError(c, webErrors.NewForbidden(errors.New("scanner not allowed for this API key")))
+			return
+		}
+		c.Next()
+	}
+}
+
+// unrestrictedKey rejects requests whose API key is limited to some scanners,
+// for routes returning scan history that isn't split per scanner
+func unrestrictedKey(c *gin.Context) {
+	if k := auth.FromContext(c.Request.Context()); k != nil && k.Restricted() {
+		handleError(c, webErrors.NewForbidden(errors.New("scan history is not available to API keys limited to some scanners")))
+		return
+	}
+	c.Next()
+}
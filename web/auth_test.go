@@ -0,0 +1,153 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthentication(t *testing.T) {
+	localScanner := &mocks.Scanner{}
+	localScanner.On("Name").Return("local")
+	localScanner.On("Description").Return("local scanner")
+	paidScanner := &mocks.Scanner{}
+	paidScanner.On("Name").Return("numverify")
+	paidScanner.On("Description").Return("paid scanner")
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(localScanner)
+	handlers.RemoteLibrary.AddScanner(paidScanner)
+
+	keyring, err := auth.NewKeyring(
+		auth.Key{Name: "admin", Hash: auth.Hash("admin-key")},
+		auth.Key{Name: "restricted", Hash: auth.Hash("restricted-key"), Scanners: []string{"local"}},
+		auth.Key{Name: "revoked", Hash: auth.Hash("revoked-key"), Disabled: true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServer(true, WithKeyring(keyring))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name         string
+		path         string
+		headers      map[string]string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "test public health check",
+			path:         "/api/",
+			expectedCode: 200,
+			expectedBody: `{"success":true,"version":"dev","commit":"dev","demo":false,"auth":true}`,
+		},
+		{
+			name:         "test public readiness probe",
+			path:         "/api/v2/health/ready",
+			expectedCode: 200,
+			expectedBody: `{"ready":true,"checks":[{"name":"scanners","ready":true}]}`,
+		},
+		{
+			name:         "test missing key",
+			path:         "/api/v2/scanners",
+			expectedCode: 401,
+			expectedBody: `{"success":false,"error":"missing API key"}`,
+		},
+		{
+			name:         "test invalid key",
+			path:         "/api/v2/scanners",
+			headers:      map[string]string{"X-API-Key": "unknown"},
+			expectedCode: 401,
+			expectedBody: `{"success":false,"error":"invalid API key"}`,
+		},
+		{
+			name:         "test disabled key",
+			path:         "/api/v2/scanners",
+			headers:      map[string]string{"X-API-Key": "revoked-key"},
+			expectedCode: 401,
+			expectedBody: `{"success":false,"error":"API key is disabled"}`,
+		},
+		{
+			name:         "test key in header",
+			path:         "/api/v2/scanners",
+			headers:      map[string]string{"X-API-Key": "admin-key"},
+			expectedCode: 200,
+			expectedBody: `{"scanners":[{"name":"local","description":"local scanner"},{"name":"numverify","description":"paid scanner"}]}`,
+		},
+		{
+			name:         "test key as bearer token",
+			path:         "/api/v2/scanners",
+			headers:      map[string]string{"Authorization": "Bearer restricted-key"},
+			expectedCode: 200,
+			expectedBody: `{"scanners":[{"name":"local","description":"local scanner"}]}`,
+		},
+		{
+			name:         "test allowed v1 scanner",
+			path:         "/api/numbers/14152229670/scan/local",
+			headers:      map[string]string{"X-API-Key": "restricted-key"},
+			expectedCode: 200,
+		},
+		{
+			name:         "test forbidden v1 scanner",
+			path:         "/api/numbers/14152229670/scan/numverify",
+			headers:      map[string]string{"X-API-Key": "restricted-key"},
+			expectedCode: 403,
+			expectedBody: `{"success":false,"error":"scanner not allowed for this API key"}`,
+		},
+		{
+			name:         "test scanned numbers with unrestricted key",
+			path:         "/api/numbers",
+			headers:      map[string]string{"X-API-Key": "admin-key"},
+			expectedCode: 200,
+			expectedBody: `{"success":true,"numbers":[]}`,
+		},
+		{
+			name:         "test scanned numbers with restricted key",
+			path:         "/api/numbers",
+			headers:      map[string]string{"X-API-Key": "restricted-key"},
+			expectedCode: 403,
+			expectedBody: `{"success":false,"error":"scan history is not available to API keys limited to some scanners"}`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("test forbidden v2 scanner", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, "/api/v2/scanners/numverify/run", strings.NewReader(`{"number":"14152229670"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-API-Key", "restricted-key")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+
+		assert.Equal(t, 403, w.Code)
+		assert.Equal(t, `{"error":"Scanner not allowed for this API key"}`, w.Body.String())
+	})
+}
@@ -3,4 +3,5 @@ export default {
   appDescription:
     "Advanced information gathering & OSINT tool for phone numbers",
   apiUrl: "/api",
+  apiKeyStorageKey: "phoneinfoga-api-key",
 };
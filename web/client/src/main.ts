@@ -1,12 +1,23 @@
 import Vue from "vue";
 import { BootstrapVue, IconsPlugin } from "bootstrap-vue";
+import axios from "axios";
 
 import App from "./App.vue";
 import router from "./router";
 import store from "./store";
+import config from "./config";
 
 Vue.config.productionTip = false;
 
+// Send the API key, if any, with every request to the REST API
+axios.interceptors.request.use((req) => {
+  const key = localStorage.getItem(config.apiKeyStorageKey);
+  if (key) {
+    req.headers = { ...req.headers, "X-API-Key": key };
+  }
+  return req;
+});
+
 // Install BootstrapVue
 Vue.use(BootstrapVue);
 // Optionally install the BootstrapVue icon components plugin
@@ -7,6 +7,7 @@ import (
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote/suppliers"
 	"github.com/sundowndev/phoneinfoga/v2/web/errors"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
 	"net/http"
 )
 
@@ -25,20 +26,40 @@ type healthResponse struct {
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
 	Demo    bool   `json:"demo"`
+	// Auth is true when API routes require an API key
+	Auth bool `json:"auth"`
 }
 
 // @ID getAllNumbers
 // @Tags Numbers
 // @Summary Fetch all previously scanned numbers.
-// @Description This route is actually not used yet.
+// @Description This route returns numbers found in scan history. It's forbidden to API keys limited to some scanners.
 // @Deprecated
 // @Produce  json
 // @Success 200 {object} getAllNumbersResponse
+// @Success 403 {object} JSONResponse
+// @Security ApiKeyAuth
 // @Router /numbers [get]
 func getAllNumbers(c *gin.Context) {
+	numbers := []number.Number{}
+	if handlers.History != nil {
+		scanned, err := handlers.History.Numbers()
+		if err != nil {
+			handleError(c, errors.NewInternalError(err))
+			return
+		}
+		for _, n := range scanned {
+			num, err := number.NewNumber(n)
+			if err != nil {
+				continue
+			}
+			numbers = append(numbers, *num)
+		}
+	}
+
 	c.JSON(http.StatusOK, getAllNumbersResponse{
 		JSONResponse: JSONResponse{Success: true},
-		Numbers:      []number.Number{},
+		Numbers:      numbers,
 	})
 }
 
@@ -49,6 +70,7 @@ func getAllNumbers(c *gin.Context) {
 // @Deprecated
 // @Success 200 {object} JSONResponse
 // @Success 400 {object} JSONResponse
+// @Security ApiKeyAuth
 // @Router /numbers/{number}/validate [get]
 // @Param number path string true "Input phone number" validate(required)
 func validate(c *gin.Context) {
@@ -67,6 +89,7 @@ func validate(c *gin.Context) {
 // @Deprecated
 // @Success 200 {object} ScanResultResponse{result=number.Number}
 // @Success 400 {object} JSONResponse
+// @Security ApiKeyAuth
 // @Router /numbers/{number}/scan/local [get]
 // @Param number path string true "Input phone number" validate(required)
 func localScan(c *gin.Context) {
@@ -95,6 +118,7 @@ func localScan(c *gin.Context) {
 // @Produce  json
 // @Success 200 {object} ScanResultResponse{result=remote.NumverifyScannerResponse}
 // @Success 400 {object} JSONResponse
+// @Security ApiKeyAuth
 // @Router /numbers/{number}/scan/numverify [get]
 // @Param number path string true "Input phone number" validate(required)
 func numverifyScan(c *gin.Context) {
@@ -123,6 +147,7 @@ func numverifyScan(c *gin.Context) {
 // @Produce  json
 // @Success 200 {object} ScanResultResponse{result=remote.GoogleSearchResponse}
 // @Success 400 {object} JSONResponse
+// @Security ApiKeyAuth
 // @Router /numbers/{number}/scan/googlesearch [get]
 // @Param number path string true "Input phone number" validate(required)
 func googleSearchScan(c *gin.Context) {
@@ -151,6 +176,7 @@ func googleSearchScan(c *gin.Context) {
 // @Produce  json
 // @Success 200 {object} ScanResultResponse{result=remote.OVHScannerResponse}
 // @Success 400 {object} JSONResponse
+// @Security ApiKeyAuth
 // @Router /numbers/{number}/scan/ovh [get]
 // @Param number path string true "Input phone number" validate(required)
 func ovhScan(c *gin.Context) {
@@ -179,11 +205,12 @@ func ovhScan(c *gin.Context) {
 // @Success 200 {object} healthResponse
 // @Success 500 {object} JSONResponse
 // @Router / [get]
-func healthHandler(c *gin.Context) {
+func (s *Server) healthHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, healthResponse{
 		Success: true,
 		Version: build.Version,
 		Commit:  build.Commit,
 		Demo:    build.IsDemo(),
+		Auth:    s.keyring.Len() > 0,
 	})
 }
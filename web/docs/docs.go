@@ -47,7 +47,12 @@ const docTemplate = `{
         },
         "/numbers": {
             "get": {
-                "description": "This route is actually not used yet.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route returns numbers found in scan history. It's forbidden to API keys limited to some scanners.",
                 "produces": [
                     "application/json"
                 ],
@@ -63,12 +68,23 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/web.getAllNumbersResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/web.JSONResponse"
+                        }
                     }
                 }
             }
         },
         "/numbers/{number}/scan/googlesearch": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -117,6 +133,11 @@ const docTemplate = `{
         },
         "/numbers/{number}/scan/local": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -165,6 +186,11 @@ const docTemplate = `{
         },
         "/numbers/{number}/scan/numverify": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -213,6 +239,11 @@ const docTemplate = `{
         },
         "/numbers/{number}/scan/ovh": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -261,6 +292,11 @@ const docTemplate = `{
         },
         "/numbers/{number}/validate": {
             "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -295,8 +331,118 @@ const docTemplate = `{
                 }
             }
         },
+        "/v2/diffs": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route returns differences between two scans, per scanner. Scans are given as IDs from scan history, or as results returned by the scan route. Results of scans from history are limited to scanners the API key is allowed to use.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Compare results of two scans.",
+                "operationId": "Diff",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DiffInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/diff.Diff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/health/ready": {
+            "get": {
+                "description": "This route checks scanners are loaded and the scan history can be written, when enabled. It responds with a 503 status when a check fails, for readiness probes. It doesn't require an API key.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Check the server is ready to serve scans.",
+                "operationId": "HealthReady",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthReadyResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthReadyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/health/scanners": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route dry runs scanners the API key is allowed to use with a reference number, using options of the server environment. Outcomes are cached for 30 seconds, and dry runs taking more than 5 seconds are reported as not ready. Scanners that aren't ready, e.g. because their API key isn't configured, come with the reason. Plugins are flagged, errors of plugins that failed to load are listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Check which scanners are ready to run.",
+                "operationId": "HealthScanners",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HealthScannersResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/v2/numbers": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "This route returns information about a given phone number.",
                 "consumes": [
                     "application/json"
@@ -338,7 +484,12 @@ const docTemplate = `{
         },
         "/v2/scanners": {
             "get": {
-                "description": "This route returns all available scanners.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route returns all available scanners, restricted to the ones the API key is allowed to use.",
                 "produces": [
                     "application/json"
                 ],
@@ -359,6 +510,11 @@ const docTemplate = `{
         },
         "/v2/scanners/{scanner}/dryrun": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "This route performs a dry run with the given phone number. This doesn't perform an actual scan.",
                 "consumes": [
                     "application/json"
@@ -396,6 +552,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.DryRunScannerResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -413,7 +575,12 @@ const docTemplate = `{
         },
         "/v2/scanners/{scanner}/run": {
             "post": {
-                "description": "This route runs a single scanner with the given phone number",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route runs a single scanner with the given phone number. When a callback URL is given, the scanner runs in the background and its result is posted to the URL, like with scans. Callback URLs of loopback, private or link-local addresses are refused, unless their network is allowed by the server.",
                 "consumes": [
                     "application/json"
                 ],
@@ -450,6 +617,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/handlers.RunScannerResponse"
                         }
                     },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScanAcceptedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -464,6 +649,125 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/v2/scans": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route runs all scanners the API key is allowed to use, or the ones of the given profile, with the given phone number. Options given in the request override profile options. When a callback URL is given, the scan runs in the background and its results are posted to the URL, see the webhook deliveries route. Callback URLs of loopback, private or link-local addresses are refused, unless their network is allowed by the server.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Numbers"
+                ],
+                "summary": "Scan a number with all scanners.",
+                "operationId": "Scan",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScanInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScanResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScanAcceptedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route returns the latest deliveries to callback URLs created with the API key of the request, newest first, along with their attempts. Failed deliveries are retried with exponential backoff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get latest webhook deliveries.",
+                "operationId": "GetAllDeliveries",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GetAllDeliveriesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/deliveries/{delivery}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "This route returns a delivery to a callback URL, along with its attempts. Deliveries created with another API key are not found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook delivery.",
+                "operationId": "GetDelivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
@@ -475,6 +779,60 @@ const docTemplate = `{
                 }
             }
         },
+        "diff.Change": {
+            "type": "object",
+            "properties": {
+                "from": {},
+                "path": {
+                    "type": "string"
+                },
+                "to": {},
+                "type": {
+                    "$ref": "#/definitions/diff.ChangeType"
+                }
+            }
+        },
+        "diff.ChangeType": {
+            "type": "string",
+            "enum": [
+                "added",
+                "removed",
+                "changed"
+            ],
+            "x-enum-varnames": [
+                "Added",
+                "Removed",
+                "Changed"
+            ]
+        },
+        "diff.Diff": {
+            "type": "object",
+            "properties": {
+                "scanners": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/diff.ScannerDiff"
+                    }
+                }
+            }
+        },
+        "diff.ScannerDiff": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/diff.Change"
+                    }
+                },
+                "scanner": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/diff.ChangeType"
+                }
+            }
+        },
         "handlers.AddNumberInput": {
             "type": "object",
             "required": [
@@ -515,6 +873,39 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.DiffInput": {
+            "type": "object",
+            "required": [
+                "from",
+                "to"
+            ],
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/handlers.DiffScan"
+                },
+                "to": {
+                    "$ref": "#/definitions/handlers.DiffScan"
+                }
+            }
+        },
+        "handlers.DiffScan": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
         "handlers.DryRunScannerInput": {
             "type": "object",
             "required": [
@@ -541,6 +932,17 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.GetAllDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Delivery"
+                    }
+                }
+            }
+        },
         "handlers.GetAllScannersResponse": {
             "type": "object",
             "properties": {
@@ -552,6 +954,52 @@ const docTemplate = `{
                 }
             }
         },
+        "handlers.HealthCheck": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.HealthReadyResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.HealthCheck"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handlers.HealthScannersResponse": {
+            "type": "object",
+            "properties": {
+                "plugin_errors": {
+                    "description": "PluginErrors are errors of plugins that failed to load,\nthey're not given to API keys restricted to some scanners",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scanners": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.ScannerHealth"
+                    }
+                }
+            }
+        },
         "handlers.RunScannerInput": {
             "type": "object",
             "required": [
@@ -559,6 +1007,10 @@ const docTemplate = `{
                 "options"
             ],
             "properties": {
+                "callback_url": {
+                    "description": "CallbackURL receives the result once the scanner is done, instead of the response",
+                    "type": "string"
+                },
                 "number": {
                     "type": "string"
                 },
@@ -573,6 +1025,51 @@ const docTemplate = `{
                 "result": {}
             }
         },
+        "handlers.ScanAcceptedResponse": {
+            "type": "object",
+            "properties": {
+                "delivery": {
+                    "$ref": "#/definitions/webhook.Delivery"
+                }
+            }
+        },
+        "handlers.ScanInput": {
+            "type": "object",
+            "required": [
+                "number",
+                "options"
+            ],
+            "properties": {
+                "callback_url": {
+                    "description": "CallbackURL receives results once the scan is done, instead of the response",
+                    "type": "string"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "options": {
+                    "$ref": "#/definitions/remote.ScannerOptions"
+                },
+                "profile": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.ScanResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "results": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
         "handlers.Scanner": {
             "type": "object",
             "properties": {
@@ -581,6 +1078,36 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.ScannerHealth": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "plugin": {
+                    "type": "boolean"
+                },
+                "ready": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "Reason is why the scanner doesn't run, such as missing credentials",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -619,6 +1146,9 @@ const docTemplate = `{
                 "dork": {
                     "type": "string"
                 },
+                "engine": {
+                    "type": "string"
+                },
                 "number": {
                     "type": "string"
                 },
@@ -770,6 +1300,10 @@ const docTemplate = `{
         "web.healthResponse": {
             "type": "object",
             "properties": {
+                "auth": {
+                    "description": "Auth is true when API routes require an API key",
+                    "type": "boolean"
+                },
                 "commit": {
                     "type": "string"
                 },
@@ -783,6 +1317,67 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "webhook.Attempt": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Attempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/webhook.Status"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusDelivered",
+                "StatusFailed"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Required on all routes but health checks when API keys are configured.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -44,3 +44,33 @@ func NewInternalError(err error) *Error {
 		err:    err,
 	}
 }
+
+func NewUnauthorized(err error) *Error {
+	if err == nil {
+		err = errors.New("unauthorized")
+	}
+	return &Error{
+		status: http.StatusUnauthorized,
+		err:    err,
+	}
+}
+
+func NewForbidden(err error) *Error {
+	if err == nil {
+		err = errors.New("forbidden")
+	}
+	return &Error{
+		status: http.StatusForbidden,
+		err:    err,
+	}
+}
+
+func NewTooManyRequests(err error) *Error {
+	if err == nil {
+		err = errors.New("too many requests")
+	}
+	return &Error{
+		status: http.StatusTooManyRequests,
+		err:    err,
+	}
+}
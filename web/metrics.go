@@ -0,0 +1,22 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
+	"time"
+)
+
+// instrument records requests in metrics, by route template so
+// numbers given in paths don't end up in metric labels
+func instrument(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
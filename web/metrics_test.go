@@ -0,0 +1,71 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	srv, err := NewServer(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := performRequest(srv, http.MethodGet, "/metrics")
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.Code)
+
+	srv, err = NewServer(true, WithMetrics(metrics.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = performRequest(srv, http.MethodGet, "/api/numbers/14152229670/validate")
+	assert.NoError(t, err)
+	_, err = performRequest(srv, http.MethodGet, "/api/notfound")
+	assert.NoError(t, err)
+
+	res, err = performRequest(srv, http.MethodGet, "/metrics")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.Code)
+
+	body, _ := io.ReadAll(res.Body)
+	assert.Contains(t, string(body), `phoneinfoga_http_requests_total{code="200",method="GET",route="/api/numbers/:number/validate"} 1`)
+	assert.Contains(t, string(body), `phoneinfoga_http_requests_total{code="404",method="GET",route="unmatched"} 1`)
+}
+
+func TestMetrics_Protected(t *testing.T) {
+	keyring, err := auth.NewKeyring(auth.Key{Name: "prometheus", Hash: auth.Hash("prometheus-key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := ratelimit.NewLimiter(ratelimit.Rule{Route: "/metrics", Requests: 1, Per: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServer(true, WithMetrics(metrics.New()), WithKeyring(keyring), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(key string) int {
+		req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+		if key != "" {
+			req.Header.Set(auth.Header, key)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, 401, request(""))
+	assert.Equal(t, 401, request("unknown"))
+	assert.Equal(t, 200, request("prometheus-key"))
+	assert.Equal(t, 429, request("prometheus-key"))
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tlsconfig"
+	webErrors "github.com/sundowndev/phoneinfoga/v2/web/errors"
+	"math"
+	"strconv"
+)
+
+// Response headers of routes with a daily quota
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	QuotaResetHeader     = "X-Quota-Reset"
+)
+
+// clientID identifies clients by API key when authenticated, by
+// TLS client certificate when given, by IP otherwise
+func clientID(c *gin.Context) string {
+	if k := auth.FromContext(c.Request.Context()); k != nil {
+		return "key:" + k.Name
+	}
+	if cert := clientCertificate(c); cert != nil {
+		return "cert:" + tlsconfig.Identity(cert)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// rateLimit rejects requests of clients exceeding the rate limit or daily quota of the route
+func rateLimit(l *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		res := l.Allow(c.FullPath(), clientID(c))
+		if res.Quota > 0 {
+			c.Header(QuotaLimitHeader, strconv.Itoa(res.Quota))
+			c.Header(QuotaRemainingHeader, strconv.Itoa(res.QuotaRemaining))
+			c.Header(QuotaResetHeader, strconv.FormatInt(res.QuotaReset.Unix(), 10))
+		}
+		if res.Allowed {
+			c.Next()
+			return
+		}
+
+		retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		msg := fmt.Sprintf("rate limit exceeded, retry in %d seconds", retryAfter)
+		if res.QuotaExceeded {
+			msg = "daily quota exceeded"
+		}
+		handleError(c, webErrors.NewTooManyRequests(errors.New(msg)))
+	}
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	keyring, err := auth.NewKeyring(
+		auth.Key{Name: "a", Hash: auth.Hash("a-key")},
+		auth.Key{Name: "b", Hash: auth.Hash("b-key")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := ratelimit.NewLimiter(ratelimit.Rule{
+		Route:      "/api/numbers/:number/*",
+		Requests:   1,
+		Per:        time.Minute,
+		DailyQuota: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServer(true, WithKeyring(keyring), WithRateLimiter(limiter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(key string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/api/numbers/14152229670/validate", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(auth.Header, key)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	res := request("a-key")
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "5", res.Header().Get(QuotaLimitHeader))
+	assert.Equal(t, "4", res.Header().Get(QuotaRemainingHeader))
+	reset, err := strconv.ParseInt(res.Header().Get(QuotaResetHeader), 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour).Add(24*time.Hour).Unix(), reset)
+
+	res = request("a-key")
+	assert.Equal(t, 429, res.Code)
+	assert.Equal(t, "60", res.Header().Get("Retry-After"))
+	assert.Equal(t, "4", res.Header().Get(QuotaRemainingHeader))
+	assert.Equal(t, `{"success":false,"error":"rate limit exceeded, retry in 60 seconds"}`, res.Body.String())
+
+	// Clients are limited separately
+	res = request("b-key")
+	assert.Equal(t, 200, res.Code)
+
+	// Routes without rules are not limited
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/api/", nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+		assert.Empty(t, w.Header().Get(QuotaLimitHeader))
+	}
+}
+
+func TestRateLimit_ForwardedFor(t *testing.T) {
+	testcases := []struct {
+		name         string
+		opts         []Option
+		expectedCode int
+	}{
+		{
+			name:         "test spoofed header ignored",
+			expectedCode: 429,
+		},
+		{
+			name:         "test header of trusted proxy",
+			opts:         []Option{WithTrustedProxies([]string{"192.0.2.0/24"})},
+			expectedCode: 200,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := ratelimit.NewLimiter(ratelimit.Rule{Route: "/api/numbers/:number/*", Requests: 1, Per: time.Minute})
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv, err := NewServer(true, append(tt.opts, WithRateLimiter(limiter))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request := func(forwardedFor string) int {
+				// Remote address is 192.0.2.1
+				req := httptest.NewRequest(http.MethodGet, "/api/numbers/14152229670/validate", nil)
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				w := httptest.NewRecorder()
+				srv.ServeHTTP(w, req)
+				return w.Code
+			}
+
+			assert.Equal(t, 200, request("203.0.113.1"))
+			assert.Equal(t, tt.expectedCode, request("203.0.113.2"))
+		})
+	}
+}
+
+func TestNewServer_InvalidTrustedProxy(t *testing.T) {
+	_, err := NewServer(true, WithTrustedProxies([]string{"proxy.internal"}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+	"regexp"
+)
+
+// RequestIDHeader identifies a request in logs, it's
+// taken from the client when valid, or generated
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestID adds the ID of the request to logs of its handlers and scanners
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+
+		logger := logs.FromContext(c.Request.Context()).WithField("request_id", id)
+		c.Request = c.Request.WithContext(logs.NewContext(c.Request.Context(), logger))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	fakenumber "github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	testcases := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{
+			name:     "test request ID from client",
+			header:   "abc-123.def_456",
+			expected: "abc-123.def_456",
+		},
+		{
+			name:   "test generated request ID",
+			header: "",
+		},
+		{
+			name:   "test invalid request ID from client",
+			header: "abc\"; rm -rf",
+		},
+	}
+
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("DryRun", *fakenumber.NewFakeUSNumber(), remote.ScannerOptions{}).Return(errors.New("not configured"))
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+
+	srv, err := NewServer(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook := test.NewGlobal()
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.DebugLevel)
+	defer logrus.SetLevel(level)
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			hook.Reset()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/scans", strings.NewReader(`{"number":"14152229670"}`))
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			assert.Equal(t, 200, w.Code)
+
+			id := w.Header().Get(RequestIDHeader)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, id)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{16}$", id)
+			}
+
+			// Logs of the scanner, then the access log
+			entries := hook.AllEntries()
+			if assert.Len(t, entries, 2) {
+				assert.Equal(t, "Scanner was ignored because it should not run", entries[0].Message)
+				assert.Equal(t, id, entries[0].Data["request_id"])
+				assert.Equal(t, "fakeScanner", entries[0].Data["scanner"])
+				assert.Equal(t, "Request served", entries[1].Message)
+				assert.Equal(t, id, entries[1].Data["request_id"])
+			}
+		})
+	}
+}
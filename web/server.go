@@ -4,9 +4,18 @@
 package web
 
 import (
+	"context"
+	"crypto/tls"
 	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
+	"github.com/sundowndev/phoneinfoga/v2/lib/ratelimit"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote/suppliers"
 	v2 "github.com/sundowndev/phoneinfoga/v2/web/v2/api/server"
+	"net"
 	"net/http"
+	"time"
 )
 
 // @title PhoneInfoga REST API
@@ -17,14 +26,112 @@ import (
 // @schemes http https
 // @license.name GNU General Public License v3.0
 // @license.url https://github.com/sundowndev/phoneinfoga/blob/master/LICENSE
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Required on all routes but health checks when API keys are configured.
 
 type Server struct {
-	router *gin.Engine
+	router   *gin.Engine
+	http     *http.Server
+	keyring  *auth.Keyring
+	limiter  *ratelimit.Limiter
+	metrics  *metrics.Metrics
+	timeouts Timeouts
+	tls      *tls.Config
+	proxies  []string
+
+	// ctx is the parent of request contexts, it's cancelled
+	// when requests don't complete in time on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Timeouts of the HTTP server, zero means no timeout
+type Timeouts struct {
+	// Read is the maximum duration for reading a request, including its body
+	Read time.Duration
+	// Write is the maximum duration before timing out writes of a response
+	Write time.Duration
+	// Idle is the maximum time to wait for the next request on keep-alive connections
+	Idle time.Duration
+}
+
+// Option configures the server
+type Option func(*Server)
+
+// WithKeyring requires API keys from the keyring on API routes,
+// except the health check. Routes are public when it holds no key.
+func WithKeyring(keyring *auth.Keyring) Option {
+	return func(s *Server) {
+		s.keyring = keyring
+	}
+}
+
+// WithRateLimiter limits requests of each client, identified by
+// API key or IP address, to routes matching the limiter rules
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(s *Server) {
+		s.limiter = limiter
+	}
 }
 
-func NewServer(disableClient bool) (*Server, error) {
+// WithTimeouts sets timeouts of the HTTP server
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *Server) {
+		s.timeouts = timeouts
+	}
+}
+
+// WithTLS serves HTTPS with the given configuration. When it requests
+// client certificates, API routes require one, except the health check.
+func WithTLS(config *tls.Config) Option {
+	return func(s *Server) {
+		s.tls = config
+	}
+}
+
+// WithTrustedProxies trusts the X-Forwarded-For and X-Real-IP headers
+// of requests from the given networks, written in CIDR notation or as
+// single IP addresses. Headers of other clients are ignored.
+func WithTrustedProxies(proxies []string) Option {
+	return func(s *Server) {
+		s.proxies = proxies
+	}
+}
+
+// WithMetrics records requests and serves metrics on /metrics,
+// which requires the client certificate and API key API routes do
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *Server) {
+		s.metrics = m
+	}
+}
+
+func NewServer(disableClient bool, opts ...Option) (*Server, error) {
 	s := &Server{
-		router: gin.Default(),
+		router: gin.New(),
+	}
+	// Requests are logged through logrus, so logs settings apply
+	s.router.Use(gin.Recovery(), accessLog())
+	for _, opt := range opts {
+		opt(s)
+	}
+	// Client IPs identify clients in rate limits, they can only be
+	// forwarded by trusted proxies, none by default
+	if err := s.router.SetTrustedProxies(s.proxies); err != nil {
+		return s, err
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.http = &http.Server{
+		Handler:      s.router,
+		ReadTimeout:  s.timeouts.Read,
+		WriteTimeout: s.timeouts.Write,
+		IdleTimeout:  s.timeouts.Idle,
+		TLSConfig:    s.tls,
+		BaseContext: func(net.Listener) context.Context {
+			return s.ctx
+		},
 	}
 	if err := s.registerRoutes(disableClient); err != nil {
 		return s, err
@@ -33,16 +140,35 @@ func NewServer(disableClient bool) (*Server, error) {
 }
 
 func (s *Server) registerRoutes(disableClient bool) error {
-	group := s.router.Group("/api")
+	// API routes and metrics require a client certificate and an
+	// API key when they're configured, and are rate limited
+	var protected []gin.HandlerFunc
+	if s.tls != nil && s.tls.ClientAuth != tls.NoClientCert {
+		protected = append(protected, requireClientCert())
+	}
+	if s.keyring.Len() > 0 {
+		protected = append(protected, authenticate(s.keyring))
+	}
+	if s.limiter.Len() > 0 {
+		protected = append(protected, rateLimit(s.limiter))
+	}
+
+	if s.metrics != nil {
+		s.router.Use(instrument(s.metrics))
+		s.router.GET("/metrics", append(protected, gin.WrapH(s.metrics.Handler()))...)
+	}
+
+	group := s.router.Group("/api", requestID(), traceRequest())
+	group.Use(protected...)
 
 	group.
-		GET("/", healthHandler).
-		GET("/numbers", getAllNumbers).
+		GET("/", s.healthHandler).
+		GET("/numbers", unrestrictedKey, getAllNumbers).
 		GET("/numbers/:number/validate", ValidateScanURL, validate).
-		GET("/numbers/:number/scan/local", ValidateScanURL, localScan).
-		GET("/numbers/:number/scan/numverify", ValidateScanURL, numverifyScan).
-		GET("/numbers/:number/scan/googlesearch", ValidateScanURL, googleSearchScan).
-		GET("/numbers/:number/scan/ovh", ValidateScanURL, ovhScan)
+		GET("/numbers/:number/scan/local", ValidateScanURL, allowScanner(remote.NewLocalScanner()), localScan).
+		GET("/numbers/:number/scan/numverify", ValidateScanURL, allowScanner(remote.NewNumverifyScanner(suppliers.NewNumverifySupplier())), numverifyScan).
+		GET("/numbers/:number/scan/googlesearch", ValidateScanURL, allowScanner(remote.NewGoogleSearchScanner()), googleSearchScan).
+		GET("/numbers/:number/scan/ovh", ValidateScanURL, allowScanner(remote.NewOVHScanner(suppliers.NewOVHSupplier())), ovhScan)
 
 	v2routes := v2.NewServer().Routes()
 	for _, r := range v2routes {
@@ -66,8 +192,34 @@ func (s *Server) registerRoutes(disableClient bool) error {
 	return nil
 }
 
+// ListenAndServe serves requests on the given address until the server
+// is shut down, it then returns http.ErrServerClosed.
 func (s *Server) ListenAndServe(addr string) error {
-	return s.router.Run(addr)
+	s.http.Addr = addr
+	if s.tls != nil {
+		return s.http.ListenAndServeTLS("", "")
+	}
+	return s.http.ListenAndServe()
+}
+
+// Serve is like ListenAndServe, with an existing listener
+func (s *Server) Serve(l net.Listener) error {
+	if s.tls != nil {
+		return s.http.ServeTLS(l, "", "")
+	}
+	return s.http.Serve(l)
+}
+
+// Shutdown stops accepting connections, then waits for running requests
+// until the given context is done. Requests still running are cancelled
+// and their connections closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		s.cancel()
+		_ = s.http.Close()
+	}
+	return err
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
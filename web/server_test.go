@@ -1,11 +1,15 @@
 package web
 
 import (
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote/suppliers"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,6 +69,25 @@ func TestApi(t *testing.T) {
 			assert.Equal(t, string(body), "{\"success\":true,\"numbers\":[]}")
 		})
 
+		t.Run("getAllNumbers with history - /api/numbers", func(t *testing.T) {
+			store := history.NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+			handlers.History = store
+			defer func() { handlers.History = nil }()
+
+			err := store.Save(history.NewScan(test.NewFakeUSNumber(), map[string]interface{}{}, map[string]error{}, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := performRequest(srv, http.MethodGet, "/api/numbers")
+
+			body, _ := ioutil.ReadAll(res.Body)
+
+			assert.Equal(t, err, nil)
+			assert.Equal(t, res.Result().StatusCode, 200)
+			assert.Equal(t, string(body), "{\"success\":true,\"numbers\":[{\"Valid\":true,\"RawLocal\":\"4152229670\",\"Local\":\"(415) 222-9670\",\"E164\":\"+14152229670\",\"International\":\"14152229670\",\"CountryCode\":1,\"Country\":\"US\",\"Carrier\":\"\"}]}")
+		})
+
 		t.Run("validate - /api/numbers/:number/validate", func(t *testing.T) {
 			t.Run("valid number", func(t *testing.T) {
 				res, err := performRequest(srv, http.MethodGet, "/api/numbers/3312345253/validate")
@@ -201,7 +224,7 @@ func TestApi(t *testing.T) {
 				assert.NoError(t, err)
 
 				assert.Equal(t, 200, res.Result().StatusCode)
-				assert.Equal(t, `{"success":true,"result":{"social_media":[{"number":"+33365179268","dork":"site:facebook.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afacebook.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:twitter.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Atwitter.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:linkedin.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Alinkedin.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:instagram.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Ainstagram.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:vk.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Avk.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"}],"disposable_providers":[{"number":"+33365179268","dork":"site:hs3x.com intext:\"33365179268\"","url":"https://www.google.com/search?q=site%3Ahs3x.com+intext%3A%2233365179268%22"},{"number":"+33365179268","dork":"site:receive-sms-now.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms-now.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:smslisten.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmslisten.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:smsnumbersonline.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmsnumbersonline.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:freesmscode.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afreesmscode.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:catchsms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Acatchsms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:smstibo.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmstibo.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:smsreceiving.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmsreceiving.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:getfreesmsnumber.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Agetfreesmsnumber.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:sellaite.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asellaite.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receive-sms-online.info intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms-online.info+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receivesmsonline.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceivesmsonline.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receive-a-sms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-a-sms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:sms-receive.net intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asms-receive.net+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receivefreesms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceivefreesms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receive-sms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receivetxt.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceivetxt.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:freephonenum.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afreephonenum.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:freesmsverification.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afreesmsverification.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:receive-sms-online.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms-online.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:smslive.co intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmslive.co+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"}],"reputation":[{"number":"+33365179268","dork":"site:whosenumber.info intext:\"+33365179268\" intitle:\"who called\"","url":"https://www.google.com/search?q=site%3Awhosenumber.info+intext%3A%22%2B33365179268%22+intitle%3A%22who+called%22"},{"number":"+33365179268","dork":"intitle:\"Phone Fraud\" intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=intitle%3A%22Phone+Fraud%22+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:findwhocallsme.com intext:\"+33365179268\" | intext:\"33365179268\"","url":"https://www.google.com/search?q=site%3Afindwhocallsme.com+intext%3A%22%2B33365179268%22+%7C+intext%3A%2233365179268%22"},{"number":"+33365179268","dork":"site:yellowpages.ca intext:\"+33365179268\"","url":"https://www.google.com/search?q=site%3Ayellowpages.ca+intext%3A%22%2B33365179268%22"},{"number":"+33365179268","dork":"site:phonenumbers.ie intext:\"+33365179268\"","url":"https://www.google.com/search?q=site%3Aphonenumbers.ie+intext%3A%22%2B33365179268%22"},{"number":"+33365179268","dork":"site:who-calledme.com intext:\"+33365179268\"","url":"https://www.google.com/search?q=site%3Awho-calledme.com+intext%3A%22%2B33365179268%22"},{"number":"+33365179268","dork":"site:usphonesearch.net intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Ausphonesearch.net+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:whocalled.us inurl:\"0365179268\"","url":"https://www.google.com/search?q=site%3Awhocalled.us+inurl%3A%220365179268%22"},{"number":"+33365179268","dork":"site:quinumero.info intext:\"0365179268\" | intext:\"33365179268\"","url":"https://www.google.com/search?q=site%3Aquinumero.info+intext%3A%220365179268%22+%7C+intext%3A%2233365179268%22"},{"number":"+33365179268","dork":"site:uk.popularphotolook.com inurl:\"0365179268\"","url":"https://www.google.com/search?q=site%3Auk.popularphotolook.com+inurl%3A%220365179268%22"}],"individuals":[{"number":"+33365179268","dork":"site:numinfo.net intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Anuminfo.net+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:sync.me intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Async.me+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:whocallsyou.de intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Awhocallsyou.de+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:pastebin.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Apastebin.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:whycall.me intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Awhycall.me+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:locatefamily.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Alocatefamily.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","dork":"site:spytox.com intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Aspytox.com+intext%3A%220365179268%22"}],"general":[{"number":"+33365179268","dork":"intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\" | intext:\"03 65 17 92 68\"","url":"https://www.google.com/search?q=intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22+%7C+intext%3A%2203+65+17+92+68%22"},{"number":"+33365179268","dork":"(ext:doc | ext:docx | ext:odt | ext:pdf | ext:rtf | ext:sxw | ext:psw | ext:ppt | ext:pptx | ext:pps | ext:csv | ext:txt | ext:xls) intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=%28ext%3Adoc+%7C+ext%3Adocx+%7C+ext%3Aodt+%7C+ext%3Apdf+%7C+ext%3Artf+%7C+ext%3Asxw+%7C+ext%3Apsw+%7C+ext%3Appt+%7C+ext%3Apptx+%7C+ext%3Apps+%7C+ext%3Acsv+%7C+ext%3Atxt+%7C+ext%3Axls%29+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"}]}}`, string(body))
+				assert.Equal(t, `{"success":true,"result":{"social_media":[{"number":"+33365179268","engine":"google","dork":"site:facebook.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afacebook.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:twitter.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Atwitter.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:linkedin.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Alinkedin.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:instagram.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Ainstagram.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:vk.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Avk.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"}],"disposable_providers":[{"number":"+33365179268","engine":"google","dork":"site:hs3x.com intext:\"33365179268\"","url":"https://www.google.com/search?q=site%3Ahs3x.com+intext%3A%2233365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receive-sms-now.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms-now.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:smslisten.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmslisten.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:smsnumbersonline.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmsnumbersonline.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:freesmscode.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afreesmscode.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:catchsms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Acatchsms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:smstibo.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmstibo.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:smsreceiving.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmsreceiving.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:getfreesmsnumber.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Agetfreesmsnumber.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:sellaite.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asellaite.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receive-sms-online.info intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms-online.info+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receivesmsonline.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceivesmsonline.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receive-a-sms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-a-sms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:sms-receive.net intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asms-receive.net+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receivefreesms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceivefreesms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receive-sms.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receivetxt.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceivetxt.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:freephonenum.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afreephonenum.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:freesmsverification.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Afreesmsverification.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:receive-sms-online.com intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Areceive-sms-online.com+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:smslive.co intext:\"33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Asmslive.co+intext%3A%2233365179268%22+%7C+intext%3A%220365179268%22"}],"reputation":[{"number":"+33365179268","engine":"google","dork":"site:whosenumber.info intext:\"+33365179268\" intitle:\"who called\"","url":"https://www.google.com/search?q=site%3Awhosenumber.info+intext%3A%22%2B33365179268%22+intitle%3A%22who+called%22"},{"number":"+33365179268","engine":"google","dork":"intitle:\"Phone Fraud\" intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=intitle%3A%22Phone+Fraud%22+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:findwhocallsme.com intext:\"+33365179268\" | intext:\"33365179268\"","url":"https://www.google.com/search?q=site%3Afindwhocallsme.com+intext%3A%22%2B33365179268%22+%7C+intext%3A%2233365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:yellowpages.ca intext:\"+33365179268\"","url":"https://www.google.com/search?q=site%3Ayellowpages.ca+intext%3A%22%2B33365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:phonenumbers.ie intext:\"+33365179268\"","url":"https://www.google.com/search?q=site%3Aphonenumbers.ie+intext%3A%22%2B33365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:who-calledme.com intext:\"+33365179268\"","url":"https://www.google.com/search?q=site%3Awho-calledme.com+intext%3A%22%2B33365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:usphonesearch.net intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Ausphonesearch.net+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:whocalled.us inurl:\"0365179268\"","url":"https://www.google.com/search?q=site%3Awhocalled.us+inurl%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:quinumero.info intext:\"0365179268\" | intext:\"33365179268\"","url":"https://www.google.com/search?q=site%3Aquinumero.info+intext%3A%220365179268%22+%7C+intext%3A%2233365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:uk.popularphotolook.com inurl:\"0365179268\"","url":"https://www.google.com/search?q=site%3Auk.popularphotolook.com+inurl%3A%220365179268%22"}],"individuals":[{"number":"+33365179268","engine":"google","dork":"site:numinfo.net intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Anuminfo.net+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:sync.me intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Async.me+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:whocallsyou.de intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Awhocallsyou.de+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:pastebin.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Apastebin.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:whycall.me intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Awhycall.me+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:locatefamily.com intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Alocatefamily.com+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"},{"number":"+33365179268","engine":"google","dork":"site:spytox.com intext:\"0365179268\"","url":"https://www.google.com/search?q=site%3Aspytox.com+intext%3A%220365179268%22"}],"general":[{"number":"+33365179268","engine":"google","dork":"intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\" | intext:\"03 65 17 92 68\"","url":"https://www.google.com/search?q=intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22+%7C+intext%3A%2203+65+17+92+68%22"},{"number":"+33365179268","engine":"google","dork":"(ext:doc | ext:docx | ext:odt | ext:pdf | ext:rtf | ext:sxw | ext:psw | ext:ppt | ext:pptx | ext:pps | ext:csv | ext:txt | ext:xls) intext:\"33365179268\" | intext:\"+33365179268\" | intext:\"0365179268\"","url":"https://www.google.com/search?q=%28ext%3Adoc+%7C+ext%3Adocx+%7C+ext%3Aodt+%7C+ext%3Apdf+%7C+ext%3Artf+%7C+ext%3Asxw+%7C+ext%3Apsw+%7C+ext%3Appt+%7C+ext%3Apptx+%7C+ext%3Apps+%7C+ext%3Acsv+%7C+ext%3Atxt+%7C+ext%3Axls%29+intext%3A%2233365179268%22+%7C+intext%3A%22%2B33365179268%22+%7C+intext%3A%220365179268%22"}]}}`, string(body))
 			})
 		})
 
@@ -246,7 +269,7 @@ func TestApi(t *testing.T) {
 			body, _ := ioutil.ReadAll(res.Body)
 
 			assert.Equal(t, 200, res.Result().StatusCode)
-			assert.Equal(t, `{"success":true,"version":"dev","commit":"dev","demo":false}`, string(body))
+			assert.Equal(t, `{"success":true,"version":"dev","commit":"dev","demo":false,"auth":false}`, string(body))
 		})
 
 		t.Run("404 error - /api/notfound", func(t *testing.T) {
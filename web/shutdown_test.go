@@ -0,0 +1,118 @@
+package web
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingScanner runs until released or until its context is cancelled
+type blockingScanner struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingScanner) Name() string        { return "blocking" }
+func (s *blockingScanner) Description() string { return "" }
+
+func (s *blockingScanner) DryRun(number.Number, remote.ScannerOptions) error { return nil }
+
+func (s *blockingScanner) Run(n number.Number, opts remote.ScannerOptions) (interface{}, error) {
+	return s.RunContext(context.Background(), n, opts)
+}
+
+func (s *blockingScanner) RunContext(ctx context.Context, _ number.Number, _ remote.ScannerOptions) (interface{}, error) {
+	close(s.started)
+	select {
+	case <-s.release:
+		return "done", nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	testcases := []struct {
+		name          string
+		release       bool
+		timeout       time.Duration
+		expectedError error
+		expectedBody  string
+	}{
+		{
+			name:         "test running scan completes",
+			release:      true,
+			timeout:      5 * time.Second,
+			expectedBody: `"blocking":"done"`,
+		},
+		{
+			name:          "test running scan cancelled after timeout",
+			timeout:       50 * time.Millisecond,
+			expectedError: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &blockingScanner{started: make(chan struct{}), release: make(chan struct{})}
+			handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+			handlers.RemoteLibrary.AddScanner(scanner)
+
+			srv, err := NewServer(true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- srv.Serve(l)
+			}()
+
+			type result struct {
+				body string
+				err  error
+			}
+			results := make(chan result, 1)
+			go func() {
+				res, err := http.Post("http://"+l.Addr().String()+"/api/v2/scans", "application/json", strings.NewReader(`{"number":"14152229670"}`))
+				if err != nil {
+					results <- result{err: err}
+					return
+				}
+				defer res.Body.Close()
+				var body strings.Builder
+				_, err = io.Copy(&body, res.Body)
+				results <- result{body: body.String(), err: err}
+			}()
+			<-scanner.started
+
+			if tt.release {
+				time.AfterFunc(20*time.Millisecond, func() { close(scanner.release) })
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			assert.ErrorIs(t, srv.Shutdown(ctx), tt.expectedError)
+			assert.ErrorIs(t, <-serveErr, http.ErrServerClosed)
+
+			res := <-results
+			if tt.expectedBody != "" {
+				assert.NoError(t, res.err)
+				assert.Contains(t, res.body, tt.expectedBody)
+			} else {
+				assert.Error(t, res.err)
+			}
+		})
+	}
+}
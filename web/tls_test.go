@@ -0,0 +1,170 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/metrics"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tlsconfig"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMutualTLS(t *testing.T) {
+	localScanner := &mocks.Scanner{}
+	localScanner.On("Name").Return("local")
+	localScanner.On("Description").Return("local scanner")
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(localScanner)
+
+	ca, err := test.NewCertificateAuthority("test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certPEM, keyPEM, err := ca.Issue("localhost", x509.ExtKeyUsageServerAuth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"cert.pem": certPEM, "key.pem": keyPEM, "ca.pem": ca.PEM} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	certs, err := tlsconfig.NewReloader(tlsconfig.Options{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := auth.NewKeyring(
+		auth.Key{Name: "soar", ClientCert: "soar.internal"},
+		auth.Key{Name: "admin", Hash: auth.Hash("admin-key")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := NewServer(true, WithKeyring(keyring), WithTLS(certs.Config()), WithMetrics(metrics.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	defer srv.Shutdown(context.Background())
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.PEM)
+	client := func(commonName string) *http.Client {
+		config := &tls.Config{RootCAs: roots}
+		if commonName != "" {
+			certPEM, keyPEM, err := ca.Issue(commonName, x509.ExtKeyUsageClientAuth)
+			assert.NoError(t, err)
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			assert.NoError(t, err)
+			config.Certificates = []tls.Certificate{cert}
+		}
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	}
+
+	testcases := []struct {
+		name         string
+		cert         string
+		path         string
+		key          string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "test public health check",
+			path:         "/api/",
+			expectedCode: 200,
+		},
+		{
+			name:         "test missing client certificate",
+			path:         "/api/v2/scanners",
+			key:          "admin-key",
+			expectedCode: 401,
+			expectedBody: `{"success":false,"error":"missing TLS client certificate"}`,
+		},
+		{
+			name:         "test certificate bound to a key",
+			cert:         "soar.internal",
+			path:         "/api/v2/scanners",
+			expectedCode: 200,
+		},
+		{
+			name:         "test certificate not bound to a key",
+			cert:         "unknown.internal",
+			path:         "/api/v2/scanners",
+			expectedCode: 401,
+			expectedBody: `{"success":false,"error":"client certificate is not bound to an API key"}`,
+		},
+		{
+			name:         "test certificate with an API key",
+			cert:         "unknown.internal",
+			path:         "/api/v2/scanners",
+			key:          "admin-key",
+			expectedCode: 200,
+		},
+		{
+			name:         "test metrics without client certificate",
+			path:         "/metrics",
+			key:          "admin-key",
+			expectedCode: 401,
+			expectedBody: `{"success":false,"error":"missing TLS client certificate"}`,
+		},
+		{
+			name:         "test metrics with certificate bound to a key",
+			cert:         "soar.internal",
+			path:         "/metrics",
+			expectedCode: 200,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "https://"+l.Addr().String()+tt.path, nil)
+			assert.NoError(t, err)
+			if tt.key != "" {
+				req.Header.Set(auth.Header, tt.key)
+			}
+			res, err := client(tt.cert).Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, string(body))
+			}
+		})
+	}
+
+	// Certificates of unknown authorities are rejected on handshake
+	other, err := test.NewCertificateAuthority("other CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca = other
+	_, err = client("soar.internal").Get("https://" + l.Addr().String() + "/api/v2/scanners")
+	assert.Error(t, err)
+}
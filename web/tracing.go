@@ -0,0 +1,39 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// traceRequest starts a span for each request, continuing the trace of the
+// client when it sends a traceparent header. Spans are named by route template
+// so numbers given in paths don't end up in span names.
+func traceRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(c.Request.Method),
+				semconv.HTTPRouteKey.String(route),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		code := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(code))
+		if code >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(code))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(propagator)
+	}()
+
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil)
+	fakeScanner.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil, nil)
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+
+	srv, err := NewServer(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/scans", strings.NewReader(`{"number":"14152229670"}`))
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	scanner, request := spans[0], spans[1]
+	assert.Equal(t, "scanner fakeScanner", scanner.Name())
+	assert.Equal(t, "POST /api/v2/scans", request.Name())
+	assert.Equal(t, request.SpanContext().SpanID(), scanner.Parent().SpanID())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.SpanContext().TraceID().String())
+}
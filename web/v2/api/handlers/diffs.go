@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"net/http"
+)
+
+// DiffScan is a scan to compare, either a scan ID from history or inline results
+type DiffScan struct {
+	ID      string                 `json:"id,omitempty"`
+	Results map[string]interface{} `json:"results,omitempty"`
+	Errors  map[string]string      `json:"errors,omitempty"`
+}
+
+type DiffInput struct {
+	From *DiffScan `json:"from" binding:"required"`
+	To   *DiffScan `json:"to" binding:"required"`
+}
+
+// Diff is an HTTP handler
+// @ID Diff
+// @Tags Numbers
+// @Summary Compare results of two scans.
+// @Description This route returns differences between two scans, per scanner. Scans are given as IDs from scan history, or as results returned by the scan route. Results of scans from history are limited to scanners the API key is allowed to use.
+// @Accept  json
+// @Produce  json
+// @Param request body DiffInput true "Request body"
+// @Success 200 {object} diff.Diff
+// @Success 400 {object} api.ErrorResponse
+// @Success 404 {object} api.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /v2/diffs [post]
+func Diff(ctx *gin.Context) *api.Response {
+	var input DiffInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		return &api.Response{
+			Code: http.StatusBadRequest,
+			JSON: true,
+			Data: api.ErrorResponse{Error: "Invalid request body: from and to scans are required"},
+		}
+	}
+
+	from, code, err := diffResult(ctx.Request.Context(), input.From)
+	if err != nil {
+		return &api.Response{Code: code, JSON: true, Data: api.ErrorResponse{Error: err.Error()}}
+	}
+	to, code, err := diffResult(ctx.Request.Context(), input.To)
+	if err != nil {
+		return &api.Response{Code: code, JSON: true, Data: api.ErrorResponse{Error: err.Error()}}
+	}
+
+	d, err := diff.Compare(from, to)
+	if err != nil {
+		return &api.Response{
+			Code: http.StatusBadRequest,
+			JSON: true,
+			Data: api.ErrorResponse{Error: err.Error()},
+		}
+	}
+
+	return &api.Response{
+		Code: http.StatusOK,
+		JSON: true,
+		Data: d,
+	}
+}
+
+// diffResult returns results of the given scan, along with
+// the HTTP status code to use if they can't be retrieved
+func diffResult(ctx context.Context, s *DiffScan) (diff.Result, int, error) {
+	if s.ID == "" {
+		return diff.Result{Results: s.Results, Errors: s.Errors}, http.StatusOK, nil
+	}
+	if History == nil {
+		return diff.Result{}, http.StatusBadRequest, errors.New("scan history is disabled")
+	}
+
+	scan, err := History.Get(s.ID)
+	if errors.Is(err, history.ErrNotFound) {
+		return diff.Result{}, http.StatusNotFound, fmt.Errorf("scan %s not found", s.ID)
+	}
+	if err != nil {
+		return diff.Result{}, http.StatusInternalServerError, err
+	}
+	results, errs := allowedResults(ctx, scan.Results, scan.Errors)
+	return diff.Result{Number: &scan.Number, Results: results, Errors: errs}, http.StatusOK, nil
+}
@@ -0,0 +1,150 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/diff"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/server"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	store := history.NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	scan := history.NewScan(test.NewFakeUSNumber(), map[string]interface{}{
+		"local": map[string]interface{}{"country": "US"},
+	}, map[string]error{"numverify": errors.New("dummy error")}, map[string]string{})
+	if err := store.Save(scan); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := number.NewNumber("+33679368229")
+	otherScan := history.NewScan(other, map[string]interface{}{}, map[string]error{}, map[string]string{})
+	if err := store.Save(otherScan); err != nil {
+		t.Fatal(err)
+	}
+
+	type expectedResponse struct {
+		Code int
+		Body interface{}
+	}
+
+	testcases := []struct {
+		Name     string
+		Body     string
+		History  history.Store
+		Key      *auth.Key
+		Expected expectedResponse
+	}{
+		{
+			Name:    "test comparing a saved scan with results",
+			Body:    `{"from": {"id": "` + scan.ID + `"}, "to": {"results": {"local": {"country": "CA"}}}}`,
+			History: store,
+			Expected: expectedResponse{
+				Code: 200,
+				Body: diff.Diff{Scanners: []diff.ScannerDiff{
+					{Scanner: "local", Type: diff.Changed, Changes: []diff.Change{
+						{Path: "country", Type: diff.Changed, From: "US", To: "CA"},
+					}},
+					{Scanner: "numverify", Type: diff.Removed, Changes: []diff.Change{
+						{Type: diff.Removed, From: map[string]interface{}{"error": "dummy error"}},
+					}},
+				}},
+			},
+		},
+		{
+			Name:    "test restricted key",
+			Body:    `{"from": {"id": "` + scan.ID + `"}, "to": {"results": {"local": {"country": "CA"}, "numverify": {"valid": true}}}}`,
+			History: store,
+			Key:     &auth.Key{Name: "soar", Scanners: []string{"local"}},
+			Expected: expectedResponse{
+				Code: 200,
+				Body: diff.Diff{Scanners: []diff.ScannerDiff{
+					{Scanner: "local", Type: diff.Changed, Changes: []diff.Change{
+						{Path: "country", Type: diff.Changed, From: "US", To: "CA"},
+					}},
+					{Scanner: "numverify", Type: diff.Added, Changes: []diff.Change{
+						{Type: diff.Added, To: map[string]interface{}{"valid": true}},
+					}},
+				}},
+			},
+		},
+		{
+			Name:    "test identical scans",
+			Body:    `{"from": {"id": "` + scan.ID + `"}, "to": {"id": "` + scan.ID + `"}}`,
+			History: store,
+			Expected: expectedResponse{
+				Code: 200,
+				Body: diff.Diff{Scanners: []diff.ScannerDiff{}},
+			},
+		},
+		{
+			Name:    "test scans of different numbers",
+			Body:    `{"from": {"id": "` + scan.ID + `"}, "to": {"id": "` + otherScan.ID + `"}}`,
+			History: store,
+			Expected: expectedResponse{
+				Code: 400,
+				Body: api.ErrorResponse{Error: "scans of different numbers can't be compared: +14152229670 and +33679368229"},
+			},
+		},
+		{
+			Name:    "test unknown scan",
+			Body:    `{"from": {"id": "unknown"}, "to": {"results": {}}}`,
+			History: store,
+			Expected: expectedResponse{
+				Code: 404,
+				Body: api.ErrorResponse{Error: "scan unknown not found"},
+			},
+		},
+		{
+			Name: "test scan ID with history disabled",
+			Body: `{"from": {"id": "` + scan.ID + `"}, "to": {"results": {}}}`,
+			Expected: expectedResponse{
+				Code: 400,
+				Body: api.ErrorResponse{Error: "scan history is disabled"},
+			},
+		},
+		{
+			Name: "test invalid body",
+			Body: `{"from": {}}`,
+			Expected: expectedResponse{
+				Code: 400,
+				Body: api.ErrorResponse{Error: "Invalid request body: from and to scans are required"},
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.Name, func(t *testing.T) {
+			handlers.History = tt.History
+			defer func() { handlers.History = nil }()
+
+			req, err := http.NewRequest(http.MethodPost, "/v2/diffs", bytes.NewReader([]byte(tt.Body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.Key != nil {
+				req = req.WithContext(auth.NewContext(req.Context(), tt.Key))
+			}
+			w := httptest.NewRecorder()
+			server.NewServer().ServeHTTP(w, req)
+
+			b, err := json.Marshal(tt.Expected.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.Expected.Code, w.Code)
+			assert.Equal(t, string(b), w.Body.String())
+		})
+	}
+}
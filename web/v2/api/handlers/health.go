@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ReferenceNumber is the number scanners are dry run with to check they're ready
+const ReferenceNumber = "+14152229670"
+
+var (
+	// HealthCacheTTL is how long readiness of a scanner is cached,
+	// so probes don't dry run every scanner on each request
+	HealthCacheTTL = 30 * time.Second
+	// HealthDryRunTimeout bounds the dry run of a single scanner
+	HealthDryRunTimeout = 5 * time.Second
+)
+
+// Names of readiness checks
+const (
+	HealthCheckScanners = "scanners"
+	HealthCheckHistory  = "history"
+)
+
+type HealthCheck struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+type HealthReadyResponse struct {
+	Ready  bool          `json:"ready"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// HealthReady is an HTTP handler
+// @ID HealthReady
+// @Tags Health
+// @Summary Check the server is ready to serve scans.
+// @Description This route checks scanners are loaded and the scan history can be written, when enabled. It responds with a 503 status when a check fails, for readiness probes. It doesn't require an API key.
+// @Produce  json
+// @Success 200 {object} HealthReadyResponse
+// @Success 503 {object} HealthReadyResponse
+// @Router /v2/health/ready [get]
+func HealthReady(_ *gin.Context) *api.Response {
+	res := HealthReadyResponse{Ready: true, Checks: []HealthCheck{}}
+	addCheck := func(name string, err error) {
+		check := HealthCheck{Name: name, Ready: err == nil}
+		if err != nil {
+			check.Error = err.Error()
+			res.Ready = false
+		}
+		res.Checks = append(res.Checks, check)
+	}
+
+	if RemoteLibrary == nil || len(RemoteLibrary.GetAllScanners()) == 0 {
+		addCheck(HealthCheckScanners, errors.New("no scanner loaded"))
+	} else {
+		addCheck(HealthCheckScanners, nil)
+	}
+	if p, ok := History.(history.Pinger); ok {
+		addCheck(HealthCheckHistory, p.Ping())
+	}
+
+	code := http.StatusOK
+	if !res.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	return &api.Response{
+		Code: code,
+		JSON: true,
+		Data: res,
+	}
+}
+
+type ScannerHealth struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	// Reason is why the scanner doesn't run, such as missing credentials
+	Reason string   `json:"reason,omitempty"`
+	Plugin bool     `json:"plugin"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+type HealthScannersResponse struct {
+	Scanners []ScannerHealth `json:"scanners"`
+	// PluginErrors are errors of plugins that failed to load,
+	// they're not given to API keys restricted to some scanners
+	PluginErrors []string `json:"plugin_errors,omitempty"`
+}
+
+// HealthScanners is an HTTP handler
+// @ID HealthScanners
+// @Tags Health
+// @Summary Check which scanners are ready to run.
+// @Description This route dry runs scanners the API key is allowed to use with a reference number, using options of the server environment. Outcomes are cached for 30 seconds, and dry runs taking more than 5 seconds are reported as not ready. Scanners that aren't ready, e.g. because their API key isn't configured, come with the reason. Plugins are flagged, errors of plugins that failed to load are listed.
+// @Produce  json
+// @Success 200 {object} HealthScannersResponse
+// @Security ApiKeyAuth
+// @Router /v2/health/scanners [get]
+func HealthScanners(ctx *gin.Context) *api.Response {
+	num, err := number.NewNumber(ReferenceNumber)
+	if err != nil {
+		return &api.Response{
+			Code: http.StatusInternalServerError,
+			JSON: true,
+			Data: api.ErrorResponse{Error: err.Error()},
+		}
+	}
+
+	var allowed []remote.Scanner
+	for _, s := range RemoteLibrary.GetAllScanners() {
+		if scannerAllowed(ctx.Request.Context(), s) {
+			allowed = append(allowed, s)
+		}
+	}
+	errs := readiness.check(allowed, *num)
+
+	scanners := []ScannerHealth{}
+	for i, s := range allowed {
+		tags := remote.ScannerTags(s)
+		health := ScannerHealth{Name: s.Name(), Ready: true, Tags: tags}
+		for _, t := range tags {
+			if t == remote.TagPlugin {
+				health.Plugin = true
+			}
+		}
+		if errs[i] != nil {
+			health.Ready = false
+			health.Reason = errs[i].Error()
+		}
+		scanners = append(scanners, health)
+	}
+
+	res := HealthScannersResponse{Scanners: scanners}
+	if k := auth.FromContext(ctx.Request.Context()); k == nil || !k.Restricted() {
+		for _, err := range PluginErrors {
+			res.PluginErrors = append(res.PluginErrors, err.Error())
+		}
+	}
+
+	return &api.Response{
+		Code: http.StatusOK,
+		JSON: true,
+		Data: res,
+	}
+}
+
+var readiness = &readinessCache{entries: map[remote.Scanner]readinessEntry{}}
+
+type readinessEntry struct {
+	err     error
+	checked time.Time
+}
+
+// readinessCache holds outcomes of scanner dry runs for HealthCacheTTL
+type readinessCache struct {
+	mu      sync.Mutex
+	entries map[remote.Scanner]readinessEntry
+}
+
+// check returns the dry run error of each scanner, scanners whose
+// outcome expired are dry run again concurrently
+func (c *readinessCache) check(scanners []remote.Scanner, n number.Number) []error {
+	errs := make([]error, len(scanners))
+	var wg sync.WaitGroup
+	for i, s := range scanners {
+		c.mu.Lock()
+		e, ok := c.entries[s]
+		c.mu.Unlock()
+		if ok && time.Since(e.checked) < HealthCacheTTL {
+			errs[i] = e.err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, s remote.Scanner) {
+			defer wg.Done()
+			errs[i] = dryRun(s, n, HealthDryRunTimeout)
+			c.mu.Lock()
+			c.entries[s] = readinessEntry{err: errs[i], checked: time.Now()}
+			c.mu.Unlock()
+		}(i, s)
+	}
+	wg.Wait()
+	return errs
+}
+
+// dryRun dry runs the scanner, giving up once the timeout expires
+func dryRun(s remote.Scanner, n number.Number, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("dry run panicked: %v", r)
+			}
+		}()
+		errc <- s.DryRun(n, remote.ScannerOptions{})
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("dry run did not complete within %s", timeout)
+	}
+}
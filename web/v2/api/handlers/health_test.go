@@ -0,0 +1,249 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/server"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealthReady(t *testing.T) {
+	type expectedResponse struct {
+		Code int
+		Body interface{}
+	}
+
+	// A directory can't be opened as a database file
+	brokenHistory := t.TempDir()
+
+	testcases := []struct {
+		Name     string
+		Scanners bool
+		History  history.Store
+		Expected expectedResponse
+	}{
+		{
+			Name:     "test ready without history",
+			Scanners: true,
+			Expected: expectedResponse{
+				Code: 200,
+				Body: handlers.HealthReadyResponse{
+					Ready:  true,
+					Checks: []handlers.HealthCheck{{Name: "scanners", Ready: true}},
+				},
+			},
+		},
+		{
+			Name:     "test ready with history",
+			Scanners: true,
+			History:  history.NewBoltStore(filepath.Join(t.TempDir(), "history.db")),
+			Expected: expectedResponse{
+				Code: 200,
+				Body: handlers.HealthReadyResponse{
+					Ready: true,
+					Checks: []handlers.HealthCheck{
+						{Name: "scanners", Ready: true},
+						{Name: "history", Ready: true},
+					},
+				},
+			},
+		},
+		{
+			Name:     "test history not available",
+			Scanners: true,
+			History:  history.NewBoltStore(brokenHistory),
+			Expected: expectedResponse{
+				Code: 503,
+				Body: handlers.HealthReadyResponse{
+					Ready: false,
+					Checks: []handlers.HealthCheck{
+						{Name: "scanners", Ready: true},
+						{Name: "history", Ready: false, Error: "unable to open history file " + brokenHistory + ": open " + brokenHistory + ": is a directory"},
+					},
+				},
+			},
+		},
+		{
+			Name: "test no scanner loaded",
+			Expected: expectedResponse{
+				Code: 503,
+				Body: handlers.HealthReadyResponse{
+					Ready:  false,
+					Checks: []handlers.HealthCheck{{Name: "scanners", Ready: false, Error: "no scanner loaded"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.Name, func(t *testing.T) {
+			handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+			if tt.Scanners {
+				fakeScanner := &mocks.Scanner{}
+				fakeScanner.On("Name").Return("fakeScanner")
+				handlers.RemoteLibrary.AddScanner(fakeScanner)
+			}
+			handlers.History = tt.History
+			defer func() { handlers.History = nil }()
+
+			r := server.NewServer()
+
+			req, err := http.NewRequest(http.MethodGet, "/v2/health/ready", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			b, err := json.Marshal(tt.Expected.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.Expected.Code, w.Code)
+			assert.Equal(t, string(b), w.Body.String())
+		})
+	}
+}
+
+func TestHealthScanners(t *testing.T) {
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil)
+	unconfiguredScanner := &mocks.Scanner{}
+	unconfiguredScanner.On("Name").Return("numverify")
+	unconfiguredScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(errors.New("API key is not defined"))
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+	handlers.RemoteLibrary.AddScanner(unconfiguredScanner)
+
+	r := server.NewServer()
+
+	req, err := http.NewRequest(http.MethodGet, "/v2/health/scanners", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	b, err := json.Marshal(handlers.HealthScannersResponse{
+		Scanners: []handlers.ScannerHealth{
+			{Name: "fakeScanner", Ready: true},
+			{Name: "numverify", Ready: false, Reason: "API key is not defined"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+	fakeScanner.AssertExpectations(t)
+	unconfiguredScanner.AssertExpectations(t)
+}
+
+func TestHealthScanners_PluginErrors(t *testing.T) {
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.PluginErrors = []error{errors.New("checksum mismatch for plugin broken")}
+	defer func() { handlers.PluginErrors = nil }()
+
+	testcases := []struct {
+		name     string
+		key      *auth.Key
+		expected handlers.HealthScannersResponse
+	}{
+		{
+			name: "test plugin errors are listed",
+			expected: handlers.HealthScannersResponse{
+				Scanners:     []handlers.ScannerHealth{},
+				PluginErrors: []string{"checksum mismatch for plugin broken"},
+			},
+		},
+		{
+			name: "test plugin errors are hidden from restricted keys",
+			key:  &auth.Key{Name: "partner", Scanners: []string{"local"}},
+			expected: handlers.HealthScannersResponse{
+				Scanners: []handlers.ScannerHealth{},
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/v2/health/scanners", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.key != nil {
+				req = req.WithContext(auth.NewContext(req.Context(), tt.key))
+			}
+			w := httptest.NewRecorder()
+			server.NewServer().ServeHTTP(w, req)
+
+			b, err := json.Marshal(tt.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, string(b), w.Body.String())
+		})
+	}
+}
+
+func TestHealthScanners_Cache(t *testing.T) {
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil).Once()
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+
+	// Probes don't dry run scanners again until the cache expires
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest(http.MethodGet, "/v2/health/scanners", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		server.NewServer().ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code)
+		assert.JSONEq(t, `{"scanners":[{"name":"fakeScanner","ready":true,"plugin":false}]}`, w.Body.String())
+	}
+	fakeScanner.AssertNumberOfCalls(t, "DryRun", 1)
+}
+
+func TestHealthScanners_Timeout(t *testing.T) {
+	defer func(d time.Duration) { handlers.HealthDryRunTimeout = d }(handlers.HealthDryRunTimeout)
+	handlers.HealthDryRunTimeout = 10 * time.Millisecond
+
+	release := make(chan time.Time)
+	defer close(release)
+
+	slowScanner := &mocks.Scanner{}
+	slowScanner.On("Name").Return("slow")
+	slowScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).WaitUntil(release).Return(nil)
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(slowScanner)
+
+	req, err := http.NewRequest(http.MethodGet, "/v2/health/scanners", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"scanners":[{"name":"slow","ready":false,"reason":"dry run did not complete within 10ms","plugin":false}]}`, w.Body.String())
+}
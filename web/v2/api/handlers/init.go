@@ -1,15 +1,35 @@
 package handlers
 
 import (
+	"context"
 	"github.com/sirupsen/logrus"
+	"github.com/sundowndev/phoneinfoga/v2/build"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/config"
 	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
 	"sync"
 )
 
 var once sync.Once
 var RemoteLibrary *remote.Library
 
+// PluginErrors are errors of plugins that failed to load, the server
+// starts without them
+var PluginErrors []error
+
+// History stores scans, scans are not saved when it's nil
+var History history.Store
+
+// Webhooks delivers results of scans to callback URLs
+var Webhooks = webhook.NewDispatcher(webhook.Options{})
+
+// Config holds settings such as scan profiles, it's set by the serve command
+var Config = &config.Config{}
+
 func Init(filterEngine filter.Filter) {
 	once.Do(func() {
 		RemoteLibrary = remote.NewLibrary(filterEngine)
@@ -17,3 +37,55 @@ func Init(filterEngine filter.Filter) {
 		logrus.Debug("Scanners and plugins initialized")
 	})
 }
+
+// scannerAllowed reports whether the API key of the request, if any, may use the given scanner
+func scannerAllowed(ctx context.Context, s remote.Scanner) bool {
+	k := auth.FromContext(ctx)
+	return k == nil || k.AllowsScanner(s.Name(), remote.ScannerTags(s)...)
+}
+
+// allowedResults returns results and errors of scanners the API key of the
+// request may use, such as results of a scan from history. Scanners that
+// aren't loaded anymore are matched by name only.
+func allowedResults(ctx context.Context, results map[string]interface{}, errs map[string]string) (map[string]interface{}, map[string]string) {
+	k := auth.FromContext(ctx)
+	if k == nil || !k.Restricted() {
+		return results, errs
+	}
+
+	allowed := func(name string) bool {
+		var tags []string
+		if RemoteLibrary != nil {
+			if s := RemoteLibrary.GetScanner(name); s != nil {
+				tags = remote.ScannerTags(s)
+			}
+		}
+		return k.AllowsScanner(name, tags...)
+	}
+
+	filteredResults := map[string]interface{}{}
+	for name, r := range results {
+		if allowed(name) {
+			filteredResults[name] = r
+		}
+	}
+	filteredErrs := map[string]string{}
+	for name, err := range errs {
+		if allowed(name) {
+			filteredErrs[name] = err
+		}
+	}
+	return filteredResults, filteredErrs
+}
+
+// saveScan saves scan results in history, if enabled
+func saveScan(n *number.Number, results map[string]interface{}, errs map[string]error, metadata map[string]string) {
+	if History == nil {
+		return
+	}
+	metadata[history.MetadataSource] = "api"
+	metadata[history.MetadataVersion] = build.String()
+	if err := History.Save(history.NewScan(n, results, errs, metadata)); err != nil {
+		logrus.WithField("error", err).Warn("Unable to save scan in history")
+	}
+}
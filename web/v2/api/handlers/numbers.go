@@ -32,6 +32,7 @@ type AddNumberResponse struct {
 // @Param request body AddNumberInput true "Request body"
 // @Success 200 {object} AddNumberResponse
 // @Success 500 {object} api.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /v2/numbers [post]
 func AddNumber(ctx *gin.Context) *api.Response {
 	var input AddNumberInput
@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/sundowndev/phoneinfoga/v2/lib/number"
 	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/tracing"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
 	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
 	"net/http"
 )
 
 type Scanner struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
 }
 
 type GetAllScannersResponse struct {
@@ -21,16 +25,21 @@ type GetAllScannersResponse struct {
 // @ID GetAllScanners
 // @Tags Numbers
 // @Summary Get all available scanners.
-// @Description This route returns all available scanners.
+// @Description This route returns all available scanners, restricted to the ones the API key is allowed to use.
 // @Produce  json
 // @Success 200 {object} GetAllScannersResponse
+// @Security ApiKeyAuth
 // @Router /v2/scanners [get]
-func GetAllScanners(*gin.Context) *api.Response {
+func GetAllScanners(ctx *gin.Context) *api.Response {
 	var scanners []Scanner
 	for _, s := range RemoteLibrary.GetAllScanners() {
+		if !scannerAllowed(ctx.Request.Context(), s) {
+			continue
+		}
 		scanners = append(scanners, Scanner{
 			Name:        s.Name(),
 			Description: s.Description(),
+			Tags:        remote.ScannerTags(s),
 		})
 	}
 
@@ -62,8 +71,10 @@ type DryRunScannerResponse struct {
 // @Produce  json
 // @Param request body DryRunScannerInput true "Request body"
 // @Success 200 {object} DryRunScannerResponse
+// @Success 403 {object} api.ErrorResponse
 // @Success 404 {object} api.ErrorResponse
 // @Success 500 {object} api.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /v2/scanners/{scanner}/dryrun [post]
 // @Param scanner path string true "Scanner name" validate(required)
 func DryRunScanner(ctx *gin.Context) *api.Response {
@@ -88,6 +99,13 @@ func DryRunScanner(ctx *gin.Context) *api.Response {
 			Data: api.ErrorResponse{Error: "Scanner not found"},
 		}
 	}
+	if !scannerAllowed(ctx.Request.Context(), scanner) {
+		return &api.Response{
+			Code: http.StatusForbidden,
+			JSON: true,
+			Data: api.ErrorResponse{Error: "Scanner not allowed for this API key"},
+		}
+	}
 
 	num, err := number.NewNumber(input.Number)
 	if err != nil {
@@ -122,23 +140,37 @@ func DryRunScanner(ctx *gin.Context) *api.Response {
 type RunScannerInput struct {
 	Number  string                `json:"number" binding:"number,required"`
 	Options remote.ScannerOptions `json:"options" validate:"dive,required"`
+	// CallbackURL receives the result once the scanner is done, instead of the response
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 type RunScannerResponse struct {
 	Result interface{} `json:"result"`
 }
 
+// ScannerCallback is posted to the callback URL of a single scanner run
+type ScannerCallback struct {
+	Number  string      `json:"number"`
+	Scanner string      `json:"scanner"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
 // RunScanner is an HTTP handler
 // @ID RunScanner
 // @Tags Numbers
 // @Summary Run a single scanner
-// @Description This route runs a single scanner with the given phone number
+// @Description This route runs a single scanner with the given phone number. When a callback URL is given, the scanner runs in the background and its result is posted to the URL, like with scans. Callback URLs of loopback, private or link-local addresses are refused, unless their network is allowed by the server.
 // @Accept  json
 // @Produce  json
 // @Param request body RunScannerInput true "Request body"
 // @Success 200 {object} RunScannerResponse
+// @Success 202 {object} ScanAcceptedResponse
+// @Success 400 {object} api.ErrorResponse
+// @Success 403 {object} api.ErrorResponse
 // @Success 404 {object} api.ErrorResponse
 // @Success 500 {object} api.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /v2/scanners/{scanner}/run [post]
 // @Param scanner path string true "Scanner name" validate(required)
 func RunScanner(ctx *gin.Context) *api.Response {
@@ -163,6 +195,13 @@ func RunScanner(ctx *gin.Context) *api.Response {
 			Data: api.ErrorResponse{Error: "Scanner not found"},
 		}
 	}
+	if !scannerAllowed(ctx.Request.Context(), scanner) {
+		return &api.Response{
+			Code: http.StatusForbidden,
+			JSON: true,
+			Data: api.ErrorResponse{Error: "Scanner not allowed for this API key"},
+		}
+	}
 
 	num, err := number.NewNumber(input.Number)
 	if err != nil {
@@ -173,7 +212,50 @@ func RunScanner(ctx *gin.Context) *api.Response {
 		}
 	}
 
-	result, err := scanner.Run(*num, input.Options)
+	run := func(runCtx context.Context) (interface{}, error) {
+		scanCtx, span := tracing.StartScanner(runCtx, scanner.Name(), num)
+		result, err := remote.RunContext(scanCtx, scanner, *num, input.Options)
+		if err != nil {
+			tracing.EndScanner(span, string(remote.ScanError), err)
+			saveScan(num, map[string]interface{}{}, map[string]error{scanner.Name(): err}, map[string]string{})
+			return nil, err
+		}
+		tracing.EndScanner(span, string(remote.ScanSuccess), nil)
+
+		results := map[string]interface{}{}
+		if result != nil {
+			results[scanner.Name()] = result
+		}
+		saveScan(num, results, map[string]error{}, map[string]string{})
+		return result, nil
+	}
+
+	if input.CallbackURL != "" {
+		// The run outlives the request, so it only keeps its logger
+		logger := logs.FromContext(ctx.Request.Context())
+		delivery, err := Webhooks.SendFunc(deliveryOwner(ctx), input.CallbackURL, ScannerCompletedEvent, func(jobCtx context.Context) interface{} {
+			result, err := run(logs.NewContext(jobCtx, logger))
+			callback := ScannerCallback{Number: num.E164, Scanner: scanner.Name(), Result: result}
+			if err != nil {
+				callback.Error = err.Error()
+			}
+			return callback
+		})
+		if err != nil {
+			return &api.Response{
+				Code: http.StatusBadRequest,
+				JSON: true,
+				Data: api.ErrorResponse{Error: err.Error()},
+			}
+		}
+		return &api.Response{
+			Code: http.StatusAccepted,
+			JSON: true,
+			Data: ScanAcceptedResponse{Delivery: delivery},
+		}
+	}
+
+	result, err := run(ctx.Request.Context())
 	if err != nil {
 		return &api.Response{
 			Code: http.StatusInternalServerError,
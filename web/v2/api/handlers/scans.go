@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/number"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+	"github.com/sundowndev/phoneinfoga/v2/logs"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"net/http"
+	"time"
+)
+
+type ScanInput struct {
+	Number  string                `json:"number" binding:"number,required"`
+	Profile string                `json:"profile,omitempty"`
+	Options remote.ScannerOptions `json:"options,omitempty" validate:"dive,required"`
+	// CallbackURL receives results once the scan is done, instead of the response
+	CallbackURL string `json:"callback_url,omitempty"`
+}
+
+type ScanResponse struct {
+	Results map[string]interface{} `json:"results"`
+	Errors  map[string]string      `json:"errors"`
+}
+
+// ScanCallback is posted to the callback URL of a scan
+type ScanCallback struct {
+	Number string `json:"number"`
+	ScanResponse
+}
+
+type ScanAcceptedResponse struct {
+	Delivery *webhook.Delivery `json:"delivery"`
+}
+
+// Scan is an HTTP handler
+// @ID Scan
+// @Tags Numbers
+// @Summary Scan a number with all scanners.
+// @Description This route runs all scanners the API key is allowed to use, or the ones of the given profile, with the given phone number. Options given in the request override profile options. When a callback URL is given, the scan runs in the background and its results are posted to the URL, see the webhook deliveries route. Callback URLs of loopback, private or link-local addresses are refused, unless their network is allowed by the server.
+// @Accept  json
+// @Produce  json
+// @Param request body ScanInput true "Request body"
+// @Success 200 {object} ScanResponse
+// @Success 202 {object} ScanAcceptedResponse
+// @Success 400 {object} api.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /v2/scans [post]
+func Scan(ctx *gin.Context) *api.Response {
+	var input ScanInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		return &api.Response{
+			Code: http.StatusBadRequest,
+			JSON: true,
+			Data: api.ErrorResponse{Error: "Invalid phone number: please provide an integer without any special chars"},
+		}
+	}
+
+	num, err := number.NewNumber(input.Number)
+	if err != nil {
+		return &api.Response{
+			Code: http.StatusBadRequest,
+			JSON: true,
+			Data: api.ErrorResponse{Error: err.Error()},
+		}
+	}
+
+	lib := RemoteLibrary
+	if k := auth.FromContext(ctx.Request.Context()); k != nil {
+		lib = lib.Filtered(k.Filter())
+	}
+	opts := input.Options
+	var timeout time.Duration
+	if input.Profile != "" {
+		profile, err := Config.Profile(input.Profile)
+		if err != nil {
+			return &api.Response{
+				Code: http.StatusBadRequest,
+				JSON: true,
+				Data: api.ErrorResponse{Error: err.Error()},
+			}
+		}
+
+		lib = lib.Filtered(profile.Filter())
+		opts = profile.ScannerOptions(opts)
+		timeout = profile.Timeout
+	}
+	if opts == nil {
+		opts = make(remote.ScannerOptions)
+	}
+
+	scan := func(scanCtx context.Context) ScanResponse {
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			scanCtx, cancel = context.WithTimeout(scanCtx, timeout)
+			defer cancel()
+		}
+
+		results, errs := lib.ScanContext(scanCtx, num, opts)
+
+		metadata := map[string]string{}
+		if input.Profile != "" {
+			metadata[history.MetadataProfile] = input.Profile
+		}
+		saveScan(num, results, errs, metadata)
+
+		res := ScanResponse{Results: results, Errors: map[string]string{}}
+		for name, err := range errs {
+			res.Errors[name] = err.Error()
+		}
+		return res
+	}
+
+	if input.CallbackURL != "" {
+		// The scan outlives the request, so it only keeps its logger
+		logger := logs.FromContext(ctx.Request.Context())
+		delivery, err := Webhooks.SendFunc(deliveryOwner(ctx), input.CallbackURL, ScanCompletedEvent, func(jobCtx context.Context) interface{} {
+			return ScanCallback{Number: num.E164, ScanResponse: scan(logs.NewContext(jobCtx, logger))}
+		})
+		if err != nil {
+			return &api.Response{
+				Code: http.StatusBadRequest,
+				JSON: true,
+				Data: api.ErrorResponse{Error: err.Error()},
+			}
+		}
+		return &api.Response{
+			Code: http.StatusAccepted,
+			JSON: true,
+			Data: ScanAcceptedResponse{Delivery: delivery},
+		}
+	}
+
+	return &api.Response{
+		Code: http.StatusOK,
+		JSON: true,
+		Data: scan(ctx.Request.Context()),
+	}
+}
@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/config"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/history"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/server"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	type FakeScannerResponse struct {
+		Info string `json:"info"`
+	}
+
+	type expectedResponse struct {
+		Code int
+		Body interface{}
+	}
+
+	testcases := []struct {
+		Name     string
+		Body     interface{}
+		Expected expectedResponse
+		Mocks    func(local *mocks.Scanner, paid *mocks.Scanner)
+	}{
+		{
+			Name: "test scanning with all scanners",
+			Body: handlers.ScanInput{Number: "14152229670"},
+			Expected: expectedResponse{
+				Code: 200,
+				Body: handlers.ScanResponse{
+					Results: map[string]interface{}{"local": FakeScannerResponse{Info: "test"}},
+					Errors:  map[string]string{"paid": "dummy error"},
+				},
+			},
+			Mocks: func(local *mocks.Scanner, paid *mocks.Scanner) {
+				local.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil)
+				local.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(FakeScannerResponse{Info: "test"}, nil)
+				paid.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil)
+				paid.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil, errors.New("dummy error"))
+			},
+		},
+		{
+			Name: "test scanning with a profile",
+			Body: handlers.ScanInput{Number: "14152229670", Profile: "free"},
+			Expected: expectedResponse{
+				Code: 200,
+				Body: handlers.ScanResponse{
+					Results: map[string]interface{}{"local": FakeScannerResponse{Info: "test"}},
+					Errors:  map[string]string{},
+				},
+			},
+			Mocks: func(local *mocks.Scanner, paid *mocks.Scanner) {
+				local.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{"LOCAL_OPTION": "profile"}).Return(nil)
+				local.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{"LOCAL_OPTION": "profile"}).Return(FakeScannerResponse{Info: "test"}, nil)
+			},
+		},
+		{
+			Name: "test scanning with a profile and options",
+			Body: handlers.ScanInput{
+				Number:  "14152229670",
+				Profile: "free",
+				Options: remote.ScannerOptions{"LOCAL_OPTION": "request"},
+			},
+			Expected: expectedResponse{
+				Code: 200,
+				Body: handlers.ScanResponse{
+					Results: map[string]interface{}{"local": FakeScannerResponse{Info: "test"}},
+					Errors:  map[string]string{},
+				},
+			},
+			Mocks: func(local *mocks.Scanner, paid *mocks.Scanner) {
+				local.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{"LOCAL_OPTION": "request"}).Return(nil)
+				local.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{"LOCAL_OPTION": "request"}).Return(FakeScannerResponse{Info: "test"}, nil)
+			},
+		},
+		{
+			Name: "test unknown profile",
+			Body: handlers.ScanInput{Number: "14152229670", Profile: "unknown"},
+			Expected: expectedResponse{
+				Code: 400,
+				Body: api.ErrorResponse{Error: "profile \"unknown\" not found"},
+			},
+			Mocks: func(local *mocks.Scanner, paid *mocks.Scanner) {},
+		},
+		{
+			Name: "test invalid number",
+			Body: handlers.ScanInput{Number: "1.4152229670"},
+			Expected: expectedResponse{
+				Code: 400,
+				Body: api.ErrorResponse{Error: "Invalid phone number: please provide an integer without any special chars"},
+			},
+			Mocks: func(local *mocks.Scanner, paid *mocks.Scanner) {},
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.Name, func(t *testing.T) {
+			localScanner := &mocks.Scanner{}
+			localScanner.On("Name").Return("local")
+			paidScanner := &mocks.Scanner{}
+			paidScanner.On("Name").Return("paid")
+			tt.Mocks(localScanner, paidScanner)
+
+			handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+			handlers.RemoteLibrary.AddScanner(localScanner)
+			handlers.RemoteLibrary.AddScanner(paidScanner)
+			handlers.Config = &config.Config{
+				Profiles: map[string]*config.Profile{
+					"free": {
+						Disable: []string{"paid"},
+						Options: map[string]string{"LOCAL_OPTION": "profile"},
+					},
+				},
+			}
+			defer func() { handlers.Config = &config.Config{} }()
+
+			data, err := json.Marshal(&tt.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/v2/scans", bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			server.NewServer().ServeHTTP(w, req)
+
+			b, err := json.Marshal(tt.Expected.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.Expected.Code, w.Code)
+			assert.Equal(t, string(b), w.Body.String())
+			localScanner.AssertExpectations(t)
+			paidScanner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestScan_History(t *testing.T) {
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil)
+	fakeScanner.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(map[string]interface{}{"info": "test"}, nil)
+
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+	handlers.History = history.NewBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	defer func() { handlers.History = nil }()
+
+	for _, path := range []string{"/v2/scans", "/v2/scanners/fakeScanner/run"} {
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(`{"number":"14152229670"}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		server.NewServer().ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+	}
+
+	scans, err := handlers.History.List(history.Query{Number: "+14152229670"})
+	assert.NoError(t, err)
+	assert.Len(t, scans, 2)
+	for _, s := range scans {
+		assert.Equal(t, map[string]interface{}{"fakeScanner": map[string]interface{}{"info": "test"}}, s.Results)
+		assert.Equal(t, "api", s.Metadata[history.MetadataSource])
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"net/http"
+)
+
+// Events of deliveries
+const (
+	// ScanCompletedEvent is the event of deliveries holding scan results
+	ScanCompletedEvent = "scan.completed"
+	// ScannerCompletedEvent is the event of deliveries holding the result of a single scanner
+	ScannerCompletedEvent = "scanner.completed"
+)
+
+type GetAllDeliveriesResponse struct {
+	Deliveries []*webhook.Delivery `json:"deliveries"`
+}
+
+// GetAllDeliveries is an HTTP handler
+// @ID GetAllDeliveries
+// @Tags Webhooks
+// @Summary Get latest webhook deliveries.
+// @Description This route returns the latest deliveries to callback URLs created with the API key of the request, newest first, along with their attempts. Failed deliveries are retried with exponential backoff.
+// @Produce  json
+// @Success 200 {object} GetAllDeliveriesResponse
+// @Security ApiKeyAuth
+// @Router /v2/webhooks/deliveries [get]
+func GetAllDeliveries(ctx *gin.Context) *api.Response {
+	return &api.Response{
+		Code: http.StatusOK,
+		JSON: true,
+		Data: GetAllDeliveriesResponse{Deliveries: Webhooks.Deliveries(deliveryOwner(ctx))},
+	}
+}
+
+// GetDelivery is an HTTP handler
+// @ID GetDelivery
+// @Tags Webhooks
+// @Summary Get a webhook delivery.
+// @Description This route returns a delivery to a callback URL, along with its attempts. Deliveries created with another API key are not found.
+// @Produce  json
+// @Param delivery path string true "Delivery ID"
+// @Success 200 {object} webhook.Delivery
+// @Success 404 {object} api.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /v2/webhooks/deliveries/{delivery} [get]
+func GetDelivery(ctx *gin.Context) *api.Response {
+	delivery, ok := Webhooks.Get(ctx.Param("delivery"), deliveryOwner(ctx))
+	if !ok {
+		return &api.Response{
+			Code: http.StatusNotFound,
+			JSON: true,
+			Data: api.ErrorResponse{Error: "Delivery not found"},
+		}
+	}
+	return &api.Response{
+		Code: http.StatusOK,
+		JSON: true,
+		Data: delivery,
+	}
+}
+
+// deliveryOwner returns the name of the API key of the request, deliveries
+// are only listed to the key they were created with
+func deliveryOwner(ctx *gin.Context) string {
+	if k := auth.FromContext(ctx.Request.Context()); k != nil {
+		return k.Name
+	}
+	return ""
+}
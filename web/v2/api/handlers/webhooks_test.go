@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/sundowndev/phoneinfoga/v2/lib/auth"
+	"github.com/sundowndev/phoneinfoga/v2/lib/filter"
+	"github.com/sundowndev/phoneinfoga/v2/lib/netguard"
+	"github.com/sundowndev/phoneinfoga/v2/lib/remote"
+	"github.com/sundowndev/phoneinfoga/v2/lib/webhook"
+	"github.com/sundowndev/phoneinfoga/v2/mocks"
+	"github.com/sundowndev/phoneinfoga/v2/test"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/handlers"
+	"github.com/sundowndev/phoneinfoga/v2/web/v2/api/server"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestScan_Callback(t *testing.T) {
+	var received []byte
+	var headers http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		headers = r.Header
+	}))
+	defer receiver.Close()
+
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("DryRun", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(nil)
+	fakeScanner.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(map[string]interface{}{"info": "test"}, nil)
+
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+	guard, err := netguard.New("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers.Webhooks = webhook.NewDispatcher(webhook.Options{Secret: "secret", Backoff: time.Millisecond, Guard: guard})
+	defer func() { handlers.Webhooks = webhook.NewDispatcher(webhook.Options{}) }()
+
+	body := []byte(`{"number":"14152229670","callback_url":"` + receiver.URL + `"}`)
+	req, err := http.NewRequest(http.MethodPost, "/v2/scans", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{Name: "soar"}))
+	w := httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 202, w.Code)
+
+	var accepted handlers.ScanAcceptedResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, webhook.StatusPending, accepted.Delivery.Status)
+
+	handlers.Webhooks.Wait()
+	fakeScanner.AssertExpectations(t)
+
+	assert.JSONEq(t, `{"number":"+14152229670","results":{"fakeScanner":{"info":"test"}},"errors":{}}`, string(received))
+	assert.Equal(t, "scan.completed", headers.Get(webhook.EventHeader))
+	assert.Equal(t, accepted.Delivery.ID, headers.Get(webhook.DeliveryHeader))
+	assert.True(t, webhook.Verify("secret", received, headers.Get(webhook.SignatureHeader)))
+
+	// Delivery log
+	req, _ = http.NewRequest(http.MethodGet, "/v2/webhooks/deliveries", nil)
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{Name: "soar"}))
+	w = httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var deliveries handlers.GetAllDeliveriesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveries))
+	assert.Len(t, deliveries.Deliveries, 1)
+	assert.Equal(t, webhook.StatusDelivered, deliveries.Deliveries[0].Status)
+	assert.Equal(t, 200, deliveries.Deliveries[0].Attempts[0].StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, "/v2/webhooks/deliveries/"+accepted.Delivery.ID, nil)
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{Name: "soar"}))
+	w = httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	// Deliveries of other keys aren't listed
+	req, _ = http.NewRequest(http.MethodGet, "/v2/webhooks/deliveries", nil)
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{Name: "other"}))
+	w = httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"deliveries":[]}`, w.Body.String())
+
+	req, _ = http.NewRequest(http.MethodGet, "/v2/webhooks/deliveries/"+accepted.Delivery.ID, nil)
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{Name: "other"}))
+	w = httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+
+	req, _ = http.NewRequest(http.MethodGet, "/v2/webhooks/deliveries/unknown", nil)
+	w = httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+	expected, _ := json.Marshal(api.ErrorResponse{Error: "Delivery not found"})
+	assert.Equal(t, string(expected), w.Body.String())
+}
+
+func TestScan_InvalidCallback(t *testing.T) {
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.Webhooks = webhook.NewDispatcher(webhook.Options{Secret: "secret"})
+	defer func() { handlers.Webhooks = webhook.NewDispatcher(webhook.Options{}) }()
+
+	testcases := []struct {
+		name          string
+		callback      string
+		expectedError string
+	}{
+		{
+			name:          "test invalid scheme",
+			callback:      "file:///etc/passwd",
+			expectedError: `invalid callback URL "file:///etc/passwd": only http and https URLs are allowed`,
+		},
+		{
+			name:          "test loopback address",
+			callback:      "http://127.0.0.1:8080/hook",
+			expectedError: `invalid callback URL "http://127.0.0.1:8080/hook": destination is a loopback, private or link-local address: 127.0.0.1`,
+		},
+		{
+			name:          "test link-local address",
+			callback:      "http://169.254.169.254/latest/meta-data",
+			expectedError: `invalid callback URL "http://169.254.169.254/latest/meta-data": destination is a loopback, private or link-local address: 169.254.169.254`,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(`{"number":"14152229670","callback_url":"` + tt.callback + `"}`)
+			req, err := http.NewRequest(http.MethodPost, "/v2/scans", bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			server.NewServer().ServeHTTP(w, req)
+
+			expected, _ := json.Marshal(api.ErrorResponse{Error: tt.expectedError})
+			assert.Equal(t, 400, w.Code)
+			assert.Equal(t, string(expected), w.Body.String())
+		})
+	}
+}
+
+func TestScan_CallbackWithoutSecret(t *testing.T) {
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+
+	body := []byte(`{"number":"14152229670","callback_url":"https://example.com/hook"}`)
+	req, err := http.NewRequest(http.MethodPost, "/v2/scans", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+
+	expected, _ := json.Marshal(api.ErrorResponse{Error: webhook.ErrNoSecret.Error()})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, string(expected), w.Body.String())
+}
+
+func TestRunScanner_Callback(t *testing.T) {
+	var received []byte
+	var headers http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		headers = r.Header
+	}))
+	defer receiver.Close()
+
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+	fakeScanner.On("Run", *test.NewFakeUSNumber(), remote.ScannerOptions{}).Return(map[string]interface{}{"info": "test"}, nil)
+
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+	guard, err := netguard.New("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers.Webhooks = webhook.NewDispatcher(webhook.Options{Secret: "secret", Backoff: time.Millisecond, Guard: guard})
+	defer func() { handlers.Webhooks = webhook.NewDispatcher(webhook.Options{}) }()
+
+	body := []byte(`{"number":"14152229670","callback_url":"` + receiver.URL + `"}`)
+	req, err := http.NewRequest(http.MethodPost, "/v2/scanners/fakeScanner/run", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(auth.NewContext(req.Context(), &auth.Key{Name: "soar"}))
+	w := httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+	assert.Equal(t, 202, w.Code)
+
+	var accepted handlers.ScanAcceptedResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &accepted))
+	assert.Equal(t, webhook.StatusPending, accepted.Delivery.Status)
+
+	handlers.Webhooks.Wait()
+	fakeScanner.AssertExpectations(t)
+
+	assert.JSONEq(t, `{"number":"+14152229670","scanner":"fakeScanner","result":{"info":"test"}}`, string(received))
+	assert.Equal(t, "scanner.completed", headers.Get(webhook.EventHeader))
+	assert.Equal(t, accepted.Delivery.ID, headers.Get(webhook.DeliveryHeader))
+	assert.True(t, webhook.Verify("secret", received, headers.Get(webhook.SignatureHeader)))
+}
+
+func TestRunScanner_InvalidCallback(t *testing.T) {
+	fakeScanner := &mocks.Scanner{}
+	fakeScanner.On("Name").Return("fakeScanner")
+
+	handlers.RemoteLibrary = remote.NewLibrary(filter.NewEngine())
+	handlers.RemoteLibrary.AddScanner(fakeScanner)
+	handlers.Webhooks = webhook.NewDispatcher(webhook.Options{Secret: "secret"})
+	defer func() { handlers.Webhooks = webhook.NewDispatcher(webhook.Options{}) }()
+
+	body := []byte(`{"number":"14152229670","callback_url":"http://169.254.169.254/latest/meta-data"}`)
+	req, err := http.NewRequest(http.MethodPost, "/v2/scanners/fakeScanner/run", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	server.NewServer().ServeHTTP(w, req)
+
+	expected, _ := json.Marshal(api.ErrorResponse{Error: `invalid callback URL "http://169.254.169.254/latest/meta-data": destination is a loopback, private or link-local address: 169.254.169.254`})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, string(expected), w.Body.String())
+	fakeScanner.AssertNotCalled(t, "Run", *test.NewFakeUSNumber(), remote.ScannerOptions{})
+}
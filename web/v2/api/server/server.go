@@ -16,8 +16,9 @@ func NewServer() *Server {
 	gin.DefaultWriter = color.Output
 	gin.DefaultErrorWriter = color.Error
 	s := &Server{
-		router: gin.Default(),
+		router: gin.New(),
 	}
+	s.router.Use(gin.Recovery())
 	s.registerRoutes()
 	return s
 }
@@ -25,9 +26,15 @@ func NewServer() *Server {
 func (s *Server) registerRoutes() {
 	s.router.Group("/v2").
 		POST("/numbers", api.WrapHandler(handlers.AddNumber)).
+		POST("/scans", api.WrapHandler(handlers.Scan)).
+		POST("/diffs", api.WrapHandler(handlers.Diff)).
 		POST("/scanners/:scanner/dryrun", api.WrapHandler(handlers.DryRunScanner)).
 		POST("/scanners/:scanner/run", api.WrapHandler(handlers.RunScanner)).
-		GET("/scanners", api.WrapHandler(handlers.GetAllScanners))
+		GET("/scanners", api.WrapHandler(handlers.GetAllScanners)).
+		GET("/health/ready", api.WrapHandler(handlers.HealthReady)).
+		GET("/health/scanners", api.WrapHandler(handlers.HealthScanners)).
+		GET("/webhooks/deliveries", api.WrapHandler(handlers.GetAllDeliveries)).
+		GET("/webhooks/deliveries/:delivery", api.WrapHandler(handlers.GetDelivery))
 }
 
 func (s *Server) Routes() gin.RoutesInfo {